@@ -9,8 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordhash"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb"
-	"golang.org/x/crypto/bcrypt"
 
 	configApp "github.com/IldarGaleev/todo-backend-service/internal/app/configapp"
 )
@@ -91,6 +91,11 @@ func main() {
 	//Init app config
 	appConf := configApp.MustLoadConfig(confPath)
 
+	passwordHasher, err := passwordhash.New(appConf.PasswordHashParams())
+	if err != nil {
+		panic(err)
+	}
+
 	storageProvider := postgresdb.New(log, appConf.Dsn)
 	storageProvider.MustRun()
 
@@ -100,7 +105,7 @@ func main() {
 
 	wg.Add(1)
 	go func(opt createUserOptions) {
-		passwordHash, err := bcrypt.GenerateFromPassword([]byte(opt.Password), bcrypt.DefaultCost)
+		passwordHash, err := passwordHasher.Hash(opt.Password)
 		if err != nil {
 			panic(err)
 		}
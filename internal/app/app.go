@@ -4,16 +4,31 @@ Package app Service entrypoint
 package app
 
 import (
+	"crypto/tls"
+	"fmt"
 	"log/slog"
 
 	configApp "github.com/IldarGaleev/todo-backend-service/internal/app/configapp"
 	grpcApp "github.com/IldarGaleev/todo-backend-service/internal/app/grpcapp"
+	httpApp "github.com/IldarGaleev/todo-backend-service/internal/app/httpapp"
+	janitorApp "github.com/IldarGaleev/todo-backend-service/internal/app/janitorapp"
+	grpcToDoServer "github.com/IldarGaleev/todo-backend-service/internal/grpc/grpctodoserver"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/eventhub"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/jwtkeys"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/loginthrottle"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/notifier"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordhash"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordpolicy"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/secretcipher"
 	secretsJwt "github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/tlscerts"
+	accountService "github.com/IldarGaleev/todo-backend-service/internal/services/accountservice"
 	authService "github.com/IldarGaleev/todo-backend-service/internal/services/auth"
-	credentialService "github.com/IldarGaleev/todo-backend-service/internal/services/credentialservice"
+	projectService "github.com/IldarGaleev/todo-backend-service/internal/services/projectservice"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	shareService "github.com/IldarGaleev/todo-backend-service/internal/services/shareservice"
 	todoService "github.com/IldarGaleev/todo-backend-service/internal/services/todoservice"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb"
-	faketempdb "github.com/IldarGaleev/todo-backend-service/internal/tempstorage/fakeTempDb"
 )
 
 type IStorageProvider interface {
@@ -25,7 +40,11 @@ type IStorageProvider interface {
 type App struct {
 	logger          *slog.Logger
 	grpcServer      *grpcApp.App
+	httpServer      *httpApp.App
+	janitor         *janitorApp.App
 	storageProvider IStorageProvider
+	keyRing         *jwtkeys.KeyRing
+	certStore       *tlscerts.Store
 }
 
 // New Create main application instance
@@ -35,12 +54,28 @@ func New(
 ) *App {
 
 	storageProvider := postgresdb.New(log, config.Dsn)
-	tokenStorage := faketempdb.New(log)
+
+	var keyRing *jwtkeys.KeyRing
+	if config.JWTKeysDir == "" && len(config.JWTKeyFiles) == 0 {
+		log.Warn("no JWT keys set, tokens are signed with HS256 secret key")
+		keyRing = jwtkeys.NewHMAC(log, config.SecretKey)
+	} else {
+		keyRing = jwtkeys.New(
+			log,
+			config.JWTKeysDir,
+			config.JWTKeyFiles,
+			config.JWTSigningKeyID,
+			config.JWTKeysReloadInterval,
+		)
+	}
 
 	secretProvider := secretsJwt.New(
 		log,
-		*config, tokenStorage,
-		tokenStorage,
+		*config,
+		keyRing,
+		storageProvider,
+		storageProvider,
+		storageProvider,
 	)
 
 	todoSrv := todoService.New(
@@ -49,12 +84,184 @@ func New(
 		storageProvider,
 		storageProvider,
 		storageProvider,
+		storageProvider,
+		storageProvider,
+		eventhub.New[serviceDTO.ToDoItemEvent](config.WatchHistorySize, config.WatchBufferSize),
+		config.SubtaskMaxDepth,
+		config.TaskBatchMaxSize,
+	)
+
+	projectSrv := projectService.New(
+		log,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+	)
+
+	shareSrv := shareService.New(
+		log,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
 	)
 
+	var attemptCounter authService.ILoginAttemptCounter
+	switch config.LoginAttemptsStorage {
+	case "memory":
+		attemptCounter = loginthrottle.NewMemoryCounter()
+	case "postgres":
+		attemptCounter = storageProvider
+	default:
+		panic(fmt.Sprintf("unknown login attempts storage %q", config.LoginAttemptsStorage))
+	}
+
+	var mfaCipher authService.ISecretCipher
+	if config.MfaEncryptionKey == "" {
+		log.Warn("no MFA encryption key set, MFA enrollment is disabled")
+	} else {
+		cipher, err := secretcipher.NewFromBase64(config.MfaEncryptionKey)
+		if err != nil {
+			panic(err)
+		}
+		mfaCipher = cipher
+	}
+
+	passwordHasher, err := passwordhash.New(config.PasswordHashParams())
+	if err != nil {
+		panic(err)
+	}
+
 	authSrv := authService.New(
 		log,
 		secretProvider,
 		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		attemptCounter,
+		authService.LoginLimits{
+			User: loginthrottle.Policy{
+				Threshold:       config.LoginLockoutThreshold,
+				BackoffBase:     config.LoginBackoffBase,
+				BackoffMax:      config.LoginBackoffMax,
+				LockoutDuration: config.LoginLockoutDuration,
+			},
+			IP: loginthrottle.Policy{
+				Threshold:       config.LoginIPLockoutThreshold,
+				BackoffBase:     config.LoginBackoffBase,
+				BackoffMax:      config.LoginBackoffMax,
+				LockoutDuration: config.LoginLockoutDuration,
+			},
+		},
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		mfaCipher,
+		config.MfaIssuer,
+		storageProvider,
+		passwordHasher,
+	)
+
+	var resetNotifier accountService.IPasswordResetNotifier
+	switch config.PasswordResetNotifier {
+	case "log":
+		resetNotifier = notifier.NewLog(log)
+	case "file":
+		resetNotifier = notifier.NewFile(config.PasswordResetFile)
+	default:
+		panic(fmt.Sprintf("unknown password reset notifier %q", config.PasswordResetNotifier))
+	}
+
+	accountSrv := accountService.New(
+		log,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		storageProvider,
+		resetNotifier,
+		secretProvider,
+		passwordpolicy.Policy{
+			MinLength:     config.PasswordMinLength,
+			RequireUpper:  config.PasswordRequireUpper,
+			RequireLower:  config.PasswordRequireLower,
+			RequireDigit:  config.PasswordRequireDigit,
+			RequireSymbol: config.PasswordRequireSymbol,
+			BlockCommon:   config.PasswordBlockCommon,
+		},
+		passwordHasher,
+		config.RegistrationEnabled,
+		config.PasswordResetTokenMaxAge,
+	)
+
+	var certStore *tlscerts.Store
+	var tlsConfig *tls.Config
+	if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+		minVersion, err := tlscerts.ParseVersion(config.TLSMinVersion)
+		if err != nil {
+			panic(err)
+		}
+		certStore = tlscerts.New(log, tlscerts.Options{
+			CertFile:          config.TLSCertFile,
+			KeyFile:           config.TLSKeyFile,
+			ClientCAFile:      config.TLSClientCAFile,
+			RequireClientCert: config.TLSRequireClientCert,
+			MinVersion:        minVersion,
+			ReloadInterval:    config.TLSReloadInterval,
+		})
+		tlsConfig = certStore.ServerConfig()
+	} else if config.TLSClientCAFile != "" {
+		panic("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	servers := grpcToDoServer.New(
+		todoSrv,
+		todoSrv,
+		todoSrv,
+		todoSrv,
+		todoSrv,
+		todoSrv,
+		projectSrv,
+		projectSrv,
+		projectSrv,
+		projectSrv,
+		shareSrv,
+		shareSrv,
+		shareSrv,
+		shareSrv,
+		authSrv,
+		authSrv,
+		authSrv,
+		accountSrv,
+		authSrv,
+		authSrv,
+		authSrv,
+		authSrv,
+		authSrv,
+		accountSrv,
+	)
+
+	var httpServer *httpApp.App
+	if config.HTTPPort != 0 {
+		httpServer = httpApp.New(
+			log,
+			config.HTTPPort,
+			tlsConfig,
+			servers.ToDoV1,
+			authSrv,
+			config.TLSDelegatingServices,
+		)
+	}
+
+	janitor := janitorApp.New(
+		log,
+		storageProvider,
+		config.TrashRetention,
+		config.TrashPurgeInterval,
 	)
 
 	return &App{
@@ -62,26 +269,42 @@ func New(
 		grpcServer: grpcApp.New(
 			log,
 			config.Port,
-			todoSrv,
-			todoSrv,
-			todoSrv,
-			todoSrv,
-			authSrv,
-			authSrv,
+			tlsConfig,
+			servers,
 			authSrv,
-			credentialService.New(log, storageProvider),
+			config.TLSDelegatingServices,
 		),
+		httpServer:      httpServer,
+		janitor:         janitor,
 		storageProvider: storageProvider,
+		keyRing:         keyRing,
+		certStore:       certStore,
 	}
 }
 
 func (app *App) MustRun() {
 	app.storageProvider.MustRun()
+	app.keyRing.MustRun()
+	app.janitor.MustRun()
+	if app.certStore != nil {
+		app.certStore.MustRun()
+	}
+	if app.httpServer != nil {
+		go app.httpServer.MustRun()
+	}
 	app.grpcServer.MustRun()
 }
 
 func (app *App) Stop() {
+	if app.httpServer != nil {
+		app.httpServer.Stop()
+	}
 	app.grpcServer.Stop()
+	app.keyRing.Stop()
+	app.janitor.Stop()
+	if app.certStore != nil {
+		app.certStore.Stop()
+	}
 	err := app.storageProvider.Stop()
 	if err != nil {
 		app.logger.Error("failed stop service", slog.Any("err", err))
@@ -6,16 +6,70 @@ import (
 	"os"
 	"time"
 
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordhash"
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type AppConfig struct {
 	EnvMode string `yaml:"env-mode" env:"ENV_MODE" env-default:"prod"`
 	Port    int    `yaml:"port" env:"PORT" env-default:"9090"`
-	Dsn     string `yaml:"dsn" env:"DSN" env-require:"true"`
+	// HTTPPort of REST gateway, disabled if zero
+	HTTPPort int    `yaml:"http-port" env:"HTTP_PORT" env-default:"8080"`
+	Dsn      string `yaml:"dsn" env:"DSN" env-require:"true"`
 
-	SecretKey     []byte        `yaml:"secret-key" env:"SECRET_KEY" env-require:"true"`
-	SecretsMaxAge time.Duration `yaml:"secrets-max-age" env:"SECRETS_MAX_AGE" env-default:"24h"`
+	TLSCertFile           string        `yaml:"tls-cert-file" env:"TLS_CERT_FILE"`
+	TLSKeyFile            string        `yaml:"tls-key-file" env:"TLS_KEY_FILE"`
+	TLSClientCAFile       string        `yaml:"tls-client-ca-file" env:"TLS_CLIENT_CA_FILE"`
+	TLSRequireClientCert  bool          `yaml:"tls-require-client-cert" env:"TLS_REQUIRE_CLIENT_CERT" env-default:"false"`
+	TLSMinVersion         string        `yaml:"tls-min-version" env:"TLS_MIN_VERSION" env-default:"1.2"`
+	TLSReloadInterval     time.Duration `yaml:"tls-reload-interval" env:"TLS_RELOAD_INTERVAL" env-default:"1m"`
+	TLSDelegatingServices []string      `yaml:"tls-delegating-services" env:"TLS_DELEGATING_SERVICES"`
+
+	SecretKey          []byte        `yaml:"secret-key" env:"SECRET_KEY" env-require:"true"`
+	SecretsMaxAge      time.Duration `yaml:"secrets-max-age" env:"SECRETS_MAX_AGE" env-default:"15m"`
+	RefreshTokenMaxAge time.Duration `yaml:"refresh-token-max-age" env:"REFRESH_TOKEN_MAX_AGE" env-default:"720h"`
+
+	JWTKeysDir            string        `yaml:"jwt-keys-dir" env:"JWT_KEYS_DIR"`
+	JWTKeyFiles           []string      `yaml:"jwt-key-files" env:"JWT_KEY_FILES"`
+	JWTSigningKeyID       string        `yaml:"jwt-signing-key-id" env:"JWT_SIGNING_KEY_ID"`
+	JWTKeysReloadInterval time.Duration `yaml:"jwt-keys-reload-interval" env:"JWT_KEYS_RELOAD_INTERVAL" env-default:"1m"`
+
+	WatchHistorySize int `yaml:"watch-history-size" env:"WATCH_HISTORY_SIZE" env-default:"10000"`
+	WatchBufferSize  int `yaml:"watch-buffer-size" env:"WATCH_BUFFER_SIZE" env-default:"256"`
+
+	SubtaskMaxDepth  int `yaml:"subtask-max-depth" env:"SUBTASK_MAX_DEPTH" env-default:"3"`
+	TaskBatchMaxSize int `yaml:"task-batch-max-size" env:"TASK_BATCH_MAX_SIZE" env-default:"100"`
+
+	TrashRetention     time.Duration `yaml:"trash-retention" env:"TRASH_RETENTION" env-default:"720h"`
+	TrashPurgeInterval time.Duration `yaml:"trash-purge-interval" env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+
+	RegistrationEnabled   bool `yaml:"registration-enabled" env:"REGISTRATION_ENABLED" env-default:"false"`
+	PasswordMinLength     int  `yaml:"password-min-length" env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	PasswordRequireUpper  bool `yaml:"password-require-upper" env:"PASSWORD_REQUIRE_UPPER" env-default:"true"`
+	PasswordRequireLower  bool `yaml:"password-require-lower" env:"PASSWORD_REQUIRE_LOWER" env-default:"true"`
+	PasswordRequireDigit  bool `yaml:"password-require-digit" env:"PASSWORD_REQUIRE_DIGIT" env-default:"true"`
+	PasswordRequireSymbol bool `yaml:"password-require-symbol" env:"PASSWORD_REQUIRE_SYMBOL" env-default:"false"`
+	PasswordBlockCommon   bool `yaml:"password-block-common" env:"PASSWORD_BLOCK_COMMON" env-default:"true"`
+
+	LoginAttemptsStorage    string        `yaml:"login-attempts-storage" env:"LOGIN_ATTEMPTS_STORAGE" env-default:"memory"`
+	LoginLockoutThreshold   int           `yaml:"login-lockout-threshold" env:"LOGIN_LOCKOUT_THRESHOLD" env-default:"10"`
+	LoginIPLockoutThreshold int           `yaml:"login-ip-lockout-threshold" env:"LOGIN_IP_LOCKOUT_THRESHOLD" env-default:"50"`
+	LoginLockoutDuration    time.Duration `yaml:"login-lockout-duration" env:"LOGIN_LOCKOUT_DURATION" env-default:"15m"`
+	LoginBackoffBase        time.Duration `yaml:"login-backoff-base" env:"LOGIN_BACKOFF_BASE" env-default:"1s"`
+	LoginBackoffMax         time.Duration `yaml:"login-backoff-max" env:"LOGIN_BACKOFF_MAX" env-default:"1m"`
+
+	PasswordHashAlgorithm string `yaml:"password-hash-algorithm" env:"PASSWORD_HASH_ALGORITHM" env-default:"bcrypt"`
+	BcryptCost            int    `yaml:"bcrypt-cost" env:"BCRYPT_COST" env-default:"10"`
+	Argon2Time            uint32 `yaml:"argon2-time" env:"ARGON2_TIME" env-default:"3"`
+	Argon2MemoryKiB       uint32 `yaml:"argon2-memory-kib" env:"ARGON2_MEMORY_KIB" env-default:"65536"`
+	Argon2Threads         uint8  `yaml:"argon2-threads" env:"ARGON2_THREADS" env-default:"2"`
+
+	PasswordResetTokenMaxAge time.Duration `yaml:"password-reset-token-max-age" env:"PASSWORD_RESET_TOKEN_MAX_AGE" env-default:"1h"`
+	PasswordResetNotifier    string        `yaml:"password-reset-notifier" env:"PASSWORD_RESET_NOTIFIER" env-default:"log"`
+	PasswordResetFile        string        `yaml:"password-reset-file" env:"PASSWORD_RESET_FILE" env-default:"password-resets.jsonl"`
+
+	MfaEncryptionKey string `yaml:"mfa-encryption-key" env:"MFA_ENCRYPTION_KEY"`
+	MfaIssuer        string `yaml:"mfa-issuer" env:"MFA_ISSUER" env-default:"ToDo"`
 }
 
 // MustLoadConfig returns app configuration. Panic if failed
@@ -28,3 +82,14 @@ func MustLoadConfig(confPath string) *AppConfig {
 	}
 	return &appConf
 }
+
+// PasswordHashParams returns settings of password hasher
+func (c *AppConfig) PasswordHashParams() passwordhash.Params {
+	return passwordhash.Params{
+		Algorithm:       c.PasswordHashAlgorithm,
+		BcryptCost:      c.BcryptCost,
+		Argon2Time:      c.Argon2Time,
+		Argon2MemoryKiB: c.Argon2MemoryKiB,
+		Argon2Threads:   c.Argon2Threads,
+	}
+}
@@ -3,20 +3,52 @@ package grpcapp
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"strconv"
+	"strings"
 
 	grpcToDoServer "github.com/IldarGaleev/todo-backend-service/internal/grpc/grpctodoserver"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
-type ICredentialService interface {
-	CheckToken(token string) bool
+const (
+	bearerScheme = "bearer "
+	// userIDMetadata metadata key of user on whose behalf service caller acts
+	userIDMetadata = "x-user-id"
+)
+
+// PublicMethods lists gRPC methods which are available without authorization
+var PublicMethods = map[string]bool{
+	todo_protobuf_v1.ToDoService_Login_FullMethodName:                true,
+	todo_protobuf_v1.ToDoService_CheckSecret_FullMethodName:          true,
+	todo_protobuf_v1.ToDoService_Register_FullMethodName:             true,
+	todo_protobuf_v1.ToDoService_RefreshToken_FullMethodName:         true,
+	todo_protobuf_v1.ToDoService_GetPublicKeys_FullMethodName:        true,
+	todo_protobuf_v1.ToDoService_VerifyMfa_FullMethodName:            true,
+	todo_protobuf_v1.ToDoService_RequestPasswordReset_FullMethodName: true,
+	todo_protobuf_v1.ToDoService_ConfirmPasswordReset_FullMethodName: true,
+
+	todo_protobuf_v2.AuthService_Login_FullMethodName:                true,
+	todo_protobuf_v2.AuthService_VerifyMfa_FullMethodName:            true,
+	todo_protobuf_v2.AuthService_RefreshToken_FullMethodName:         true,
+	todo_protobuf_v2.AuthService_ValidateToken_FullMethodName:        true,
+	todo_protobuf_v2.AuthService_GetPublicKeys_FullMethodName:        true,
+	todo_protobuf_v2.AuthService_Register_FullMethodName:             true,
+	todo_protobuf_v2.AuthService_RequestPasswordReset_FullMethodName: true,
+	todo_protobuf_v2.AuthService_ConfirmPasswordReset_FullMethodName: true,
 }
 
 // gRPC Application
@@ -31,22 +63,200 @@ var (
 	ErrGrpcListen = errors.New("grpc app: listen error")
 )
 
-func GetUnaryInterceptor(credentialService ICredentialService) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		meta, ok := metadata.FromIncomingContext(ctx)
+// extractBearerToken returns token from "authorization" metadata
+func extractBearerToken(ctx context.Context) (string, bool) {
+	meta, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := meta.Get("authorization")
+	if len(values) != 1 {
+		return "", false
+	}
+
+	header := strings.TrimSpace(values[0])
+	if len(header) <= len(bearerScheme) || !strings.EqualFold(header[:len(bearerScheme)], bearerScheme) {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[len(bearerScheme):])
+	return token, token != ""
+}
+
+// serviceFromPeer returns identity of verified client certificate of the caller
+func serviceFromPeer(ctx context.Context) (authcontext.Service, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return authcontext.Service{}, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return authcontext.Service{}, false
+	}
+
+	certificate := tlsInfo.State.VerifiedChains[0][0]
+	service := authcontext.Service{
+		Name:     certificate.Subject.CommonName,
+		DNSNames: certificate.DNSNames,
+	}
+	for _, uri := range certificate.URIs {
+		service.URIs = append(service.URIs, uri.String())
+	}
+
+	return service, true
+}
+
+// withPeerService returns context with service of verified client certificate if the caller sent one
+func withPeerService(ctx context.Context) context.Context {
+	if service, ok := serviceFromPeer(ctx); ok {
+		return authcontext.WithService(ctx, service)
+	}
+	return ctx
+}
+
+// extractServiceUserID returns user id from "x-user-id" metadata
+func extractServiceUserID(ctx context.Context) (uint64, bool) {
+	meta, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+
+	values := meta.Get(userIDMetadata)
+	if len(values) != 1 {
+		return 0, false
+	}
+
+	userID, err := strconv.ParseUint(strings.TrimSpace(values[0]), 10, 64)
+	return userID, err == nil && userID != 0
+}
+
+// delegatingSet returns set of service identities allowed to act on behalf of users
+func delegatingSet(delegatingServices []string) map[string]bool {
+	set := make(map[string]bool, len(delegatingServices))
+	for _, identity := range delegatingServices {
+		set[identity] = true
+	}
+	return set
+}
+
+// canDelegate reports whether common name, DNS or URI SAN of service is in delegating set
+func canDelegate(service *authcontext.Service, delegating map[string]bool) bool {
+	if service.Name != "" && delegating[service.Name] {
+		return true
+	}
+	for _, name := range service.DNSNames {
+		if delegating[name] {
+			return true
+		}
+	}
+	for _, uri := range service.URIs {
+		if delegating[uri] {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate validates bearer token and returns context with authenticated user.
+// Callers with verified client certificate of delegating service may omit the token
+// and act on behalf of user set by "x-user-id" metadata
+func authenticate(
+	ctx context.Context,
+	secretValidator grpcToDoServer.IAccountSecretValidator,
+	delegating map[string]bool,
+) (context.Context, error) {
+	token, ok := extractBearerToken(ctx)
+	if !ok {
+		service, isService := authcontext.ServiceFromContext(ctx)
+		if !isService {
+			return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+		}
+
+		if !canDelegate(service, delegating) {
+			return nil, status.Error(codes.Unauthenticated, "service is not allowed to act on behalf of users, missing authorization token")
+		}
+
+		userID, ok := extractServiceUserID(ctx)
 		if !ok {
-			return nil, status.Errorf(codes.Unauthenticated, "missing context metadata")
+			return nil, status.Error(codes.Unauthenticated, "service caller must set x-user-id metadata")
+		}
+		return authcontext.WithUser(ctx, serviceDTO.User{UserID: &userID}), nil
+	}
+
+	user, err := secretValidator.CheckSecret(ctx, []byte(token))
+	if err != nil || user.UserID == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return authcontext.WithUser(ctx, *user), nil
+}
+
+// GetUnaryInterceptor returns interceptor which validates bearer token
+// and puts authenticated user and client certificate service into request context.
+// Methods from publicMethods are called without authorization.
+// Services from delegatingServices (matched by common name, DNS or URI SAN) may act on behalf of users
+func GetUnaryInterceptor(
+	secretValidator grpcToDoServer.IAccountSecretValidator,
+	publicMethods map[string]bool,
+	delegatingServices []string,
+) grpc.UnaryServerInterceptor {
+	delegating := delegatingSet(delegatingServices)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = withPeerService(ctx)
+
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
 		}
 
-		if len(meta["authorization"]) != 1 {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+		authCtx, err := authenticate(ctx, secretValidator, delegating)
+		if err != nil {
+			return nil, err
 		}
 
-		if !credentialService.CheckToken(meta["authorization"][0]) {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token")
+		return handler(authCtx, req)
+	}
+}
+
+// authenticatedStream overrides context of server stream
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// GetStreamInterceptor returns stream interceptor with the same rules as GetUnaryInterceptor
+func GetStreamInterceptor(
+	secretValidator grpcToDoServer.IAccountSecretValidator,
+	publicMethods map[string]bool,
+	delegatingServices []string,
+) grpc.StreamServerInterceptor {
+	delegating := delegatingSet(delegatingServices)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withPeerService(ss.Context())
+
+		if publicMethods[info.FullMethod] {
+			return handler(srv, &authenticatedStream{
+				ServerStream: ss,
+				ctx:          ctx,
+			})
+		}
+
+		authCtx, err := authenticate(ctx, secretValidator, delegating)
+		if err != nil {
+			return err
 		}
 
-		return handler(ctx, req)
+		return handler(srv, &authenticatedStream{
+			ServerStream: ss,
+			ctx:          authCtx,
+		})
 	}
 }
 
@@ -54,34 +264,26 @@ func GetUnaryInterceptor(credentialService ICredentialService) grpc.UnaryServerI
 func New(
 	log *slog.Logger,
 	port int,
-	todoItemsCreatorService grpcToDoServer.IToDoItemCreatorService,
-	todoItemsUpdaterService grpcToDoServer.IToDoItemUpdaterService,
-	todoItemsGetterService grpcToDoServer.IToDoItemGetterService,
-	todoItemsDeleterService grpcToDoServer.IToDoItemDeleterService,
-	accountSecretCreator grpcToDoServer.IAccountSecretCreator,
+	tlsConfig *tls.Config,
+	servers *grpcToDoServer.Servers,
 	accountSecretValidator grpcToDoServer.IAccountSecretValidator,
-	accountSecretDeleter grpcToDoServer.IAccountSecretDeleter,
-	credentialSevice ICredentialService,
+	delegatingServices []string,
 ) *App {
 
 	var opts []grpc.ServerOption
 
-	opts = append(opts, grpc.UnaryInterceptor(GetUnaryInterceptor(credentialSevice)))
+	opts = append(opts, grpc.UnaryInterceptor(GetUnaryInterceptor(accountSecretValidator, PublicMethods, delegatingServices)))
+	opts = append(opts, grpc.StreamInterceptor(GetStreamInterceptor(accountSecretValidator, PublicMethods, delegatingServices)))
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else {
+		log.Warn("insecure transport for gRPC")
+	}
 
-	//TODO: add TLS transport
-	log.Warn("insecure transport for gRPC")
 	gRPCServer := grpc.NewServer(opts...)
 
-	grpcToDoServer.Register(
-		gRPCServer,
-		todoItemsCreatorService,
-		todoItemsUpdaterService,
-		todoItemsGetterService,
-		todoItemsDeleterService,
-		accountSecretCreator,
-		accountSecretValidator,
-		accountSecretDeleter,
-	)
+	grpcToDoServer.Register(gRPCServer, servers)
 
 	return &App{
 		log:        log.With(slog.String("module", "grpcApp")),
@@ -0,0 +1,400 @@
+package grpcapp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	grpcToDoServer "github.com/IldarGaleev/todo-backend-service/internal/grpc/grpctodoserver"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type secretValidatorStub struct {
+	validSecret string
+	user        serviceDTO.User
+}
+
+func (v secretValidatorStub) CheckSecret(_ context.Context, secret []byte) (*serviceDTO.User, error) {
+	if string(secret) != v.validSecret {
+		return nil, errors.New("wrong secret")
+	}
+	return &v.user, nil
+}
+
+func TestGetUnaryInterceptor(t *testing.T) {
+	userID := uint64(5)
+	username := "user"
+
+	interceptor := GetUnaryInterceptor(
+		secretValidatorStub{
+			validSecret: "valid_token",
+			user:        serviceDTO.User{UserID: &userID, Username: &username},
+		},
+		PublicMethods,
+		nil,
+	)
+
+	privateMethod := &grpc.UnaryServerInfo{FullMethod: todo_protobuf_v1.ToDoService_ListTasks_FullMethodName}
+	publicMethod := &grpc.UnaryServerInfo{FullMethod: todo_protobuf_v1.ToDoService_Login_FullMethodName}
+
+	testCases := []struct {
+		name          string
+		info          *grpc.UnaryServerInfo
+		authorization []string
+		expectedCode  codes.Code
+		expectedUser  *uint64
+	}{
+		{
+			name:         "public method without token",
+			info:         publicMethod,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "missing token",
+			info:         privateMethod,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "v2 public method without token",
+			info:         &grpc.UnaryServerInfo{FullMethod: todo_protobuf_v2.AuthService_Login_FullMethodName},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "v2 private method without token",
+			info:         &grpc.UnaryServerInfo{FullMethod: todo_protobuf_v2.TaskService_ListTasks_FullMethodName},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "legacy magic string",
+			info:          privateMethod,
+			authorization: []string{"Bearer 1234"},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "token without scheme",
+			info:          privateMethod,
+			authorization: []string{"valid_token"},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "several tokens",
+			info:          privateMethod,
+			authorization: []string{"Bearer valid_token", "Bearer valid_token"},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "valid token",
+			info:          privateMethod,
+			authorization: []string{"Bearer valid_token"},
+			expectedCode:  codes.OK,
+			expectedUser:  &userID,
+		},
+		{
+			name:          "valid token with lowercase scheme",
+			info:          privateMethod,
+			authorization: []string{"bearer valid_token"},
+			expectedCode:  codes.OK,
+			expectedUser:  &userID,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			if testCase.authorization != nil {
+				md := metadata.MD{}
+				md.Append("authorization", testCase.authorization...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+
+			var handlerUser *serviceDTO.User
+			_, err := interceptor(ctx, nil, testCase.info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerUser, _ = authcontext.UserFromContext(ctx)
+				return nil, nil
+			})
+
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+
+			if testCase.expectedUser == nil {
+				require.Nil(t, handlerUser)
+			} else {
+				require.NotNil(t, handlerUser)
+				require.Equal(t, *testCase.expectedUser, *handlerUser.UserID)
+			}
+		})
+	}
+}
+
+func TestGetUnaryInterceptor_ClientCertificate(t *testing.T) {
+	userID := uint64(5)
+
+	interceptor := GetUnaryInterceptor(
+		secretValidatorStub{
+			validSecret: "valid_token",
+			user:        serviceDTO.User{UserID: &userID},
+		},
+		PublicMethods,
+		[]string{"worker.internal"},
+	)
+
+	serviceUserID := uint64(7)
+	privateMethod := &grpc.UnaryServerInfo{FullMethod: todo_protobuf_v1.ToDoService_ListTasks_FullMethodName}
+	certificate := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "reminder-worker"},
+		DNSNames: []string{"worker.internal"},
+	}
+	otherCertificate := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "report-builder"},
+		DNSNames: []string{"reports.internal"},
+	}
+
+	testCases := []struct {
+		name            string
+		verifiedChains  [][]*x509.Certificate
+		authorization   []string
+		userID          []string
+		expectedCode    codes.Code
+		expectedService string
+		expectedUser    *uint64
+	}{
+		{
+			name:           "verified certificate without token and user",
+			verifiedChains: [][]*x509.Certificate{{certificate}},
+			expectedCode:   codes.Unauthenticated,
+		},
+		{
+			name:            "verified certificate on behalf of user",
+			verifiedChains:  [][]*x509.Certificate{{certificate}},
+			userID:          []string{"7"},
+			expectedCode:    codes.OK,
+			expectedService: "reminder-worker",
+			expectedUser:    &serviceUserID,
+		},
+		{
+			name:           "not delegating certificate on behalf of user",
+			verifiedChains: [][]*x509.Certificate{{otherCertificate}},
+			userID:         []string{"7"},
+			expectedCode:   codes.Unauthenticated,
+		},
+		{
+			name:           "verified certificate with malformed user",
+			verifiedChains: [][]*x509.Certificate{{certificate}},
+			userID:         []string{"user"},
+			expectedCode:   codes.Unauthenticated,
+		},
+		{
+			name:            "verified certificate with token",
+			verifiedChains:  [][]*x509.Certificate{{certificate}},
+			authorization:   []string{"Bearer valid_token"},
+			expectedCode:    codes.OK,
+			expectedService: "reminder-worker",
+			expectedUser:    &userID,
+		},
+		{
+			name:           "verified certificate with invalid token",
+			verifiedChains: [][]*x509.Certificate{{certificate}},
+			authorization:  []string{"Bearer wrong_token"},
+			expectedCode:   codes.Unauthenticated,
+		},
+		{
+			name:         "TLS without client certificate",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "user without client certificate",
+			userID:       []string{"7"},
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{
+				AuthInfo: credentials.TLSInfo{
+					State: tls.ConnectionState{VerifiedChains: testCase.verifiedChains},
+				},
+			})
+			md := metadata.MD{}
+			md.Append("authorization", testCase.authorization...)
+			md.Append(userIDMetadata, testCase.userID...)
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			var handlerService *authcontext.Service
+			var handlerUser *serviceDTO.User
+			_, err := interceptor(ctx, nil, privateMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerService, _ = authcontext.ServiceFromContext(ctx)
+				handlerUser, _ = authcontext.UserFromContext(ctx)
+				return nil, nil
+			})
+
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+
+			if testCase.expectedService == "" {
+				require.Nil(t, handlerService)
+			} else {
+				require.NotNil(t, handlerService)
+				require.Equal(t, testCase.expectedService, handlerService.Name)
+				require.Equal(t, certificate.DNSNames, handlerService.DNSNames)
+			}
+
+			if testCase.expectedUser == nil {
+				require.Nil(t, handlerUser)
+			} else {
+				require.NotNil(t, handlerUser)
+				require.Equal(t, *testCase.expectedUser, *handlerUser.UserID)
+			}
+		})
+	}
+}
+
+type testCert struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	tls         tls.Certificate
+}
+
+func createCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		certificate: certificate,
+		key:         key,
+		tls:         tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+	}
+}
+
+type taskGetterStub struct {
+	grpcToDoServer.IToDoItemGetterService
+	ownerIDs chan uint64
+}
+
+func (g taskGetterStub) GetByID(_ context.Context, itemID uint64, ownerID uint64) (*serviceDTO.ToDoItem, error) {
+	g.ownerIDs <- ownerID
+	return &serviceDTO.ToDoItem{ID: itemID, OwnerID: ownerID}, nil
+}
+
+func TestApp_ServiceCertificate(t *testing.T) {
+	ca := createCert(t, "test-ca", nil)
+	serverCert := createCert(t, "todo.internal", ca)
+	serviceCert := createCert(t, "reminder-worker", ca)
+	otherServiceCert := createCert(t, "report-builder", ca)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.certificate)
+
+	getter := taskGetterStub{ownerIDs: make(chan uint64, 1)}
+	servers := grpcToDoServer.New(
+		nil, nil, getter, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+	)
+	app := New(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		0,
+		&tls.Config{
+			Certificates: []tls.Certificate{serverCert.tls},
+			ClientCAs:    pool,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+		},
+		servers,
+		secretValidatorStub{},
+		[]string{"reminder-worker"},
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.gRPCServer.Serve(listener)
+	}()
+	defer app.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serviceCert.tls},
+		RootCAs:      pool,
+		ServerName:   "todo.internal",
+	})))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := todo_protobuf_v2.NewTaskServiceClient(conn)
+
+	t.Run("on behalf of user", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), userIDMetadata, "7")
+
+		task, err := client.GetTask(ctx, &todo_protobuf_v2.GetTaskRequest{TaskId: 10})
+
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), <-getter.ownerIDs)
+		require.Equal(t, uint64(10), task.GetTaskId())
+		require.Equal(t, uint64(7), task.GetOwnerId())
+	})
+
+	t.Run("without user", func(t *testing.T) {
+		_, err := client.GetTask(context.Background(), &todo_protobuf_v2.GetTaskRequest{TaskId: 10})
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Empty(t, getter.ownerIDs)
+	})
+
+	t.Run("not delegating service on behalf of user", func(t *testing.T) {
+		otherConn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{otherServiceCert.tls},
+			RootCAs:      pool,
+			ServerName:   "todo.internal",
+		})))
+		require.NoError(t, err)
+		defer otherConn.Close()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), userIDMetadata, "7")
+
+		_, err = todo_protobuf_v2.NewTaskServiceClient(otherConn).GetTask(ctx, &todo_protobuf_v2.GetTaskRequest{TaskId: 10})
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Empty(t, getter.ownerIDs)
+	})
+}
@@ -0,0 +1,402 @@
+package httpapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	grpcApp "github.com/IldarGaleev/todo-backend-service/internal/app/grpcapp"
+	grpcToDoServer "github.com/IldarGaleev/todo-backend-service/internal/grpc/grpctodoserver"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBodySize limits request body in bytes
+const maxBodySize = 1 << 20
+
+// forwardedHeaders HTTP headers passed to handlers as gRPC metadata
+var forwardedHeaders = []string{"authorization", "user-agent", "x-device"}
+
+var marshalOptions = protojson.MarshalOptions{}
+
+var unmarshalOptions = protojson.UnmarshalOptions{}
+
+// route binds HTTP pattern to RPC.
+// Wildcards of pattern fill request fields of the same name unless pathFields maps them to another field.
+// fixedFields are set regardless of request
+type route struct {
+	pattern     string
+	pathFields  map[string]string
+	fixedFields map[string]string
+}
+
+type gateway struct {
+	log               *slog.Logger
+	server            todo_protobuf_v1.ToDoServiceServer
+	unaryInterceptor  grpc.UnaryServerInterceptor
+	streamInterceptor grpc.StreamServerInterceptor
+	// streamsCtx ends streaming responses on shutdown
+	streamsCtx context.Context
+}
+
+// newGateway returns handler calling server RPCs with the same authorization as gRPC server
+func newGateway(
+	log *slog.Logger,
+	server todo_protobuf_v1.ToDoServiceServer,
+	accountSecretValidator grpcToDoServer.IAccountSecretValidator,
+	delegatingServices []string,
+	streamsCtx context.Context,
+) http.Handler {
+	g := &gateway{
+		log:               log.With(slog.String("module", "httpGateway")),
+		server:            server,
+		unaryInterceptor:  grpcApp.GetUnaryInterceptor(accountSecretValidator, grpcApp.PublicMethods, delegatingServices),
+		streamInterceptor: grpcApp.GetStreamInterceptor(accountSecretValidator, grpcApp.PublicMethods, delegatingServices),
+		streamsCtx:        streamsCtx,
+	}
+
+	mux := http.NewServeMux()
+
+	taskResource := map[string]string{"resource.type": todo_protobuf_v1.ShareResourceType_SHARE_RESOURCE_TYPE_TASK.String()}
+	projectResource := map[string]string{"resource.type": todo_protobuf_v1.ShareResourceType_SHARE_RESOURCE_TYPE_PROJECT.String()}
+	resourceID := map[string]string{"id": "resource.id"}
+
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/login"}, todo_protobuf_v1.ToDoService_Login_FullMethodName, server.Login)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/logout"}, todo_protobuf_v1.ToDoService_Logout_FullMethodName, server.Logout)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/check"}, todo_protobuf_v1.ToDoService_CheckSecret_FullMethodName, server.CheckSecret)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/refresh"}, todo_protobuf_v1.ToDoService_RefreshToken_FullMethodName, server.RefreshToken)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/mfa/verify"}, todo_protobuf_v1.ToDoService_VerifyMfa_FullMethodName, server.VerifyMfa)
+	handleUnary(mux, g, route{pattern: "GET /v1/auth/keys"}, todo_protobuf_v1.ToDoService_GetPublicKeys_FullMethodName, server.GetPublicKeys)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/password-reset"}, todo_protobuf_v1.ToDoService_RequestPasswordReset_FullMethodName, server.RequestPasswordReset)
+	handleUnary(mux, g, route{pattern: "POST /v1/auth/password-reset/confirm"}, todo_protobuf_v1.ToDoService_ConfirmPasswordReset_FullMethodName, server.ConfirmPasswordReset)
+
+	handleUnary(mux, g, route{pattern: "POST /v1/accounts"}, todo_protobuf_v1.ToDoService_Register_FullMethodName, server.Register)
+	handleUnary(mux, g, route{pattern: "POST /v1/account/password"}, todo_protobuf_v1.ToDoService_ChangePassword_FullMethodName, server.ChangePassword)
+	handleUnary(mux, g, route{pattern: "POST /v1/account/totp"}, todo_protobuf_v1.ToDoService_EnrollTotp_FullMethodName, server.EnrollTotp)
+	handleUnary(mux, g, route{pattern: "POST /v1/account/totp/confirm"}, todo_protobuf_v1.ToDoService_ConfirmTotp_FullMethodName, server.ConfirmTotp)
+	handleUnary(mux, g, route{pattern: "POST /v1/account/totp/disable"}, todo_protobuf_v1.ToDoService_DisableTotp_FullMethodName, server.DisableTotp)
+	handleUnary(mux, g, route{pattern: "POST /v1/account/recovery-codes"}, todo_protobuf_v1.ToDoService_GenerateRecoveryCodes_FullMethodName, server.GenerateRecoveryCodes)
+
+	handleUnary(mux, g, route{pattern: "GET /v1/sessions"}, todo_protobuf_v1.ToDoService_ListSessions_FullMethodName, server.ListSessions)
+	handleUnary(mux, g, route{pattern: "DELETE /v1/sessions/{session_id}"}, todo_protobuf_v1.ToDoService_RevokeSession_FullMethodName, server.RevokeSession)
+	handleUnary(mux, g, route{pattern: "POST /v1/sessions/revoke-others"}, todo_protobuf_v1.ToDoService_RevokeAllOtherSessions_FullMethodName, server.RevokeAllOtherSessions)
+
+	handleUnary(mux, g, route{pattern: "POST /v1/tasks"}, todo_protobuf_v1.ToDoService_CreateTask_FullMethodName, server.CreateTask)
+	handleUnary(mux, g, route{pattern: "GET /v1/tasks"}, todo_protobuf_v1.ToDoService_ListTasks_FullMethodName, server.ListTasks)
+	handleUnary(mux, g, route{pattern: "GET /v1/tasks/{task_id}"}, todo_protobuf_v1.ToDoService_GetTaskByID_FullMethodName, server.GetTaskByID)
+	handleUnary(mux, g, route{pattern: "PATCH /v1/tasks/{task_id}"}, todo_protobuf_v1.ToDoService_UpdateTaskByID_FullMethodName, server.UpdateTaskByID)
+	handleUnary(mux, g, route{pattern: "DELETE /v1/tasks/{task_id}"}, todo_protobuf_v1.ToDoService_DeleteTaskByID_FullMethodName, server.DeleteTaskByID)
+	handleServerStream(mux, g, route{pattern: "GET /v1/tasks/watch"}, todo_protobuf_v1.ToDoService_WatchTasks_FullMethodName, server.WatchTasks)
+
+	handleUnary(mux, g, route{pattern: "POST /v1/tasks/{parent_id}/subtasks"}, todo_protobuf_v1.ToDoService_AddSubtask_FullMethodName, server.AddSubtask)
+	handleUnary(mux, g, route{pattern: "GET /v1/tasks/{parent_id}/subtasks"}, todo_protobuf_v1.ToDoService_ListSubtasks_FullMethodName, server.ListSubtasks)
+	handleUnary(mux, g, route{pattern: "POST /v1/tasks/{parent_id}/subtasks/reorder"}, todo_protobuf_v1.ToDoService_ReorderSubtasks_FullMethodName, server.ReorderSubtasks)
+
+	handleUnary(mux, g, route{pattern: "POST /v1/projects"}, todo_protobuf_v1.ToDoService_CreateProject_FullMethodName, server.CreateProject)
+	handleUnary(mux, g, route{pattern: "GET /v1/projects"}, todo_protobuf_v1.ToDoService_ListProjects_FullMethodName, server.ListProjects)
+	handleUnary(mux, g, route{pattern: "PATCH /v1/projects/{project_id}"}, todo_protobuf_v1.ToDoService_RenameProject_FullMethodName, server.RenameProject)
+	handleUnary(mux, g, route{pattern: "POST /v1/projects/{project_id}/archive"}, todo_protobuf_v1.ToDoService_ArchiveProject_FullMethodName, server.ArchiveProject)
+	handleUnary(mux, g, route{pattern: "DELETE /v1/projects/{project_id}"}, todo_protobuf_v1.ToDoService_DeleteProject_FullMethodName, server.DeleteProject)
+
+	for prefix, resourceType := range map[string]map[string]string{"/v1/tasks": taskResource, "/v1/projects": projectResource} {
+		handleUnary(mux, g, route{pattern: "POST " + prefix + "/{id}/members", pathFields: resourceID, fixedFields: resourceType}, todo_protobuf_v1.ToDoService_InviteMember_FullMethodName, server.InviteMember)
+		handleUnary(mux, g, route{pattern: "GET " + prefix + "/{id}/members", pathFields: resourceID, fixedFields: resourceType}, todo_protobuf_v1.ToDoService_ListMembers_FullMethodName, server.ListMembers)
+		handleUnary(mux, g, route{pattern: "PATCH " + prefix + "/{id}/members/{user_id}", pathFields: resourceID, fixedFields: resourceType}, todo_protobuf_v1.ToDoService_ChangeMemberRole_FullMethodName, server.ChangeMemberRole)
+		handleUnary(mux, g, route{pattern: "DELETE " + prefix + "/{id}/members/{user_id}", pathFields: resourceID, fixedFields: resourceType}, todo_protobuf_v1.ToDoService_RevokeMember_FullMethodName, server.RevokeMember)
+	}
+
+	return mux
+}
+
+// handleUnary registers handler of unary RPC
+func handleUnary[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp proto.Message](
+	mux *http.ServeMux,
+	g *gateway,
+	r route,
+	fullMethod string,
+	call func(context.Context, PReq) (Resp, error),
+) {
+	info := &grpc.UnaryServerInfo{Server: g.server, FullMethod: fullMethod}
+
+	mux.HandleFunc(r.pattern, func(w http.ResponseWriter, httpReq *http.Request) {
+		req := PReq(new(Req))
+		if err := decodeRequest(httpReq, r, req); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		resp, err := g.unaryInterceptor(incomingContext(httpReq, httpReq.Context()), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(ctx, req.(PReq))
+		})
+		if err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		data, err := marshalOptions.Marshal(resp.(proto.Message))
+		if err != nil {
+			g.writeError(w, status.Error(codes.Internal, "Internal error"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+// handleServerStream registers handler of server streaming RPC.
+// Messages are written as newline delimited JSON objects {"result": message},
+// the error ending the stream as {"error": status}
+func handleServerStream[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp any](
+	mux *http.ServeMux,
+	g *gateway,
+	r route,
+	fullMethod string,
+	call func(PReq, grpc.ServerStreamingServer[Resp]) error,
+) {
+	info := &grpc.StreamServerInfo{FullMethod: fullMethod, IsServerStream: true}
+
+	mux.HandleFunc(r.pattern, func(w http.ResponseWriter, httpReq *http.Request) {
+		req := PReq(new(Req))
+		if err := decodeRequest(httpReq, r, req); err != nil {
+			g.writeError(w, err)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(incomingContext(httpReq, httpReq.Context()))
+		defer cancel()
+		stop := context.AfterFunc(g.streamsCtx, cancel)
+		defer stop()
+
+		stream := &responseStream[Resp]{ctx: ctx, w: w}
+		err := g.streamInterceptor(g.server, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+			return call(req, &typedStream[Resp]{ServerStream: ss})
+		})
+		if err == nil {
+			return
+		}
+
+		if !stream.started {
+			g.writeError(w, err)
+			return
+		}
+
+		data, marshalErr := marshalOptions.Marshal(status.Convert(err).Proto())
+		if marshalErr != nil {
+			return
+		}
+		stream.writeLine("error", data)
+	})
+}
+
+// decodeRequest fills request from JSON body, query parameters and path wildcards in this order
+func decodeRequest(httpReq *http.Request, r route, req proto.Message) error {
+	body, err := io.ReadAll(io.LimitReader(httpReq.Body, maxBodySize+1))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "failed to read body")
+	}
+	if len(body) > maxBodySize {
+		return status.Error(codes.InvalidArgument, "body too large")
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := unmarshalOptions.Unmarshal(body, req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid body: %s", err)
+		}
+	}
+
+	message := req.ProtoReflect()
+
+	for key, values := range httpReq.URL.Query() {
+		for _, value := range values {
+			if err := setField(message, key, value); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid query parameter %q: %s", key, err)
+			}
+		}
+	}
+
+	for _, wildcard := range patternWildcards(r.pattern) {
+		field := wildcard
+		if mapped, ok := r.pathFields[wildcard]; ok {
+			field = mapped
+		}
+		if err := setField(message, field, httpReq.PathValue(wildcard)); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid path parameter %q: %s", wildcard, err)
+		}
+	}
+
+	for field, value := range r.fixedFields {
+		if err := setField(message, field, value); err != nil {
+			return status.Errorf(codes.Internal, "invalid route field %q: %s", field, err)
+		}
+	}
+
+	return nil
+}
+
+// patternWildcards returns names of {wildcards} of ServeMux pattern
+func patternWildcards(pattern string) []string {
+	var wildcards []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			wildcards = append(wildcards, strings.TrimSuffix(segment[1:len(segment)-1], "..."))
+		}
+	}
+	return wildcards
+}
+
+// incomingContext returns ctx with gRPC metadata and peer of HTTP request
+func incomingContext(httpReq *http.Request, ctx context.Context) context.Context {
+	md := metadata.MD{}
+	for _, header := range forwardedHeaders {
+		if values := httpReq.Header.Values(header); len(values) > 0 {
+			md.Append(header, values...)
+		}
+	}
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	p := &peer.Peer{}
+	if addr, err := net.ResolveTCPAddr("tcp", httpReq.RemoteAddr); err == nil {
+		p.Addr = addr
+	}
+	if httpReq.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *httpReq.TLS}
+	}
+
+	return peer.NewContext(ctx, p)
+}
+
+// httpStatusFromCode maps gRPC status code to HTTP status
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError writes gRPC status as JSON google.rpc.Status with mapped HTTP status.
+// RetryInfo detail is also sent as Retry-After header
+func (g *gateway) writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+
+	data, marshalErr := marshalOptions.Marshal(st.Proto())
+	if marshalErr != nil {
+		g.log.Error("failed to marshal status", slog.Any("err", marshalErr))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			seconds := retryInfo.GetRetryDelay().AsDuration().Round(time.Second) / time.Second
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFromCode(st.Code()))
+	_, _ = w.Write(data)
+}
+
+// responseStream implements grpc.ServerStream writing messages to HTTP response
+type responseStream[Resp any] struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *responseStream[Resp]) SetHeader(metadata.MD) error  { return nil }
+func (s *responseStream[Resp]) SendHeader(metadata.MD) error { return nil }
+func (s *responseStream[Resp]) SetTrailer(metadata.MD)       {}
+func (s *responseStream[Resp]) Context() context.Context     { return s.ctx }
+
+func (s *responseStream[Resp]) SendMsg(m interface{}) error {
+	message, ok := m.(proto.Message)
+	if !ok {
+		return status.Error(codes.Internal, "not a protobuf message")
+	}
+
+	data, err := marshalOptions.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	if !s.started {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.started = true
+	}
+	return s.writeLine("result", data)
+}
+
+func (s *responseStream[Resp]) RecvMsg(interface{}) error {
+	return io.EOF
+}
+
+func (s *responseStream[Resp]) writeLine(key string, data []byte) error {
+	line := make([]byte, 0, len(data)+len(key)+6)
+	line = append(line, `{"`...)
+	line = append(line, key...)
+	line = append(line, `":`...)
+	line = append(line, data...)
+	line = append(line, "}\n"...)
+
+	if _, err := s.w.Write(line); err != nil {
+		return errors.Join(status.Error(codes.Canceled, "client disconnected"), err)
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// typedStream adds Send of grpc.ServerStreamingServer to stream passed through interceptor
+type typedStream[Resp any] struct {
+	grpc.ServerStream
+}
+
+func (s *typedStream[Resp]) Send(m *Resp) error {
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,286 @@
+package httpapp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type secretValidatorStub struct {
+	validSecret string
+	user        serviceDTO.User
+}
+
+func (v secretValidatorStub) CheckSecret(_ context.Context, secret []byte) (*serviceDTO.User, error) {
+	if string(secret) != v.validSecret {
+		return nil, errors.New("wrong secret")
+	}
+	return &v.user, nil
+}
+
+// todoServerStub records requests and returns canned responses
+type todoServerStub struct {
+	todo_protobuf_v1.UnimplementedToDoServiceServer
+
+	listRequest   *todo_protobuf_v1.ListTasksRequest
+	updateRequest *todo_protobuf_v1.UpdateTaskByIdRequest
+	inviteRequest *todo_protobuf_v1.InviteMemberRequest
+}
+
+func (s *todoServerStub) Login(ctx context.Context, req *todo_protobuf_v1.LoginRequest) (*todo_protobuf_v1.LoginResponce, error) {
+	if req.GetPassword() != "password" {
+		st, _ := status.New(codes.ResourceExhausted, "too many failed attempts, try later").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)})
+		return nil, st.Err()
+	}
+	return &todo_protobuf_v1.LoginResponce{Token: "valid_token"}, nil
+}
+
+func (s *todoServerStub) CreateTask(ctx context.Context, req *todo_protobuf_v1.CreateTaskRequest) (*todo_protobuf_v1.CreateTaskResponce, error) {
+	if _, ok := authcontext.UserFromContext(ctx); !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return &todo_protobuf_v1.CreateTaskResponce{TaskId: 7}, nil
+}
+
+func (s *todoServerStub) GetTaskByID(ctx context.Context, req *todo_protobuf_v1.TaskByIdRequest) (*todo_protobuf_v1.GetTaskByIdResponce, error) {
+	if req.GetTaskId() != 7 {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+	return &todo_protobuf_v1.GetTaskByIdResponce{TaskId: 7, Title: "task"}, nil
+}
+
+func (s *todoServerStub) ListTasks(ctx context.Context, req *todo_protobuf_v1.ListTasksRequest) (*todo_protobuf_v1.ListTasksResponce, error) {
+	s.listRequest = req
+	return &todo_protobuf_v1.ListTasksResponce{}, nil
+}
+
+func (s *todoServerStub) UpdateTaskByID(ctx context.Context, req *todo_protobuf_v1.UpdateTaskByIdRequest) (*todo_protobuf_v1.ChangedTaskByIdResponce, error) {
+	s.updateRequest = req
+	return &todo_protobuf_v1.ChangedTaskByIdResponce{TaskId: req.GetTaskId(), IsSuccess: true}, nil
+}
+
+func (s *todoServerStub) InviteMember(ctx context.Context, req *todo_protobuf_v1.InviteMemberRequest) (*todo_protobuf_v1.MemberResponce, error) {
+	s.inviteRequest = req
+	return &todo_protobuf_v1.MemberResponce{Username: req.GetUsername(), Role: req.GetRole()}, nil
+}
+
+func (s *todoServerStub) WatchTasks(req *todo_protobuf_v1.WatchTasksRequest, stream grpc.ServerStreamingServer[todo_protobuf_v1.TaskEvent]) error {
+	for _, id := range []uint64{1, 2} {
+		err := stream.Send(&todo_protobuf_v1.TaskEvent{Type: todo_protobuf_v1.TaskEventType_TASK_EVENT_TYPE_CREATED, TaskId: id})
+		if err != nil {
+			return err
+		}
+	}
+	return status.Error(codes.Unavailable, "event stream overflow, reconnect with the last resume token")
+}
+
+func createGateway(t *testing.T) (*httptest.Server, *todoServerStub) {
+	userID := uint64(5)
+	server := &todoServerStub{}
+
+	handler := newGateway(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		server,
+		secretValidatorStub{validSecret: "valid_token", user: serviceDTO.User{UserID: &userID}},
+		nil,
+		context.Background(),
+	)
+
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	return httpServer, server
+}
+
+func doRequest(t *testing.T, server *httptest.Server, method string, path string, body string, token string) (*http.Response, map[string]interface{}) {
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+
+	return resp, decoded
+}
+
+func TestGateway_Unary(t *testing.T) {
+	server, _ := createGateway(t)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:           "public method",
+			method:         http.MethodPost,
+			path:           "/v1/auth/login",
+			body:           `{"email": "user", "password": "password"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"token": "valid_token"},
+		},
+		{
+			name:           "body and token",
+			method:         http.MethodPost,
+			path:           "/v1/tasks",
+			body:           `{"title": "task", "priority": "TASK_PRIORITY_HIGH"}`,
+			token:          "valid_token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"taskId": "7"},
+		},
+		{
+			name:           "missing token",
+			method:         http.MethodPost,
+			path:           "/v1/tasks",
+			body:           `{"title": "task"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   map[string]interface{}{"code": float64(codes.Unauthenticated), "message": "missing authorization token"},
+		},
+		{
+			name:           "path parameter",
+			method:         http.MethodGet,
+			path:           "/v1/tasks/7",
+			token:          "valid_token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"taskId": "7", "title": "task"},
+		},
+		{
+			name:           "not found",
+			method:         http.MethodGet,
+			path:           "/v1/tasks/8",
+			token:          "valid_token",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   map[string]interface{}{"code": float64(codes.NotFound), "message": "Task not found"},
+		},
+		{
+			name:           "invalid path parameter",
+			method:         http.MethodGet,
+			path:           "/v1/tasks/abc",
+			token:          "valid_token",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			path:           "/v1/tasks",
+			body:           `{"unknown": 1}`,
+			token:          "valid_token",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, body := doRequest(t, server, testCase.method, testCase.path, testCase.body, testCase.token)
+
+			require.Equal(t, testCase.expectedStatus, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			for key, value := range testCase.expectedBody {
+				require.Equal(t, value, body[key], key)
+			}
+		})
+	}
+}
+
+func TestGateway_Unary_RetryAfter(t *testing.T) {
+	server, _ := createGateway(t)
+
+	resp, _ := doRequest(t, server, http.MethodPost, "/v1/auth/login", `{"email": "user", "password": "wrong"}`, "")
+
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "3", resp.Header.Get("Retry-After"))
+}
+
+func TestGateway_Unary_RequestFields(t *testing.T) {
+	server, stub := createGateway(t)
+
+	resp, _ := doRequest(t, server, http.MethodGet, "/v1/tasks?is_done=false&priorities=TASK_PRIORITY_HIGH&priorities=4&pageSize=10&due_before=2026-01-02T03:04:05Z", "", "valid_token")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, stub.listRequest.IsDone)
+	require.False(t, stub.listRequest.GetIsDone())
+	require.Equal(t, []todo_protobuf_v1.TaskPriority{
+		todo_protobuf_v1.TaskPriority_TASK_PRIORITY_HIGH,
+		todo_protobuf_v1.TaskPriority_TASK_PRIORITY_URGENT,
+	}, stub.listRequest.GetPriorities())
+	require.Equal(t, uint32(10), stub.listRequest.GetPageSize())
+	require.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), stub.listRequest.GetDueBefore().AsTime())
+
+	resp, _ = doRequest(t, server, http.MethodPatch, "/v1/tasks/7", `{"taskId": "9", "isDone": true}`, "valid_token")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, uint64(7), stub.updateRequest.GetTaskId())
+	require.True(t, stub.updateRequest.GetIsDone())
+	require.Nil(t, stub.updateRequest.Title)
+
+	resp, body := doRequest(t, server, http.MethodPost, "/v1/projects/3/members", `{"username": "friend", "role": "SHARE_ROLE_EDITOR"}`, "valid_token")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, todo_protobuf_v1.ShareResourceType_SHARE_RESOURCE_TYPE_PROJECT, stub.inviteRequest.GetResource().GetType())
+	require.Equal(t, uint64(3), stub.inviteRequest.GetResource().GetId())
+	require.Equal(t, "SHARE_ROLE_EDITOR", body["role"])
+}
+
+func TestGateway_ServerStream(t *testing.T) {
+	server, _ := createGateway(t)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/tasks/watch", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var lines []map[string]map[string]interface{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, lines, 3)
+	require.Equal(t, "1", lines[0]["result"]["taskId"])
+	require.Equal(t, "2", lines[1]["result"]["taskId"])
+	require.Equal(t, float64(codes.Unavailable), lines[2]["error"]["code"])
+}
+
+func TestGateway_ServerStream_Unauthenticated(t *testing.T) {
+	server, _ := createGateway(t)
+
+	resp, body := doRequest(t, server, http.MethodGet, "/v1/tasks/watch", "", "")
+
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, float64(codes.Unauthenticated), body["code"])
+}
@@ -0,0 +1,116 @@
+// Package httpapp implements HTTP/JSON gateway to ToDoService
+package httpapp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	grpcToDoServer "github.com/IldarGaleev/todo-backend-service/internal/grpc/grpctodoserver"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+)
+
+// shutdownTimeout limits waiting for active requests on Stop
+const shutdownTimeout = 10 * time.Second
+
+// readHeaderTimeout limits reading of request headers
+const readHeaderTimeout = 10 * time.Second
+
+// HTTP Application
+type App struct {
+	log        *slog.Logger
+	httpServer *http.Server
+	tlsConfig  *tls.Config
+	port       int
+}
+
+var (
+	ErrHttpServe  = errors.New("http app: serve error")
+	ErrHttpListen = errors.New("http app: listen error")
+)
+
+// Create HTTP application instance
+func New(
+	log *slog.Logger,
+	port int,
+	tlsConfig *tls.Config,
+	todoServer todo_protobuf_v1.ToDoServiceServer,
+	accountSecretValidator grpcToDoServer.IAccountSecretValidator,
+	delegatingServices []string,
+) *App {
+	if tlsConfig == nil {
+		log.Warn("insecure transport for HTTP")
+	}
+
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+
+	httpServer := &http.Server{
+		Handler:           newGateway(log, todoServer, accountSecretValidator, delegatingServices, streamsCtx),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	// Shutdown waits for active requests, streams are ended to not block it
+	httpServer.RegisterOnShutdown(cancelStreams)
+
+	return &App{
+		log:        log.With(slog.String("module", "httpApp")),
+		httpServer: httpServer,
+		tlsConfig:  tlsConfig,
+		port:       port,
+	}
+}
+
+// Run HTTP server listener, panic if failed
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// Run HTTP server listener
+func (a *App) Run() error {
+	log := a.log.With(slog.String("method", "Run"))
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+
+	if err != nil {
+		return errors.Join(ErrHttpListen, err)
+	}
+
+	log.Info(
+		"HTTP server started",
+		slog.String("addr", listener.Addr().String()),
+		slog.Int("port", a.port),
+	)
+
+	if a.tlsConfig != nil {
+		err = a.httpServer.ServeTLS(listener, "", "")
+	} else {
+		err = a.httpServer.Serve(listener)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Join(ErrHttpServe, err)
+	}
+
+	return nil
+}
+
+// Stop HTTP server listener
+func (a *App) Stop() {
+	log := a.log.With(slog.String("method", "Stop"))
+
+	log.Info("stopping HTTP server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := a.httpServer.Shutdown(ctx); err != nil {
+		log.Error("HTTP server shutdown error", slog.Any("err", err))
+	}
+}
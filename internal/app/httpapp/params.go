@@ -0,0 +1,100 @@
+package httpapp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	errUnknownField     = errors.New("unknown field")
+	errUnsupportedField = errors.New("field can not be set from string")
+)
+
+// timestampName full name of google.protobuf.Timestamp
+var timestampName = (&timestamppb.Timestamp{}).ProtoReflect().Descriptor().FullName()
+
+// setField sets field of message by dotted path of proto or JSON field names.
+// Repeated fields get value appended, enums accept names and numbers, timestamps RFC 3339
+func setField(message protoreflect.Message, path string, value string) error {
+	names := strings.Split(path, ".")
+
+	for _, name := range names[:len(names)-1] {
+		field := findField(message.Descriptor(), name)
+		if field == nil || field.Message() == nil || field.IsList() || field.IsMap() {
+			return fmt.Errorf("%w: %s", errUnknownField, path)
+		}
+		message = message.Mutable(field).Message()
+	}
+
+	field := findField(message.Descriptor(), names[len(names)-1])
+	if field == nil || field.IsMap() {
+		return fmt.Errorf("%w: %s", errUnknownField, path)
+	}
+
+	fieldValue, err := parseValue(field, value)
+	if err != nil {
+		return err
+	}
+
+	if field.IsList() {
+		message.Mutable(field).List().Append(fieldValue)
+		return nil
+	}
+	message.Set(field, fieldValue)
+	return nil
+}
+
+func findField(descriptor protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := descriptor.Fields()
+	if field := fields.ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+	return fields.ByJSONName(name)
+}
+
+func parseValue(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		parsed, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(parsed), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(parsed)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(parsed), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(parsed)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(parsed), err
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByName(protoreflect.Name(value)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || field.Enum().Values().ByNumber(protoreflect.EnumNumber(parsed)) == nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %s value %q", field.Enum().Name(), value)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(parsed)), nil
+	case protoreflect.MessageKind:
+		if field.Message().FullName() == timestampName {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfMessage(timestamppb.New(parsed).ProtoReflect()), nil
+		}
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("%w: %s", errUnsupportedField, field.Name())
+}
@@ -0,0 +1,93 @@
+// Package janitorapp implements periodic removal of expired tasks from trash
+package janitorapp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IToDoItemPurger interface {
+	StorageToDoItemPurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// App permanently deletes tasks kept in trash longer than retention
+type App struct {
+	logger    *slog.Logger
+	purger    IToDoItemPurger
+	retention time.Duration
+	interval  time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates trash janitor. Non-positive interval disables it
+func New(
+	log *slog.Logger,
+	purger IToDoItemPurger,
+	retention time.Duration,
+	interval time.Duration,
+) *App {
+	return &App{
+		logger:    log.With(slog.String("module", "janitorApp")),
+		purger:    purger,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// MustRun starts periodic removal, the first one runs immediately
+func (a *App) MustRun() {
+	if a.interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.done = make(chan struct{})
+	go a.watch(ctx)
+}
+
+// Stop stops periodic removal and waits for running one
+func (a *App) Stop() {
+	if a.cancel == nil {
+		return
+	}
+	a.cancel()
+	<-a.done
+}
+
+func (a *App) watch(ctx context.Context) {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge permanently deletes tasks moved to trash more than retention ago
+func (a *App) Purge(ctx context.Context) {
+	log := a.logger.With(slog.String("method", "Purge"))
+
+	purged, err := a.purger.StorageToDoItemPurgeDeleted(ctx, time.Now().Add(-a.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("purge trash error", slog.Any("err", err))
+		}
+		return
+	}
+
+	if purged > 0 {
+		log.Info("expired tasks removed from trash", slog.Int64("count", purged))
+	}
+}
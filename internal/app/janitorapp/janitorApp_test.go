@@ -0,0 +1,69 @@
+package janitorapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type purgerStub struct {
+	deletedBefore chan time.Time
+	err           error
+}
+
+func (p purgerStub) StorageToDoItemPurgeDeleted(_ context.Context, deletedBefore time.Time) (int64, error) {
+	p.deletedBefore <- deletedBefore
+	return 1, p.err
+}
+
+func TestApp_Purge(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{name: "success"},
+		{name: "storage error", err: errors.New("db error")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			purger := purgerStub{deletedBefore: make(chan time.Time, 1), err: testCase.err}
+			app := New(log, purger, time.Hour, time.Minute)
+
+			app.Purge(context.Background())
+
+			require.WithinDuration(t, time.Now().Add(-time.Hour), <-purger.deletedBefore, time.Second)
+		})
+	}
+}
+
+func TestApp_MustRun(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("purges periodically", func(t *testing.T) {
+		purger := purgerStub{deletedBefore: make(chan time.Time, 10)}
+		app := New(log, purger, time.Hour, time.Millisecond)
+
+		app.MustRun()
+		<-purger.deletedBefore
+		<-purger.deletedBefore
+		app.Stop()
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		purger := purgerStub{deletedBefore: make(chan time.Time, 1)}
+		app := New(log, purger, time.Hour, 0)
+
+		app.MustRun()
+		app.Stop()
+
+		require.Empty(t, purger.deletedBefore)
+	})
+}
@@ -0,0 +1,331 @@
+package grpctodoserver
+
+import (
+	"context"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// authServerV2 implements v2 AuthService
+type authServerV2 struct {
+	todo_protobuf_v2.UnimplementedAuthServiceServer
+	services
+}
+
+func (s *authServerV2) Login(
+	ctx context.Context,
+	req *todo_protobuf_v2.LoginRequest,
+) (*todo_protobuf_v2.LoginResponse, error) {
+	username := req.GetUsername()
+	result, err := s.accountSecretCreator.CreateUserSecret(
+		ctx,
+		serviceDTO.User{
+			Username: &username,
+			Password: req.GetPassword(),
+		},
+		clientFromContext(ctx),
+	)
+	if err != nil {
+		return nil, loginError(err)
+	}
+
+	if result.MfaChallenge != nil {
+		return &todo_protobuf_v2.LoginResponse{
+			Result: &todo_protobuf_v2.LoginResponse_MfaChallenge{
+				MfaChallenge: &todo_protobuf_v2.MfaChallenge{
+					Token:     result.MfaChallenge.Token,
+					ExpiresAt: timestamppb.New(result.MfaChallenge.ExpiresAt),
+				},
+			},
+		}, nil
+	}
+
+	return loginResponseV2(result.Tokens), nil
+}
+
+func (s *authServerV2) VerifyMfa(
+	ctx context.Context,
+	req *todo_protobuf_v2.VerifyMfaRequest,
+) (*todo_protobuf_v2.LoginResponse, error) {
+	tokens, err := s.mfaVerifierService.VerifyMfa(
+		ctx,
+		req.GetMfaChallenge(),
+		req.GetCode(),
+		clientFromContext(ctx),
+	)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return loginResponseV2(tokens), nil
+}
+
+func (s *authServerV2) RefreshToken(
+	ctx context.Context,
+	req *todo_protobuf_v2.RefreshTokenRequest,
+) (*todo_protobuf_v2.LoginResponse, error) {
+	tokens, err := s.accountSecretCreator.RefreshUserSecret(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, refreshError(err)
+	}
+
+	return loginResponseV2(tokens), nil
+}
+
+func (s *authServerV2) Logout(
+	ctx context.Context,
+	req *todo_protobuf_v2.LogoutRequest,
+) (*todo_protobuf_v2.LogoutResponse, error) {
+	err := s.accountSecretDeleter.DeleteSecret(ctx, []byte(req.GetAccessToken()))
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, "wrong token or revoked")
+	}
+
+	return &todo_protobuf_v2.LogoutResponse{}, nil
+}
+
+func (s *authServerV2) ValidateToken(
+	ctx context.Context,
+	req *todo_protobuf_v2.ValidateTokenRequest,
+) (*todo_protobuf_v2.ValidateTokenResponse, error) {
+	user, err := s.accountSecretValidator.CheckSecret(ctx, []byte(req.GetAccessToken()))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "check secret failed")
+	}
+
+	return &todo_protobuf_v2.ValidateTokenResponse{
+		UserId:   *user.UserID,
+		Username: *user.Username,
+	}, nil
+}
+
+func (s *authServerV2) GetPublicKeys(
+	ctx context.Context,
+	req *todo_protobuf_v2.GetPublicKeysRequest,
+) (*todo_protobuf_v2.GetPublicKeysResponse, error) {
+	keys := s.publicKeysProvider.PublicKeys(ctx)
+
+	response := &todo_protobuf_v2.GetPublicKeysResponse{
+		Keys: make([]*todo_protobuf_v2.JsonWebKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, &todo_protobuf_v2.JsonWebKey{
+			Kty: key.KeyType,
+			Kid: key.KeyID,
+			Use: key.Use,
+			Alg: key.Algorithm,
+			Crv: key.Curve,
+			X:   key.X,
+			N:   key.N,
+			E:   key.E,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *authServerV2) Register(
+	ctx context.Context,
+	req *todo_protobuf_v2.RegisterRequest,
+) (*todo_protobuf_v2.RegisterResponse, error) {
+	user, err := s.accountRegistrar.Register(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, registerError(err)
+	}
+
+	return &todo_protobuf_v2.RegisterResponse{
+		UserId:   *user.UserID,
+		Username: *user.Username,
+	}, nil
+}
+
+func (s *authServerV2) ChangePassword(
+	ctx context.Context,
+	req *todo_protobuf_v2.ChangePasswordRequest,
+) (*todo_protobuf_v2.ChangePasswordResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.accountPasswordService.ChangePassword(ctx, userID, req.GetCurrentPassword(), req.GetNewPassword())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v2.ChangePasswordResponse{}, nil
+}
+
+func (s *authServerV2) RequestPasswordReset(
+	ctx context.Context,
+	req *todo_protobuf_v2.RequestPasswordResetRequest,
+) (*todo_protobuf_v2.RequestPasswordResetResponse, error) {
+	err := s.accountPasswordService.RequestPasswordReset(ctx, req.GetUsername())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v2.RequestPasswordResetResponse{}, nil
+}
+
+func (s *authServerV2) ConfirmPasswordReset(
+	ctx context.Context,
+	req *todo_protobuf_v2.ConfirmPasswordResetRequest,
+) (*todo_protobuf_v2.ConfirmPasswordResetResponse, error) {
+	err := s.accountPasswordService.ConfirmPasswordReset(ctx, req.GetResetToken(), req.GetNewPassword())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v2.ConfirmPasswordResetResponse{}, nil
+}
+
+func (s *authServerV2) ListSessions(
+	ctx context.Context,
+	req *todo_protobuf_v2.ListSessionsRequest,
+) (*todo_protobuf_v2.ListSessionsResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionGetterService.ListSessions(ctx, userID, callerSessionID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	response := &todo_protobuf_v2.ListSessionsResponse{
+		Sessions: make([]*todo_protobuf_v2.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, &todo_protobuf_v2.Session{
+			SessionId:  session.ID,
+			Device:     session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  timeToProto(&session.CreatedAt),
+			LastSeenAt: timeToProto(&session.LastSeenAt),
+			ExpiresAt:  timeToProto(&session.ExpiresAt),
+			Current:    session.Current,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *authServerV2) RevokeSession(
+	ctx context.Context,
+	req *todo_protobuf_v2.RevokeSessionRequest,
+) (*todo_protobuf_v2.RevokeSessionResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessionRevokerService.RevokeSession(ctx, userID, req.GetSessionId())
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &todo_protobuf_v2.RevokeSessionResponse{}, nil
+}
+
+func (s *authServerV2) RevokeOtherSessions(
+	ctx context.Context,
+	req *todo_protobuf_v2.RevokeOtherSessionsRequest,
+) (*todo_protobuf_v2.RevokeOtherSessionsResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := callerSessionID(ctx)
+	if sessionID == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "token has no session, login again")
+	}
+
+	count, err := s.sessionRevokerService.RevokeAllOtherSessions(ctx, userID, sessionID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	return &todo_protobuf_v2.RevokeOtherSessionsResponse{
+		RevokedCount: count,
+	}, nil
+}
+
+func (s *authServerV2) EnrollTotp(
+	ctx context.Context,
+	req *todo_protobuf_v2.EnrollTotpRequest,
+) (*todo_protobuf_v2.EnrollTotpResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.mfaManagerService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v2.EnrollTotpResponse{
+		OtpauthUri: enrollment.URI,
+		Secret:     enrollment.Secret,
+	}, nil
+}
+
+func (s *authServerV2) ConfirmTotp(
+	ctx context.Context,
+	req *todo_protobuf_v2.ConfirmTotpRequest,
+) (*todo_protobuf_v2.ConfirmTotpResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaManagerService.ConfirmTOTP(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v2.ConfirmTotpResponse{}, nil
+}
+
+func (s *authServerV2) DisableTotp(
+	ctx context.Context,
+	req *todo_protobuf_v2.DisableTotpRequest,
+) (*todo_protobuf_v2.DisableTotpResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaManagerService.DisableTOTP(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v2.DisableTotpResponse{}, nil
+}
+
+func (s *authServerV2) GenerateRecoveryCodes(
+	ctx context.Context,
+	req *todo_protobuf_v2.GenerateRecoveryCodesRequest,
+) (*todo_protobuf_v2.GenerateRecoveryCodesResponse, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.mfaManagerService.GenerateRecoveryCodes(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v2.GenerateRecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
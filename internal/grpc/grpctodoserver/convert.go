@@ -0,0 +1,136 @@
+package grpctodoserver
+
+import (
+	"time"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var sortOrders = map[todo_protobuf_v1.TaskSortOrder]serviceDTO.ToDoItemSortOrder{
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_ID_ASC:          serviceDTO.ToDoItemSortByIDAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_ID_DESC:         serviceDTO.ToDoItemSortByIDDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_TITLE_ASC:       serviceDTO.ToDoItemSortByTitleAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_TITLE_DESC:      serviceDTO.ToDoItemSortByTitleDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_DUE_AT_ASC:      serviceDTO.ToDoItemSortByDueAtAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_DUE_AT_DESC:     serviceDTO.ToDoItemSortByDueAtDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_PRIORITY_ASC:    serviceDTO.ToDoItemSortByPriorityAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_PRIORITY_DESC:   serviceDTO.ToDoItemSortByPriorityDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_CREATED_AT_ASC:  serviceDTO.ToDoItemSortByCreatedAtAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_CREATED_AT_DESC: serviceDTO.ToDoItemSortByCreatedAtDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_UPDATED_AT_ASC:  serviceDTO.ToDoItemSortByUpdatedAtAsc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_UPDATED_AT_DESC: serviceDTO.ToDoItemSortByUpdatedAtDesc,
+	todo_protobuf_v1.TaskSortOrder_TASK_SORT_ORDER_POSITION_ASC:    serviceDTO.ToDoItemSortByPositionAsc,
+}
+
+func sortOrderFromProto(order todo_protobuf_v1.TaskSortOrder) serviceDTO.ToDoItemSortOrder {
+	if serviceOrder, ok := sortOrders[order]; ok {
+		return serviceOrder
+	}
+	return serviceDTO.ToDoItemSortByIDAsc
+}
+
+// priorityFromProto returns nil for unspecified priority
+func priorityFromProto(priority todo_protobuf_v1.TaskPriority) *serviceDTO.ToDoItemPriority {
+	var result serviceDTO.ToDoItemPriority
+	switch priority {
+	case todo_protobuf_v1.TaskPriority_TASK_PRIORITY_LOW:
+		result = serviceDTO.ToDoItemPriorityLow
+	case todo_protobuf_v1.TaskPriority_TASK_PRIORITY_NORMAL:
+		result = serviceDTO.ToDoItemPriorityNormal
+	case todo_protobuf_v1.TaskPriority_TASK_PRIORITY_HIGH:
+		result = serviceDTO.ToDoItemPriorityHigh
+	case todo_protobuf_v1.TaskPriority_TASK_PRIORITY_URGENT:
+		result = serviceDTO.ToDoItemPriorityUrgent
+	default:
+		return nil
+	}
+	return &result
+}
+
+func priorityToProto(priority *serviceDTO.ToDoItemPriority) todo_protobuf_v1.TaskPriority {
+	if priority == nil {
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_UNSPECIFIED
+	}
+
+	switch *priority {
+	case serviceDTO.ToDoItemPriorityLow:
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_LOW
+	case serviceDTO.ToDoItemPriorityNormal:
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_NORMAL
+	case serviceDTO.ToDoItemPriorityHigh:
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_HIGH
+	case serviceDTO.ToDoItemPriorityUrgent:
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_URGENT
+	default:
+		return todo_protobuf_v1.TaskPriority_TASK_PRIORITY_UNSPECIFIED
+	}
+}
+
+func timeFromProto(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	result := timestamp.AsTime()
+	return &result
+}
+
+func timeToProto(value *time.Time) *timestamppb.Timestamp {
+	if value == nil || value.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
+
+func taskToProto(item serviceDTO.ToDoItem) *todo_protobuf_v1.GetTaskByIdResponce {
+	return &todo_protobuf_v1.GetTaskByIdResponce{
+		TaskId:       item.ID,
+		Title:        item.GetTitle(),
+		IsDone:       item.GetIsComplete(),
+		DueAt:        timeToProto(item.DueAt),
+		Priority:     priorityToProto(item.Priority),
+		Notes:        item.GetNotes(),
+		CreatedAt:    timeToProto(&item.CreatedAt),
+		UpdatedAt:    timeToProto(&item.UpdatedAt),
+		CompletedAt:  timeToProto(item.CompletedAt),
+		ProjectId:    item.GetProjectID(),
+		OwnerId:      item.OwnerID,
+		ParentId:     item.GetParentID(),
+		Position:     item.Position,
+		DoneCount:    item.DoneCount,
+		TotalCount:   item.TotalCount,
+		AutoComplete: item.GetAutoComplete(),
+		Version:      item.Version,
+	}
+}
+
+func subtaskDeleteModeFromProto(mode todo_protobuf_v1.SubtaskDeleteMode) serviceDTO.SubtaskDeleteMode {
+	if mode == todo_protobuf_v1.SubtaskDeleteMode_SUBTASK_DELETE_MODE_CASCADE {
+		return serviceDTO.SubtaskDeleteCascade
+	}
+	return serviceDTO.SubtaskDeleteReparent
+}
+
+func taskEventTypeToProto(eventType serviceDTO.ToDoItemEventType) todo_protobuf_v1.TaskEventType {
+	switch eventType {
+	case serviceDTO.ToDoItemCreated:
+		return todo_protobuf_v1.TaskEventType_TASK_EVENT_TYPE_CREATED
+	case serviceDTO.ToDoItemUpdated:
+		return todo_protobuf_v1.TaskEventType_TASK_EVENT_TYPE_UPDATED
+	case serviceDTO.ToDoItemDeleted:
+		return todo_protobuf_v1.TaskEventType_TASK_EVENT_TYPE_DELETED
+	default:
+		return todo_protobuf_v1.TaskEventType_TASK_EVENT_TYPE_UNSPECIFIED
+	}
+}
+
+// loginResponce converts issued tokens to proto
+func loginResponce(tokens *serviceDTO.TokenPair) *todo_protobuf_v1.LoginResponce {
+	return &todo_protobuf_v1.LoginResponce{
+		Token:                 tokens.AccessToken,
+		TokenExpiresAt:        timestamppb.New(tokens.AccessExpiresAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshExpiresAt),
+	}
+}
@@ -0,0 +1,244 @@
+package grpctodoserver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// v2 enums keep names and numbers of v1 enums, so v1 converters are reused by number
+
+func priorityFromProtoV2(priority todo_protobuf_v2.TaskPriority) *serviceDTO.ToDoItemPriority {
+	return priorityFromProto(todo_protobuf_v1.TaskPriority(priority))
+}
+
+func priorityToProtoV2(priority *serviceDTO.ToDoItemPriority) todo_protobuf_v2.TaskPriority {
+	return todo_protobuf_v2.TaskPriority(priorityToProto(priority))
+}
+
+func sortOrderFromProtoV2(order todo_protobuf_v2.TaskSortOrder) serviceDTO.ToDoItemSortOrder {
+	return sortOrderFromProto(todo_protobuf_v1.TaskSortOrder(order))
+}
+
+func subtaskDeleteModeFromProtoV2(mode todo_protobuf_v2.SubtaskDeleteMode) serviceDTO.SubtaskDeleteMode {
+	return subtaskDeleteModeFromProto(todo_protobuf_v1.SubtaskDeleteMode(mode))
+}
+
+func taskEventTypeToProtoV2(eventType serviceDTO.ToDoItemEventType) todo_protobuf_v2.TaskEventType {
+	return todo_protobuf_v2.TaskEventType(taskEventTypeToProto(eventType))
+}
+
+func projectDeleteModeFromProtoV2(mode todo_protobuf_v2.ProjectDeleteMode) (serviceDTO.ProjectDeleteMode, bool) {
+	return projectDeleteModeFromProto(todo_protobuf_v1.ProjectDeleteMode(mode))
+}
+
+func shareResourceFromProtoV2(resource *todo_protobuf_v2.ShareResource) serviceDTO.ShareResource {
+	return shareResourceFromProto(&todo_protobuf_v1.ShareResource{
+		Type: todo_protobuf_v1.ShareResourceType(resource.GetType()),
+		Id:   resource.GetId(),
+	})
+}
+
+func shareRoleFromProtoV2(role todo_protobuf_v2.ShareRole) serviceDTO.ShareRole {
+	return shareRoleFromProto(todo_protobuf_v1.ShareRole(role))
+}
+
+func taskToProtoV2(item serviceDTO.ToDoItem) *todo_protobuf_v2.Task {
+	return &todo_protobuf_v2.Task{
+		TaskId:       item.ID,
+		Title:        item.GetTitle(),
+		IsDone:       item.GetIsComplete(),
+		DueAt:        timeToProto(item.DueAt),
+		Priority:     priorityToProtoV2(item.Priority),
+		Notes:        item.GetNotes(),
+		CreatedAt:    timeToProto(&item.CreatedAt),
+		UpdatedAt:    timeToProto(&item.UpdatedAt),
+		CompletedAt:  timeToProto(item.CompletedAt),
+		ProjectId:    item.GetProjectID(),
+		OwnerId:      item.OwnerID,
+		ParentId:     item.GetParentID(),
+		Position:     item.Position,
+		DoneCount:    item.DoneCount,
+		TotalCount:   item.TotalCount,
+		AutoComplete: item.GetAutoComplete(),
+		Version:      item.Version,
+		DeletedAt:    timeToProto(item.DeletedAt),
+		Recurrence:   recurrenceToProtoV2(item.Recurrence),
+	}
+}
+
+func recurrenceToProtoV2(recurrence *serviceDTO.ToDoItemRecurrence) *todo_protobuf_v2.TaskRecurrence {
+	if recurrence == nil {
+		return nil
+	}
+	return &todo_protobuf_v2.TaskRecurrence{
+		Rule:     recurrence.Rule,
+		TimeZone: recurrence.TimeZone,
+	}
+}
+
+func recurrenceFromProtoV2(recurrence *todo_protobuf_v2.TaskRecurrence) *serviceDTO.ToDoItemRecurrence {
+	return &serviceDTO.ToDoItemRecurrence{
+		Rule:     recurrence.GetRule(),
+		TimeZone: recurrence.GetTimeZone(),
+	}
+}
+
+func taskPageToProtoV2(page *serviceDTO.ToDoItemPage) *todo_protobuf_v2.ListTasksResponse {
+	tasks := make([]*todo_protobuf_v2.Task, 0, len(page.Items))
+	for _, item := range page.Items {
+		tasks = append(tasks, taskToProtoV2(item))
+	}
+	return &todo_protobuf_v2.ListTasksResponse{
+		Tasks:         tasks,
+		NextPageToken: page.NextPageToken,
+	}
+}
+
+func projectToProtoV2(project serviceDTO.Project) *todo_protobuf_v2.Project {
+	return &todo_protobuf_v2.Project{
+		ProjectId:  project.ID,
+		OwnerId:    project.OwnerID,
+		Name:       project.GetName(),
+		IsArchived: project.GetIsArchived(),
+		CreatedAt:  timeToProto(&project.CreatedAt),
+		UpdatedAt:  timeToProto(&project.UpdatedAt),
+	}
+}
+
+func memberToProtoV2(member serviceDTO.ShareMember) *todo_protobuf_v2.Member {
+	return &todo_protobuf_v2.Member{
+		UserId:    member.UserID,
+		Username:  member.GetUsername(),
+		Role:      todo_protobuf_v2.ShareRole(shareRoleToProto(member.Role)),
+		CreatedAt: timeToProto(&member.CreatedAt),
+	}
+}
+
+// loginResponseV2 converts issued tokens to proto
+func loginResponseV2(tokens *serviceDTO.TokenPair) *todo_protobuf_v2.LoginResponse {
+	return &todo_protobuf_v2.LoginResponse{
+		Result: &todo_protobuf_v2.LoginResponse_Tokens{
+			Tokens: &todo_protobuf_v2.TokenPair{
+				AccessToken:           tokens.AccessToken,
+				AccessTokenExpiresAt:  timestamppb.New(tokens.AccessExpiresAt),
+				RefreshToken:          tokens.RefreshToken,
+				RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshExpiresAt),
+			},
+		},
+	}
+}
+
+// taskCreateFromProtoV2 returns new item from task, output only fields are ignored
+func taskCreateFromProtoV2(task *todo_protobuf_v2.Task) (serviceDTO.ToDoItem, error) {
+	title := task.GetTitle()
+	notes := task.GetNotes()
+	autoComplete := task.GetAutoComplete()
+	item := serviceDTO.ToDoItem{
+		Title:        &title,
+		AutoComplete: &autoComplete,
+		Notes:        &notes,
+		Priority:     priorityFromProtoV2(task.GetPriority()),
+		DueAt:        timeFromProto(task.GetDueAt()),
+	}
+	if task.GetRecurrence().GetRule() != "" {
+		item.Recurrence = recurrenceFromProtoV2(task.GetRecurrence())
+	}
+	if parentID := task.GetParentId(); parentID != 0 {
+		if task.GetProjectId() != 0 {
+			return item, errors.New("subtask can not belong to project")
+		}
+		item.ParentID = &parentID
+	} else {
+		projectID := task.GetProjectId()
+		item.ProjectID = &projectID
+	}
+
+	return item, nil
+}
+
+// taskMutationFromProtoV2 converts batch mutation, complete mutation becomes update of is_done
+func taskMutationFromProtoV2(mutation *todo_protobuf_v2.TaskMutation) (serviceDTO.ToDoItemMutation, error) {
+	switch m := mutation.GetMutation().(type) {
+	case *todo_protobuf_v2.TaskMutation_Create:
+		item, err := taskCreateFromProtoV2(m.Create.GetTask())
+		return serviceDTO.ToDoItemMutation{Type: serviceDTO.ToDoItemMutationCreate, Item: item}, err
+	case *todo_protobuf_v2.TaskMutation_Update:
+		item, err := taskUpdateFromMask(m.Update.GetTask(), m.Update.GetUpdateMask())
+		return serviceDTO.ToDoItemMutation{Type: serviceDTO.ToDoItemMutationUpdate, Item: item}, err
+	case *todo_protobuf_v2.TaskMutation_Delete:
+		return serviceDTO.ToDoItemMutation{
+			Type:       serviceDTO.ToDoItemMutationDelete,
+			Item:       serviceDTO.ToDoItem{ID: m.Delete.GetTaskId()},
+			DeleteMode: subtaskDeleteModeFromProtoV2(m.Delete.GetSubtasks()),
+		}, nil
+	case *todo_protobuf_v2.TaskMutation_Complete:
+		isDone := !m.Complete.GetUndo()
+		return serviceDTO.ToDoItemMutation{
+			Type: serviceDTO.ToDoItemMutationUpdate,
+			Item: serviceDTO.ToDoItem{
+				ID:         m.Complete.GetTaskId(),
+				IsComplete: &isDone,
+				Version:    m.Complete.GetVersion(),
+			},
+		}, nil
+	default:
+		return serviceDTO.ToDoItemMutation{}, errors.New("mutation is not set")
+	}
+}
+
+// taskUpdateFromMask returns update of task fields listed in mask.
+// Fields not in mask stay nil and keep their stored values, listed fields without value are cleared
+func taskUpdateFromMask(task *todo_protobuf_v2.Task, mask *fieldmaskpb.FieldMask) (serviceDTO.ToDoItem, error) {
+	item := serviceDTO.ToDoItem{
+		ID:      task.GetTaskId(),
+		Version: task.GetVersion(),
+	}
+
+	if len(mask.GetPaths()) == 0 {
+		return item, fmt.Errorf("%w: update_mask is empty", errInvalidMask)
+	}
+
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "title":
+			title := task.GetTitle()
+			item.Title = &title
+		case "is_done":
+			isDone := task.GetIsDone()
+			item.IsComplete = &isDone
+		case "due_at":
+			item.DueAt = &time.Time{}
+			if task.GetDueAt() != nil {
+				item.DueAt = timeFromProto(task.GetDueAt())
+			}
+		case "priority":
+			item.Priority = priorityFromProtoV2(task.GetPriority())
+			if item.Priority == nil {
+				normal := serviceDTO.ToDoItemPriorityNormal
+				item.Priority = &normal
+			}
+		case "notes":
+			notes := task.GetNotes()
+			item.Notes = &notes
+		case "project_id":
+			projectID := task.GetProjectId()
+			item.ProjectID = &projectID
+		case "auto_complete":
+			autoComplete := task.GetAutoComplete()
+			item.AutoComplete = &autoComplete
+		case "recurrence":
+			item.Recurrence = recurrenceFromProtoV2(task.GetRecurrence())
+		default:
+			return item, fmt.Errorf("%w: unknown field %q", errInvalidMask, path)
+		}
+	}
+
+	return item, nil
+}
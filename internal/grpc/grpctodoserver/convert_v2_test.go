@@ -0,0 +1,128 @@
+package grpctodoserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTaskUpdateFromMask(t *testing.T) {
+	dueAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	task := &todo_protobuf_v2.Task{
+		TaskId:   7,
+		Title:    "title",
+		DueAt:    timestamppb.New(dueAt),
+		Priority: todo_protobuf_v2.TaskPriority_TASK_PRIORITY_HIGH,
+	}
+
+	item, err := taskUpdateFromMask(task, &fieldmaskpb.FieldMask{Paths: []string{"title", "is_done", "due_at", "priority"}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), item.ID)
+	require.Equal(t, "title", *item.Title)
+	require.False(t, *item.IsComplete)
+	require.Equal(t, dueAt, *item.DueAt)
+	require.Equal(t, serviceDTO.ToDoItemPriorityHigh, *item.Priority)
+	require.Nil(t, item.Notes)
+	require.Nil(t, item.ProjectID)
+	require.Nil(t, item.AutoComplete)
+
+	item, err = taskUpdateFromMask(&todo_protobuf_v2.Task{TaskId: 7, Version: 3}, &fieldmaskpb.FieldMask{Paths: []string{"due_at", "priority"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), item.Version)
+	require.True(t, item.DueAt.IsZero())
+	require.Equal(t, serviceDTO.ToDoItemPriorityNormal, *item.Priority)
+	require.Nil(t, item.Recurrence)
+
+	item, err = taskUpdateFromMask(&todo_protobuf_v2.Task{TaskId: 7}, &fieldmaskpb.FieldMask{Paths: []string{"recurrence"}})
+	require.NoError(t, err)
+	require.Equal(t, &serviceDTO.ToDoItemRecurrence{}, item.Recurrence)
+
+	testCases := []struct {
+		name  string
+		task  *todo_protobuf_v2.Task
+		paths []string
+	}{
+		{name: "empty mask", task: task},
+		{name: "unknown field", task: task, paths: []string{"owner_id"}},
+		{name: "output only field", task: task, paths: []string{"version"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := taskUpdateFromMask(testCase.task, &fieldmaskpb.FieldMask{Paths: testCase.paths})
+			require.True(t, errors.Is(err, errInvalidMask))
+		})
+	}
+}
+
+func TestTaskMutationFromProtoV2(t *testing.T) {
+	mutation, err := taskMutationFromProtoV2(&todo_protobuf_v2.TaskMutation{
+		Mutation: &todo_protobuf_v2.TaskMutation_Complete{
+			Complete: &todo_protobuf_v2.CompleteTaskMutation{TaskId: 7, Version: 3},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, serviceDTO.ToDoItemMutationUpdate, mutation.Type)
+	require.Equal(t, uint64(7), mutation.Item.ID)
+	require.Equal(t, int64(3), mutation.Item.Version)
+	require.True(t, *mutation.Item.IsComplete)
+	require.Nil(t, mutation.Item.Title)
+
+	mutation, err = taskMutationFromProtoV2(&todo_protobuf_v2.TaskMutation{
+		Mutation: &todo_protobuf_v2.TaskMutation_Delete{
+			Delete: &todo_protobuf_v2.DeleteTaskRequest{
+				TaskId:   7,
+				Subtasks: todo_protobuf_v2.SubtaskDeleteMode_SUBTASK_DELETE_MODE_CASCADE,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, serviceDTO.ToDoItemMutationDelete, mutation.Type)
+	require.Equal(t, serviceDTO.SubtaskDeleteCascade, mutation.DeleteMode)
+
+	_, err = taskMutationFromProtoV2(&todo_protobuf_v2.TaskMutation{
+		Mutation: &todo_protobuf_v2.TaskMutation_Update{
+			Update: &todo_protobuf_v2.UpdateTaskRequest{Task: &todo_protobuf_v2.Task{TaskId: 7}},
+		},
+	})
+	require.ErrorIs(t, err, errInvalidMask)
+
+	_, err = taskMutationFromProtoV2(&todo_protobuf_v2.TaskMutation{})
+	require.Error(t, err)
+}
+
+// v2 converters reuse v1 ones by number, so enum values must match
+func TestEnumsMatchV1(t *testing.T) {
+	enums := []struct {
+		v1 protoreflect.EnumDescriptor
+		v2 protoreflect.EnumDescriptor
+	}{
+		{todo_protobuf_v1.TaskPriority(0).Descriptor(), todo_protobuf_v2.TaskPriority(0).Descriptor()},
+		{todo_protobuf_v1.TaskSortOrder(0).Descriptor(), todo_protobuf_v2.TaskSortOrder(0).Descriptor()},
+		{todo_protobuf_v1.SubtaskDeleteMode(0).Descriptor(), todo_protobuf_v2.SubtaskDeleteMode(0).Descriptor()},
+		{todo_protobuf_v1.TaskEventType(0).Descriptor(), todo_protobuf_v2.TaskEventType(0).Descriptor()},
+		{todo_protobuf_v1.ProjectDeleteMode(0).Descriptor(), todo_protobuf_v2.ProjectDeleteMode(0).Descriptor()},
+		{todo_protobuf_v1.ShareResourceType(0).Descriptor(), todo_protobuf_v2.ShareResourceType(0).Descriptor()},
+		{todo_protobuf_v1.ShareRole(0).Descriptor(), todo_protobuf_v2.ShareRole(0).Descriptor()},
+	}
+
+	for _, enum := range enums {
+		t.Run(string(enum.v2.Name()), func(t *testing.T) {
+			require.Equal(t, enum.v1.Values().Len(), enum.v2.Values().Len())
+			for i := 0; i < enum.v1.Values().Len(); i++ {
+				v1Value := enum.v1.Values().Get(i)
+				v2Value := enum.v2.Values().ByNumber(v1Value.Number())
+				require.NotNil(t, v2Value, v1Value.Name())
+				require.Equal(t, v1Value.Name(), v2Value.Name())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+
+	authService "github.com/IldarGaleev/todo-backend-service/internal/services/auth"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mfaError maps MFA service errors to gRPC status
+func mfaError(err error) error {
+	var lockedErr *authService.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		return lockedStatus(lockedErr.RetryAfter)
+	case errors.Is(err, authService.ErrArguments):
+		return status.Error(codes.InvalidArgument, "mfa_challenge and code are required")
+	case errors.Is(err, authService.ErrWrongSecret):
+		return status.Error(codes.Unauthenticated, "MFA challenge expired or unknown, login again")
+	case errors.Is(err, authService.ErrWrongMfaCode):
+		return status.Error(codes.PermissionDenied, "wrong code")
+	case errors.Is(err, authService.ErrMfaEnabled):
+		return status.Error(codes.AlreadyExists, "MFA is already enabled")
+	case errors.Is(err, authService.ErrMfaNotEnabled):
+		return status.Error(codes.FailedPrecondition, "MFA is not enabled")
+	case errors.Is(err, authService.ErrMfaUnavailable):
+		return status.Error(codes.FailedPrecondition, "MFA is not configured on server")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+func (s *serverAPI) VerifyMfa(
+	ctx context.Context,
+	req *todo_protobuf_v1.VerifyMfaRequest,
+) (*todo_protobuf_v1.LoginResponce, error) {
+	tokens, err := s.mfaVerifierService.VerifyMfa(
+		ctx,
+		req.GetMfaChallenge(),
+		req.GetCode(),
+		clientFromContext(ctx),
+	)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return loginResponce(tokens), nil
+}
+
+func (s *serverAPI) EnrollTotp(
+	ctx context.Context,
+	req *todo_protobuf_v1.EnrollTotpRequest,
+) (*todo_protobuf_v1.EnrollTotpResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.mfaManagerService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v1.EnrollTotpResponce{
+		OtpauthUri: enrollment.URI,
+		Secret:     enrollment.Secret,
+	}, nil
+}
+
+func (s *serverAPI) ConfirmTotp(
+	ctx context.Context,
+	req *todo_protobuf_v1.ConfirmTotpRequest,
+) (*todo_protobuf_v1.ConfirmTotpResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaManagerService.ConfirmTOTP(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v1.ConfirmTotpResponce{}, nil
+}
+
+func (s *serverAPI) DisableTotp(
+	ctx context.Context,
+	req *todo_protobuf_v1.DisableTotpRequest,
+) (*todo_protobuf_v1.DisableTotpResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaManagerService.DisableTOTP(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v1.DisableTotpResponce{}, nil
+}
+
+func (s *serverAPI) GenerateRecoveryCodes(
+	ctx context.Context,
+	req *todo_protobuf_v1.GenerateRecoveryCodesRequest,
+) (*todo_protobuf_v1.GenerateRecoveryCodesResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.mfaManagerService.GenerateRecoveryCodes(ctx, userID, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &todo_protobuf_v1.GenerateRecoveryCodesResponce{
+		RecoveryCodes: codes,
+	}, nil
+}
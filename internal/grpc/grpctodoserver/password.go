@@ -0,0 +1,71 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	accountService "github.com/IldarGaleev/todo-backend-service/internal/services/accountservice"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// passwordError maps password change errors to gRPC status
+func passwordError(err error) error {
+	switch {
+	case errors.Is(err, accountService.ErrWeakPassword):
+		return status.Error(codes.InvalidArgument, strings.ReplaceAll(err.Error(), "\n", "; "))
+	case errors.Is(err, accountService.ErrInvalidUsername):
+		return status.Error(codes.InvalidArgument, "invalid username")
+	case errors.Is(err, accountService.ErrWrongPassword):
+		return status.Error(codes.PermissionDenied, "wrong password")
+	case errors.Is(err, accountService.ErrInvalidResetToken):
+		return status.Error(codes.PermissionDenied, "reset token is invalid, used or expired")
+	case errors.Is(err, accountService.ErrNotFound):
+		return status.Error(codes.NotFound, "account not found")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+func (s *serverAPI) ChangePassword(
+	ctx context.Context,
+	req *todo_protobuf_v1.ChangePasswordRequest,
+) (*todo_protobuf_v1.ChangePasswordResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.accountPasswordService.ChangePassword(ctx, userID, req.GetCurrentPassword(), req.GetNewPassword())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v1.ChangePasswordResponce{}, nil
+}
+
+func (s *serverAPI) RequestPasswordReset(
+	ctx context.Context,
+	req *todo_protobuf_v1.RequestPasswordResetRequest,
+) (*todo_protobuf_v1.RequestPasswordResetResponce, error) {
+	err := s.accountPasswordService.RequestPasswordReset(ctx, req.GetEmail())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v1.RequestPasswordResetResponce{}, nil
+}
+
+func (s *serverAPI) ConfirmPasswordReset(
+	ctx context.Context,
+	req *todo_protobuf_v1.ConfirmPasswordResetRequest,
+) (*todo_protobuf_v1.ConfirmPasswordResetResponce, error) {
+	err := s.accountPasswordService.ConfirmPasswordReset(ctx, req.GetResetToken(), req.GetNewPassword())
+	if err != nil {
+		return nil, passwordError(err)
+	}
+
+	return &todo_protobuf_v1.ConfirmPasswordResetResponce{}, nil
+}
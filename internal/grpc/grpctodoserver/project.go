@@ -0,0 +1,160 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+
+	projectService "github.com/IldarGaleev/todo-backend-service/internal/services/projectservice"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// projectError maps project service errors to gRPC status
+func projectError(err error) error {
+	switch {
+	case errors.Is(err, projectService.ErrProjectNotFound):
+		return status.Error(codes.NotFound, "Project not found")
+	case errors.Is(err, projectService.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "Access denied")
+	case errors.Is(err, projectService.ErrInvalidName):
+		return status.Error(codes.InvalidArgument, "project name must be 1 to 255 characters")
+	case errors.Is(err, projectService.ErrInvalidReassign):
+		return status.Error(codes.InvalidArgument, "invalid reassign_to_project_id")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+func projectDeleteModeFromProto(mode todo_protobuf_v1.ProjectDeleteMode) (serviceDTO.ProjectDeleteMode, bool) {
+	switch mode {
+	case todo_protobuf_v1.ProjectDeleteMode_PROJECT_DELETE_MODE_UNASSIGN:
+		return serviceDTO.ProjectDeleteUnassign, true
+	case todo_protobuf_v1.ProjectDeleteMode_PROJECT_DELETE_MODE_REASSIGN:
+		return serviceDTO.ProjectDeleteReassign, true
+	case todo_protobuf_v1.ProjectDeleteMode_PROJECT_DELETE_MODE_CASCADE:
+		return serviceDTO.ProjectDeleteCascade, true
+	default:
+		return 0, false
+	}
+}
+
+func projectToProto(project serviceDTO.Project) *todo_protobuf_v1.GetProjectResponce {
+	return &todo_protobuf_v1.GetProjectResponce{
+		ProjectId:  project.ID,
+		OwnerId:    project.OwnerID,
+		Name:       project.GetName(),
+		IsArchived: project.GetIsArchived(),
+		CreatedAt:  timeToProto(&project.CreatedAt),
+		UpdatedAt:  timeToProto(&project.UpdatedAt),
+	}
+}
+
+func (s *serverAPI) CreateProject(
+	ctx context.Context,
+	req *todo_protobuf_v1.CreateProjectRequest,
+) (*todo_protobuf_v1.CreateProjectResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.projectsCreatorService.Create(ctx, req.GetName(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v1.CreateProjectResponce{
+		ProjectId: id,
+	}, nil
+}
+
+func (s *serverAPI) ListProjects(
+	ctx context.Context,
+	req *todo_protobuf_v1.ListProjectsRequest,
+) (*todo_protobuf_v1.ListProjectsResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectsGetterService.GetList(ctx, ownerID, req.GetIncludeArchived(), req.GetIncludeShared())
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	responseProjects := make([]*todo_protobuf_v1.GetProjectResponce, 0, len(projects))
+	for _, project := range projects {
+		responseProjects = append(responseProjects, projectToProto(project))
+	}
+
+	return &todo_protobuf_v1.ListProjectsResponce{
+		Projects: responseProjects,
+	}, nil
+}
+
+func (s *serverAPI) RenameProject(
+	ctx context.Context,
+	req *todo_protobuf_v1.RenameProjectRequest,
+) (*todo_protobuf_v1.ChangedProjectResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.projectsUpdaterService.Rename(ctx, req.GetProjectId(), req.GetName(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedProjectResponce{
+		ProjectId: req.GetProjectId(),
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *serverAPI) ArchiveProject(
+	ctx context.Context,
+	req *todo_protobuf_v1.ArchiveProjectRequest,
+) (*todo_protobuf_v1.ChangedProjectResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.projectsUpdaterService.Archive(ctx, req.GetProjectId(), !req.GetUnarchive(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedProjectResponce{
+		ProjectId: req.GetProjectId(),
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *serverAPI) DeleteProject(
+	ctx context.Context,
+	req *todo_protobuf_v1.DeleteProjectRequest,
+) (*todo_protobuf_v1.ChangedProjectResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, ok := projectDeleteModeFromProto(req.GetMode())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown delete mode")
+	}
+
+	err = s.projectsDeleterService.DeleteByID(ctx, req.GetProjectId(), ownerID, mode, req.GetReassignToProjectId())
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedProjectResponce{
+		ProjectId: req.GetProjectId(),
+		IsSuccess: true,
+	}, nil
+}
@@ -1,37 +1,121 @@
-// TODO: split to auth and todo items grpc....
-
 // Package grpctodoserver implements gRPC handlers
 package grpctodoserver
 
 import (
 	"context"
-
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/eventhub"
+	accountService "github.com/IldarGaleev/todo-backend-service/internal/services/accountservice"
+	authService "github.com/IldarGaleev/todo-backend-service/internal/services/auth"
 	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todoService "github.com/IldarGaleev/todo-backend-service/internal/services/todoservice"
 	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type IToDoItemCreatorService interface {
-	Create(ctx context.Context, title string, ownerID uint64) (uint64, error)
+	Create(ctx context.Context, item serviceDTO.ToDoItem, ownerID uint64) (uint64, error)
 }
 
 type IToDoItemGetterService interface {
 	GetByID(ctx context.Context, itemID uint64, ownerID uint64) (*serviceDTO.ToDoItem, error)
-	GetList(ctx context.Context, ownerID uint64) ([]serviceDTO.ToDoItem, error)
+	GetList(ctx context.Context, ownerID uint64, query serviceDTO.ToDoItemListQuery) (*serviceDTO.ToDoItemPage, error)
+	ListDeleted(ctx context.Context, ownerID uint64) ([]serviceDTO.ToDoItem, error)
+	Occurrences(ctx context.Context, query serviceDTO.ToDoItemOccurrencesQuery, ownerID uint64) ([]time.Time, error)
 }
 
 type IToDoItemDeleterService interface {
-	DeleteByID(ctx context.Context, itemID uint64, ownerID uint64) error
+	DeleteByID(ctx context.Context, itemID uint64, ownerID uint64, mode serviceDTO.SubtaskDeleteMode) error
+	Purge(ctx context.Context, itemID uint64, ownerID uint64) error
 }
 
 type IToDoItemUpdaterService interface {
 	Update(ctx context.Context, item serviceDTO.ToDoItem, ownerID uint64) error
+	Reorder(ctx context.Context, parentID uint64, childIDs []uint64, ownerID uint64) error
+	Restore(ctx context.Context, itemID uint64, ownerID uint64) error
+}
+
+type IToDoItemBatchService interface {
+	Batch(
+		ctx context.Context,
+		mutations []serviceDTO.ToDoItemMutation,
+		ownerID uint64,
+		atomic bool,
+	) ([]serviceDTO.ToDoItemMutationResult, error)
+}
+
+type IToDoItemWatcherService interface {
+	Watch(
+		ctx context.Context,
+		ownerID uint64,
+		resumeToken string,
+	) (*eventhub.Subscription[serviceDTO.ToDoItemEvent], error)
+}
+
+type IProjectCreatorService interface {
+	Create(ctx context.Context, name string, ownerID uint64) (uint64, error)
+}
+
+type IProjectGetterService interface {
+	GetList(ctx context.Context, ownerID uint64, includeArchived bool, includeShared bool) ([]serviceDTO.Project, error)
+}
+
+type IProjectUpdaterService interface {
+	Rename(ctx context.Context, projectID uint64, name string, ownerID uint64) error
+	Archive(ctx context.Context, projectID uint64, archived bool, ownerID uint64) error
+}
+
+type IProjectDeleterService interface {
+	DeleteByID(
+		ctx context.Context,
+		projectID uint64,
+		ownerID uint64,
+		mode serviceDTO.ProjectDeleteMode,
+		reassignTo uint64,
+	) error
+}
+
+type IShareMemberCreatorService interface {
+	Invite(
+		ctx context.Context,
+		resource serviceDTO.ShareResource,
+		username string,
+		role serviceDTO.ShareRole,
+		callerID uint64,
+	) (*serviceDTO.ShareMember, error)
+}
+
+type IShareMemberGetterService interface {
+	ListMembers(ctx context.Context, resource serviceDTO.ShareResource, callerID uint64) (*serviceDTO.ShareMemberList, error)
+}
+
+type IShareMemberUpdaterService interface {
+	ChangeRole(
+		ctx context.Context,
+		resource serviceDTO.ShareResource,
+		userID uint64,
+		role serviceDTO.ShareRole,
+		callerID uint64,
+	) error
+}
+
+type IShareMemberDeleterService interface {
+	Revoke(ctx context.Context, resource serviceDTO.ShareResource, userID uint64, callerID uint64) error
 }
 
 type IAccountSecretCreator interface {
-	CreateUserSecret(ctx context.Context, user serviceDTO.User) (string, error)
+	CreateUserSecret(ctx context.Context, user serviceDTO.User, client serviceDTO.Client) (*serviceDTO.LoginResult, error)
+	RefreshUserSecret(ctx context.Context, refreshToken string) (*serviceDTO.TokenPair, error)
 }
 
 type IAccountSecretValidator interface {
@@ -43,39 +127,263 @@ type IAccountSecretValidator interface {
 type IAccountSecretDeleter interface {
 	DeleteSecret(ctx context.Context, secret []byte) error
 }
-type serverAPI struct {
-	todo_protobuf_v1.UnimplementedToDoServiceServer
+type IAccountRegistrar interface {
+	Register(ctx context.Context, username string, password string) (*serviceDTO.User, error)
+}
+
+type IAccountPasswordService interface {
+	ChangePassword(ctx context.Context, userID uint64, currentPassword string, newPassword string) error
+	RequestPasswordReset(ctx context.Context, username string) error
+	ConfirmPasswordReset(ctx context.Context, token string, newPassword string) error
+}
+
+type IPublicKeysProvider interface {
+	PublicKeys(ctx context.Context) []serviceDTO.PublicKey
+}
+
+type ISessionGetterService interface {
+	ListSessions(ctx context.Context, userID uint64, currentSessionID uint64) ([]serviceDTO.Session, error)
+}
+
+type ISessionRevokerService interface {
+	RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error
+	RevokeAllOtherSessions(ctx context.Context, userID uint64, currentSessionID uint64) (int64, error)
+}
+
+type IMfaVerifierService interface {
+	VerifyMfa(ctx context.Context, challenge string, code string, client serviceDTO.Client) (*serviceDTO.TokenPair, error)
+}
+
+type IMfaManagerService interface {
+	EnrollTOTP(ctx context.Context, userID uint64) (*serviceDTO.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uint64, code string) error
+	DisableTOTP(ctx context.Context, userID uint64, code string) error
+	GenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error)
+}
+
+// services used by handlers of every API version
+type services struct {
 	todoItemsCreatorService IToDoItemCreatorService
 	todoItemsUpdaterService IToDoItemUpdaterService
 	todoItemsGetterService  IToDoItemGetterService
 	todoItemsDeleterService IToDoItemDeleterService
+	todoItemsWatcherService IToDoItemWatcherService
+	todoItemsBatchService   IToDoItemBatchService
+	projectsCreatorService  IProjectCreatorService
+	projectsUpdaterService  IProjectUpdaterService
+	projectsGetterService   IProjectGetterService
+	projectsDeleterService  IProjectDeleterService
+	membersCreatorService   IShareMemberCreatorService
+	membersUpdaterService   IShareMemberUpdaterService
+	membersGetterService    IShareMemberGetterService
+	membersDeleterService   IShareMemberDeleterService
 	accountSecretCreator    IAccountSecretCreator
 	accountSecretValidator  IAccountSecretValidator
 	accountSecretDeleter    IAccountSecretDeleter
+	accountRegistrar        IAccountRegistrar
+	publicKeysProvider      IPublicKeysProvider
+	sessionGetterService    ISessionGetterService
+	sessionRevokerService   ISessionRevokerService
+	mfaVerifierService      IMfaVerifierService
+	mfaManagerService       IMfaManagerService
+	accountPasswordService  IAccountPasswordService
 }
 
-func Register(
-	gRPC *grpc.Server,
+// serverAPI implements v1 ToDoService
+type serverAPI struct {
+	todo_protobuf_v1.UnimplementedToDoServiceServer
+	services
+}
+
+// Servers implementations of v1 and v2 services sharing the service layer
+type Servers struct {
+	ToDoV1 todo_protobuf_v1.ToDoServiceServer
+	AuthV2 todo_protobuf_v2.AuthServiceServer
+	TaskV2 todo_protobuf_v2.TaskServiceServer
+}
+
+// Register registers every service of servers on gRPC server
+func Register(gRPC *grpc.Server, servers *Servers) {
+	todo_protobuf_v1.RegisterToDoServiceServer(gRPC, servers.ToDoV1)
+	todo_protobuf_v2.RegisterAuthServiceServer(gRPC, servers.AuthV2)
+	todo_protobuf_v2.RegisterTaskServiceServer(gRPC, servers.TaskV2)
+}
+
+// New creates v1 and v2 services
+func New(
 	todoItemsCreatorService IToDoItemCreatorService,
 	todoItemsUpdaterService IToDoItemUpdaterService,
 	todoItemsGetterService IToDoItemGetterService,
 	todoItemsDeleterService IToDoItemDeleterService,
+	todoItemsWatcherService IToDoItemWatcherService,
+	todoItemsBatchService IToDoItemBatchService,
+	projectsCreatorService IProjectCreatorService,
+	projectsUpdaterService IProjectUpdaterService,
+	projectsGetterService IProjectGetterService,
+	projectsDeleterService IProjectDeleterService,
+	membersCreatorService IShareMemberCreatorService,
+	membersUpdaterService IShareMemberUpdaterService,
+	membersGetterService IShareMemberGetterService,
+	membersDeleterService IShareMemberDeleterService,
 	accountSecretCreator IAccountSecretCreator,
 	accountSecretValidator IAccountSecretValidator,
 	accountSecretDeleter IAccountSecretDeleter,
-) {
-	todo_protobuf_v1.RegisterToDoServiceServer(
-		gRPC,
-		&serverAPI{
-			todoItemsCreatorService: todoItemsCreatorService,
-			todoItemsUpdaterService: todoItemsUpdaterService,
-			todoItemsGetterService:  todoItemsGetterService,
-			todoItemsDeleterService: todoItemsDeleterService,
-			accountSecretCreator:    accountSecretCreator,
-			accountSecretValidator:  accountSecretValidator,
-			accountSecretDeleter:    accountSecretDeleter,
-		},
-	)
+	accountRegistrar IAccountRegistrar,
+	publicKeysProvider IPublicKeysProvider,
+	sessionGetterService ISessionGetterService,
+	sessionRevokerService ISessionRevokerService,
+	mfaVerifierService IMfaVerifierService,
+	mfaManagerService IMfaManagerService,
+	accountPasswordService IAccountPasswordService,
+) *Servers {
+	svc := services{
+		todoItemsCreatorService: todoItemsCreatorService,
+		todoItemsUpdaterService: todoItemsUpdaterService,
+		todoItemsGetterService:  todoItemsGetterService,
+		todoItemsDeleterService: todoItemsDeleterService,
+		todoItemsWatcherService: todoItemsWatcherService,
+		todoItemsBatchService:   todoItemsBatchService,
+		projectsCreatorService:  projectsCreatorService,
+		projectsUpdaterService:  projectsUpdaterService,
+		projectsGetterService:   projectsGetterService,
+		projectsDeleterService:  projectsDeleterService,
+		membersCreatorService:   membersCreatorService,
+		membersUpdaterService:   membersUpdaterService,
+		membersGetterService:    membersGetterService,
+		membersDeleterService:   membersDeleterService,
+		accountSecretCreator:    accountSecretCreator,
+		accountSecretValidator:  accountSecretValidator,
+		accountSecretDeleter:    accountSecretDeleter,
+		accountRegistrar:        accountRegistrar,
+		publicKeysProvider:      publicKeysProvider,
+		sessionGetterService:    sessionGetterService,
+		sessionRevokerService:   sessionRevokerService,
+		mfaVerifierService:      mfaVerifierService,
+		mfaManagerService:       mfaManagerService,
+		accountPasswordService:  accountPasswordService,
+	}
+
+	return &Servers{
+		ToDoV1: &serverAPI{services: svc},
+		AuthV2: &authServerV2{services: svc},
+		TaskV2: &taskServerV2{services: svc},
+	}
+}
+
+// callerID returns authenticated caller id.
+// Deprecated request user_id is accepted only if it matches the caller
+func callerID(ctx context.Context, requestUserID uint64) (uint64, error) {
+	user, ok := authcontext.UserFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if requestUserID != 0 && requestUserID != *user.UserID {
+		return 0, status.Error(codes.PermissionDenied, "user_id does not match authenticated user")
+	}
+
+	return *user.UserID, nil
+}
+
+// lockedStatus returns ResourceExhausted status with retry delay rounded up to seconds
+func lockedStatus(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "too many failed attempts, try later")
+	withRetry, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter.Truncate(time.Second) + time.Second),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withRetry.Err()
+}
+
+// todoItemError maps todo service errors to gRPC status
+func todoItemError(err error) error {
+	switch {
+	case errors.Is(err, todoService.ErrItemNotFound):
+		return status.Error(codes.NotFound, "Item not found")
+	case errors.Is(err, todoService.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "Access denied")
+	case errors.Is(err, todoService.ErrProjectNotFound):
+		return status.Error(codes.NotFound, "Project not found")
+	case errors.Is(err, todoService.ErrParentNotFound):
+		return status.Error(codes.NotFound, "Parent task not found")
+	case errors.Is(err, todoService.ErrInvalidParent):
+		return status.Error(codes.InvalidArgument, "subtask can not belong to project")
+	case errors.Is(err, todoService.ErrDepthExceeded):
+		return status.Error(codes.FailedPrecondition, "subtask depth exceeded")
+	case errors.Is(err, todoService.ErrInvalidOrder):
+		return status.Error(codes.InvalidArgument, "task_ids must list every subtask once")
+	case errors.Is(err, todoService.ErrVersionConflict):
+		return status.Error(codes.Aborted, "task was changed by another client, reload it and retry")
+	case errors.Is(err, todoService.ErrBatchAborted):
+		return status.Error(codes.Aborted, "batch rolled back")
+	case errors.Is(err, todoService.ErrParentDeleted):
+		return status.Error(codes.FailedPrecondition, "parent task is in trash, restore it first")
+	case errors.Is(err, todoService.ErrInvalidRecurrence):
+		return status.Error(codes.InvalidArgument, strings.ReplaceAll(err.Error(), "\n", "; "))
+	case errors.Is(err, todoService.ErrInvalidBatch):
+		return status.Error(codes.InvalidArgument, "mutations must not be empty or exceed batch size limit")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+// loginError maps login errors to gRPC status
+func loginError(err error) error {
+	var lockedErr *authService.LockedError
+	if errors.As(err, &lockedErr) {
+		return lockedStatus(lockedErr.RetryAfter)
+	}
+	return status.Error(codes.PermissionDenied, "wrong username or password")
+}
+
+// refreshError maps token refresh errors to gRPC status
+func refreshError(err error) error {
+	if errors.Is(err, authService.ErrSecretReused) {
+		return status.Error(codes.Unauthenticated, "refresh token reused, session revoked")
+	}
+	return status.Error(codes.Unauthenticated, "wrong refresh token or revoked")
+}
+
+// registerError maps account registration errors to gRPC status
+func registerError(err error) error {
+	switch {
+	case errors.Is(err, accountService.ErrRegistrationDisabled):
+		return status.Error(codes.PermissionDenied, "registration is disabled")
+	case errors.Is(err, accountService.ErrInvalidUsername):
+		return status.Error(codes.InvalidArgument, "invalid username")
+	case errors.Is(err, accountService.ErrWeakPassword):
+		return status.Error(codes.InvalidArgument, strings.ReplaceAll(err.Error(), "\n", "; "))
+	case errors.Is(err, accountService.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, "username is taken")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+// listError maps task list errors to gRPC status
+func listError(err error) error {
+	switch {
+	case errors.Is(err, todoService.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, "invalid page token")
+	case errors.Is(err, todoService.ErrInvalidQuery):
+		return status.Error(codes.InvalidArgument, "overdue tasks can not be done")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+// watchError maps task subscription errors to gRPC status
+func watchError(err error) error {
+	switch {
+	case errors.Is(err, todoService.ErrInvalidResume):
+		return status.Error(codes.InvalidArgument, "invalid resume token")
+	case errors.Is(err, todoService.ErrResumeExpired):
+		return status.Error(codes.FailedPrecondition, "resume token expired, reload tasks")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
 }
 
 func (s *serverAPI) Login(
@@ -83,20 +391,78 @@ func (s *serverAPI) Login(
 	req *todo_protobuf_v1.LoginRequest,
 ) (*todo_protobuf_v1.LoginResponce, error) {
 
-	token, err := s.accountSecretCreator.CreateUserSecret(
+	result, err := s.accountSecretCreator.CreateUserSecret(
 		ctx,
 		serviceDTO.User{
 			Username: &req.Email,
 			Password: req.Password,
 		},
+		clientFromContext(ctx),
 	)
 
 	if err != nil {
-		return nil, status.Error(codes.PermissionDenied, "wrong username or password")
+		return nil, loginError(err)
+	}
+
+	if result.MfaChallenge != nil {
+		return &todo_protobuf_v1.LoginResponce{
+			MfaChallenge:          result.MfaChallenge.Token,
+			MfaChallengeExpiresAt: timestamppb.New(result.MfaChallenge.ExpiresAt),
+		}, nil
+	}
+
+	return loginResponce(result.Tokens), nil
+}
+
+func (s *serverAPI) RefreshToken(
+	ctx context.Context,
+	req *todo_protobuf_v1.RefreshTokenRequest,
+) (*todo_protobuf_v1.LoginResponce, error) {
+	tokens, err := s.accountSecretCreator.RefreshUserSecret(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, refreshError(err)
+	}
+
+	return loginResponce(tokens), nil
+}
+
+func (s *serverAPI) GetPublicKeys(
+	ctx context.Context,
+	req *todo_protobuf_v1.GetPublicKeysRequest,
+) (*todo_protobuf_v1.GetPublicKeysResponce, error) {
+	keys := s.publicKeysProvider.PublicKeys(ctx)
+
+	responce := &todo_protobuf_v1.GetPublicKeysResponce{
+		Keys: make([]*todo_protobuf_v1.JsonWebKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		responce.Keys = append(responce.Keys, &todo_protobuf_v1.JsonWebKey{
+			Kty: key.KeyType,
+			Kid: key.KeyID,
+			Use: key.Use,
+			Alg: key.Algorithm,
+			Crv: key.Curve,
+			X:   key.X,
+			N:   key.N,
+			E:   key.E,
+		})
+	}
+
+	return responce, nil
+}
+
+func (s *serverAPI) Register(
+	ctx context.Context,
+	req *todo_protobuf_v1.RegisterRequest,
+) (*todo_protobuf_v1.RegisterResponce, error) {
+	user, err := s.accountRegistrar.Register(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, registerError(err)
 	}
 
-	return &todo_protobuf_v1.LoginResponce{
-		Token: token,
+	return &todo_protobuf_v1.RegisterResponce{
+		UserId:   *user.UserID,
+		Username: *user.Username,
 	}, nil
 }
 
@@ -141,10 +507,26 @@ func (s *serverAPI) CreateTask(
 	ctx context.Context,
 	req *todo_protobuf_v1.CreateTaskRequest,
 ) (*todo_protobuf_v1.CreateTaskResponce, error) {
-	id, err := s.todoItemsCreatorService.Create(ctx, req.GetTitle(), req.GetUserId())
+	ownerID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	title := req.GetTitle()
+	notes := req.GetNotes()
+	projectID := req.GetProjectId()
+	autoComplete := req.GetAutoComplete()
+	id, err := s.todoItemsCreatorService.Create(ctx, serviceDTO.ToDoItem{
+		Title:        &title,
+		ProjectID:    &projectID,
+		AutoComplete: &autoComplete,
+		Notes:        &notes,
+		Priority:     priorityFromProto(req.GetPriority()),
+		DueAt:        timeFromProto(req.GetDueAt()),
+	}, ownerID)
 
 	if err != nil {
-		return nil, status.Error(codes.Internal, "Internal create error")
+		return nil, todoItemError(err)
 	}
 
 	return &todo_protobuf_v1.CreateTaskResponce{
@@ -157,21 +539,46 @@ func (s *serverAPI) ListTasks(
 	ctx context.Context,
 	req *todo_protobuf_v1.ListTasksRequest,
 ) (*todo_protobuf_v1.ListTasksResponce, error) {
-	items, err := s.todoItemsGetterService.GetList(ctx, req.GetUserId())
+	ownerID, err := callerID(ctx, req.GetUserId())
 	if err != nil {
-		return nil, status.Error(codes.Internal, "Internal error")
+		return nil, err
 	}
 
-	responseItems := make([]*todo_protobuf_v1.GetTaskByIdResponce, 0, len(items))
-	for _, item := range items {
-		responseItems = append(responseItems, &todo_protobuf_v1.GetTaskByIdResponce{
-			TaskId: item.ID,
-			Title:  *item.Title,
-			IsDone: *item.IsComplete,
-		})
+	query := serviceDTO.ToDoItemListQuery{
+		ProjectID:     req.ProjectId,
+		IncludeShared: req.GetIncludeShared(),
+		IsComplete:    req.IsDone,
+		DueAfter:      timeFromProto(req.GetDueAfter()),
+		DueBefore:     timeFromProto(req.GetDueBefore()),
+		Overdue:       req.GetOverdue(),
+		SortOrder:     sortOrderFromProto(req.GetSortOrder()),
+		PageSize:      int(req.GetPageSize()),
+		// v1 clients without page_size get all tasks as before paging was added
+		Unbounded: true,
+		PageToken: req.GetPageToken(),
+	}
+	for _, priority := range req.GetPriorities() {
+		if servicePriority := priorityFromProto(priority); servicePriority != nil {
+			query.Priorities = append(query.Priorities, *servicePriority)
+		}
+	}
+	if req.GetTitleContains() != "" {
+		titleContains := req.GetTitleContains()
+		query.TitleContains = &titleContains
+	}
+
+	page, err := s.todoItemsGetterService.GetList(ctx, ownerID, query)
+	if err != nil {
+		return nil, listError(err)
+	}
+
+	responseItems := make([]*todo_protobuf_v1.GetTaskByIdResponce, 0, len(page.Items))
+	for _, item := range page.Items {
+		responseItems = append(responseItems, taskToProto(item))
 	}
 	return &todo_protobuf_v1.ListTasksResponce{
-		Tasks: responseItems,
+		Tasks:         responseItems,
+		NextPageToken: page.NextPageToken,
 	}, nil
 }
 
@@ -179,16 +586,17 @@ func (s *serverAPI) GetTaskByID(
 	ctx context.Context,
 	req *todo_protobuf_v1.TaskByIdRequest,
 ) (*todo_protobuf_v1.GetTaskByIdResponce, error) {
-	item, err := s.todoItemsGetterService.GetByID(ctx, req.GetTaskId(), req.GetUserId())
+	ownerID, err := callerID(ctx, req.GetUserId())
 	if err != nil {
-		return nil, status.Error(codes.NotFound, "Item not found")
+		return nil, err
 	}
 
-	return &todo_protobuf_v1.GetTaskByIdResponce{
-		TaskId: req.TaskId,
-		Title:  *item.Title,
-		IsDone: *item.IsComplete,
-	}, nil
+	item, err := s.todoItemsGetterService.GetByID(ctx, req.GetTaskId(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return taskToProto(*item), nil
 }
 
 func (s *serverAPI) UpdateTaskByID(
@@ -196,14 +604,25 @@ func (s *serverAPI) UpdateTaskByID(
 	req *todo_protobuf_v1.UpdateTaskByIdRequest,
 ) (*todo_protobuf_v1.ChangedTaskByIdResponce, error) {
 
-	err := s.todoItemsUpdaterService.Update(ctx, serviceDTO.ToDoItem{
-		ID:         req.GetTaskId(),
-		Title:      req.Title,
-		IsComplete: req.IsDone,
-	}, req.GetUserId())
+	ownerID, err := callerID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsUpdaterService.Update(ctx, serviceDTO.ToDoItem{
+		ID:           req.GetTaskId(),
+		ProjectID:    req.ProjectId,
+		AutoComplete: req.AutoComplete,
+		Title:        req.Title,
+		IsComplete:   req.IsDone,
+		Notes:        req.Notes,
+		Priority:     priorityFromProto(req.GetPriority()),
+		DueAt:        timeFromProto(req.GetDueAt()),
+		Version:      req.GetVersion(),
+	}, ownerID)
 
 	if err != nil {
-		return nil, status.Error(codes.NotFound, "Item not found")
+		return nil, todoItemError(err)
 	}
 
 	return &todo_protobuf_v1.ChangedTaskByIdResponce{
@@ -214,11 +633,16 @@ func (s *serverAPI) UpdateTaskByID(
 
 func (s *serverAPI) DeleteTaskByID(
 	ctx context.Context,
-	req *todo_protobuf_v1.TaskByIdRequest,
+	req *todo_protobuf_v1.DeleteTaskByIdRequest,
 ) (*todo_protobuf_v1.ChangedTaskByIdResponce, error) {
-	err := s.todoItemsDeleterService.DeleteByID(ctx, req.GetTaskId(), req.GetUserId())
+	ownerID, err := callerID(ctx, req.GetUserId())
 	if err != nil {
-		return nil, status.Error(codes.NotFound, "Item not found")
+		return nil, err
+	}
+
+	err = s.todoItemsDeleterService.DeleteByID(ctx, req.GetTaskId(), ownerID, subtaskDeleteModeFromProto(req.GetSubtasks()))
+	if err != nil {
+		return nil, todoItemError(err)
 	}
 
 	return &todo_protobuf_v1.ChangedTaskByIdResponce{
@@ -226,3 +650,46 @@ func (s *serverAPI) DeleteTaskByID(
 		IsSuccess: true,
 	}, nil
 }
+
+func (s *serverAPI) WatchTasks(
+	req *todo_protobuf_v1.WatchTasksRequest,
+	stream grpc.ServerStreamingServer[todo_protobuf_v1.TaskEvent],
+) error {
+	ctx := stream.Context()
+
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.todoItemsWatcherService.Watch(ctx, ownerID, req.GetResumeToken())
+	if err != nil {
+		return watchError(err)
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream overflow, reconnect with the last resume token")
+			}
+
+			taskEvent := &todo_protobuf_v1.TaskEvent{
+				Type:        taskEventTypeToProto(event.Payload.Type),
+				TaskId:      event.Payload.Item.ID,
+				ResumeToken: event.Token,
+			}
+
+			if event.Payload.Type != serviceDTO.ToDoItemDeleted {
+				taskEvent.Task = taskToProto(event.Payload.Item)
+			}
+
+			if err := stream.Send(taskEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todoService "github.com/IldarGaleev/todo-backend-service/internal/services/todoservice"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallerID(t *testing.T) {
+	userID := uint64(5)
+	authorizedCtx := authcontext.WithUser(context.Background(), serviceDTO.User{UserID: &userID})
+
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		requestUserID uint64
+		expectedID    uint64
+		expectedCode  codes.Code
+	}{
+		{
+			name:         "anonymous caller",
+			ctx:          context.Background(),
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "user_id omitted",
+			ctx:          authorizedCtx,
+			expectedID:   userID,
+			expectedCode: codes.OK,
+		},
+		{
+			name:          "user_id matches caller",
+			ctx:           authorizedCtx,
+			requestUserID: userID,
+			expectedID:    userID,
+			expectedCode:  codes.OK,
+		},
+		{
+			name:          "user_id of another user",
+			ctx:           authorizedCtx,
+			requestUserID: userID + 1,
+			expectedCode:  codes.PermissionDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id, err := callerID(testCase.ctx, testCase.requestUserID)
+
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+			require.Equal(t, testCase.expectedID, id)
+		})
+	}
+}
+
+func TestLockedStatus(t *testing.T) {
+	err := lockedStatus(1500 * time.Millisecond)
+
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, retryInfo.GetRetryDelay().AsDuration())
+}
+
+type creatorStub struct {
+	err error
+}
+
+func (c creatorStub) Create(_ context.Context, _ serviceDTO.ToDoItem, _ uint64) (uint64, error) {
+	return 1, c.err
+}
+
+func TestServerAPI_CreateTask(t *testing.T) {
+	userID := uint64(5)
+	ctx := authcontext.WithUser(context.Background(), serviceDTO.User{UserID: &userID})
+
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{name: "created", expectedCode: codes.OK},
+		{name: "viewer of shared project", err: todoService.ErrAccessDenied, expectedCode: codes.PermissionDenied},
+		{name: "project not found", err: todoService.ErrProjectNotFound, expectedCode: codes.NotFound},
+		{name: "invalid parent", err: todoService.ErrInvalidParent, expectedCode: codes.InvalidArgument},
+		{name: "internal", err: errors.Join(todoService.ErrInternal, errors.New("db error")), expectedCode: codes.Internal},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := &serverAPI{services: services{todoItemsCreatorService: creatorStub{err: testCase.err}}}
+
+			_, err := server.CreateTask(ctx, &todo_protobuf_v1.CreateTaskRequest{Title: "task", ProjectId: 3})
+
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+		})
+	}
+}
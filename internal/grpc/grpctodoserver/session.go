@@ -0,0 +1,138 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/authcontext"
+	authService "github.com/IldarGaleev/todo-backend-service/internal/services/auth"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxUserAgentLength longest stored device description in characters
+const maxUserAgentLength = 255
+
+// clientFromContext returns device and address of the caller.
+// Device is taken from "x-device" metadata, "user-agent" if not set
+func clientFromContext(ctx context.Context) serviceDTO.Client {
+	var client serviceDTO.Client
+
+	if meta, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{"x-device", "user-agent"} {
+			if values := meta.Get(key); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+				client.UserAgent = strings.TrimSpace(values[0])
+				break
+			}
+		}
+	}
+
+	if runes := []rune(client.UserAgent); len(runes) > maxUserAgentLength {
+		client.UserAgent = string(runes[:maxUserAgentLength])
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+
+	return client
+}
+
+// callerSessionID returns session of the token used for the call. Zero for tokens issued without session
+func callerSessionID(ctx context.Context) uint64 {
+	user, ok := authcontext.UserFromContext(ctx)
+	if !ok || user.SessionID == nil {
+		return 0
+	}
+	return *user.SessionID
+}
+
+// sessionError maps session revoke errors to gRPC status
+func sessionError(err error) error {
+	if errors.Is(err, authService.ErrSessionNotFound) {
+		return status.Error(codes.NotFound, "Session not found")
+	}
+	return status.Error(codes.Internal, "Internal error")
+}
+
+func (s *serverAPI) ListSessions(
+	ctx context.Context,
+	req *todo_protobuf_v1.ListSessionsRequest,
+) (*todo_protobuf_v1.ListSessionsResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionGetterService.ListSessions(ctx, userID, callerSessionID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	responce := &todo_protobuf_v1.ListSessionsResponce{
+		Sessions: make([]*todo_protobuf_v1.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		responce.Sessions = append(responce.Sessions, &todo_protobuf_v1.Session{
+			SessionId:  session.ID,
+			Device:     session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  timeToProto(&session.CreatedAt),
+			LastSeenAt: timeToProto(&session.LastSeenAt),
+			ExpiresAt:  timeToProto(&session.ExpiresAt),
+			Current:    session.Current,
+		})
+	}
+
+	return responce, nil
+}
+
+func (s *serverAPI) RevokeSession(
+	ctx context.Context,
+	req *todo_protobuf_v1.RevokeSessionRequest,
+) (*todo_protobuf_v1.RevokeSessionResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessionRevokerService.RevokeSession(ctx, userID, req.GetSessionId())
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	return &todo_protobuf_v1.RevokeSessionResponce{}, nil
+}
+
+func (s *serverAPI) RevokeAllOtherSessions(
+	ctx context.Context,
+	req *todo_protobuf_v1.RevokeAllOtherSessionsRequest,
+) (*todo_protobuf_v1.RevokeAllOtherSessionsResponce, error) {
+	userID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := callerSessionID(ctx)
+	if sessionID == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "token has no session, login again")
+	}
+
+	count, err := s.sessionRevokerService.RevokeAllOtherSessions(ctx, userID, sessionID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal error")
+	}
+
+	return &todo_protobuf_v1.RevokeAllOtherSessionsResponce{
+		RevokedCount: count,
+	}, nil
+}
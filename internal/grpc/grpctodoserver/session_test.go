@@ -0,0 +1,55 @@
+package grpctodoserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientFromContext(t *testing.T) {
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50123},
+	})
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected serviceDTO.Client
+	}{
+		{
+			name:     "no metadata",
+			ctx:      context.Background(),
+			expected: serviceDTO.Client{},
+		},
+		{
+			name:     "user agent",
+			ctx:      metadata.NewIncomingContext(peerCtx, metadata.Pairs("user-agent", "grpc-go/1.0")),
+			expected: serviceDTO.Client{UserAgent: "grpc-go/1.0", IP: "10.0.0.1"},
+		},
+		{
+			name: "device overrides user agent",
+			ctx: metadata.NewIncomingContext(peerCtx, metadata.Pairs(
+				"user-agent", "grpc-go/1.0",
+				"x-device", "Pixel 8",
+			)),
+			expected: serviceDTO.Client{UserAgent: "Pixel 8", IP: "10.0.0.1"},
+		},
+		{
+			name:     "long device truncated",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-device", strings.Repeat("я", 300))),
+			expected: serviceDTO.Client{UserAgent: strings.Repeat("я", maxUserAgentLength)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, clientFromContext(testCase.ctx))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	shareService "github.com/IldarGaleev/todo-backend-service/internal/services/shareservice"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shareError maps share service errors to gRPC status
+func shareError(err error) error {
+	switch {
+	case errors.Is(err, shareService.ErrResourceNotFound):
+		return status.Error(codes.NotFound, "Resource not found")
+	case errors.Is(err, shareService.ErrUserNotFound):
+		return status.Error(codes.NotFound, "User not found")
+	case errors.Is(err, shareService.ErrMemberNotFound):
+		return status.Error(codes.NotFound, "Member not found")
+	case errors.Is(err, shareService.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "Access denied")
+	case errors.Is(err, shareService.ErrAlreadyMember):
+		return status.Error(codes.AlreadyExists, "user is already a member")
+	case errors.Is(err, shareService.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, "invalid role")
+	case errors.Is(err, shareService.ErrInvalidResource):
+		return status.Error(codes.InvalidArgument, "invalid resource type")
+	case errors.Is(err, shareService.ErrInvalidMember):
+		return status.Error(codes.InvalidArgument, "owner can not be a member")
+	default:
+		return status.Error(codes.Internal, "Internal error")
+	}
+}
+
+func shareResourceFromProto(resource *todo_protobuf_v1.ShareResource) serviceDTO.ShareResource {
+	result := serviceDTO.ShareResource{ID: resource.GetId()}
+	switch resource.GetType() {
+	case todo_protobuf_v1.ShareResourceType_SHARE_RESOURCE_TYPE_TASK:
+		result.Type = serviceDTO.ShareResourceTask
+	case todo_protobuf_v1.ShareResourceType_SHARE_RESOURCE_TYPE_PROJECT:
+		result.Type = serviceDTO.ShareResourceProject
+	}
+	return result
+}
+
+func shareRoleFromProto(role todo_protobuf_v1.ShareRole) serviceDTO.ShareRole {
+	switch role {
+	case todo_protobuf_v1.ShareRole_SHARE_ROLE_VIEWER:
+		return serviceDTO.ShareRoleViewer
+	case todo_protobuf_v1.ShareRole_SHARE_ROLE_EDITOR:
+		return serviceDTO.ShareRoleEditor
+	case todo_protobuf_v1.ShareRole_SHARE_ROLE_OWNER:
+		return serviceDTO.ShareRoleOwner
+	default:
+		return serviceDTO.ShareRoleNone
+	}
+}
+
+func shareRoleToProto(role serviceDTO.ShareRole) todo_protobuf_v1.ShareRole {
+	switch role {
+	case serviceDTO.ShareRoleViewer:
+		return todo_protobuf_v1.ShareRole_SHARE_ROLE_VIEWER
+	case serviceDTO.ShareRoleEditor:
+		return todo_protobuf_v1.ShareRole_SHARE_ROLE_EDITOR
+	case serviceDTO.ShareRoleOwner:
+		return todo_protobuf_v1.ShareRole_SHARE_ROLE_OWNER
+	default:
+		return todo_protobuf_v1.ShareRole_SHARE_ROLE_UNSPECIFIED
+	}
+}
+
+func memberToProto(member serviceDTO.ShareMember) *todo_protobuf_v1.MemberResponce {
+	return &todo_protobuf_v1.MemberResponce{
+		UserId:    member.UserID,
+		Username:  member.GetUsername(),
+		Role:      shareRoleToProto(member.Role),
+		CreatedAt: timeToProto(&member.CreatedAt),
+	}
+}
+
+func (s *serverAPI) InviteMember(
+	ctx context.Context,
+	req *todo_protobuf_v1.InviteMemberRequest,
+) (*todo_protobuf_v1.MemberResponce, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.membersCreatorService.Invite(
+		ctx,
+		shareResourceFromProto(req.GetResource()),
+		req.GetUsername(),
+		shareRoleFromProto(req.GetRole()),
+		callerUserID,
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return memberToProto(*member), nil
+}
+
+func (s *serverAPI) ChangeMemberRole(
+	ctx context.Context,
+	req *todo_protobuf_v1.ChangeMemberRoleRequest,
+) (*todo_protobuf_v1.ChangedMemberResponce, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.membersUpdaterService.ChangeRole(
+		ctx,
+		shareResourceFromProto(req.GetResource()),
+		req.GetUserId(),
+		shareRoleFromProto(req.GetRole()),
+		callerUserID,
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedMemberResponce{
+		UserId:    req.GetUserId(),
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *serverAPI) RevokeMember(
+	ctx context.Context,
+	req *todo_protobuf_v1.RevokeMemberRequest,
+) (*todo_protobuf_v1.ChangedMemberResponce, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.membersDeleterService.Revoke(ctx, shareResourceFromProto(req.GetResource()), req.GetUserId(), callerUserID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedMemberResponce{
+		UserId:    req.GetUserId(),
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *serverAPI) ListMembers(
+	ctx context.Context,
+	req *todo_protobuf_v1.ListMembersRequest,
+) (*todo_protobuf_v1.ListMembersResponce, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.membersGetterService.ListMembers(ctx, shareResourceFromProto(req.GetResource()), callerUserID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	members := make([]*todo_protobuf_v1.MemberResponce, 0, len(list.Members))
+	for _, member := range list.Members {
+		members = append(members, memberToProto(member))
+	}
+
+	return &todo_protobuf_v1.ListMembersResponce{
+		OwnerId: list.OwnerID,
+		Members: members,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todoService "github.com/IldarGaleev/todo-backend-service/internal/services/todoservice"
+	todo_protobuf_v1 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) AddSubtask(
+	ctx context.Context,
+	req *todo_protobuf_v1.AddSubtaskRequest,
+) (*todo_protobuf_v1.CreateTaskResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := req.GetParentId()
+	if parentID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "parent_id is required")
+	}
+
+	title := req.GetTitle()
+	notes := req.GetNotes()
+	autoComplete := req.GetAutoComplete()
+	id, err := s.todoItemsCreatorService.Create(ctx, serviceDTO.ToDoItem{
+		Title:        &title,
+		ParentID:     &parentID,
+		AutoComplete: &autoComplete,
+		Notes:        &notes,
+		Priority:     priorityFromProto(req.GetPriority()),
+		DueAt:        timeFromProto(req.GetDueAt()),
+	}, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v1.CreateTaskResponce{
+		TaskId: id,
+	}, nil
+}
+
+func (s *serverAPI) ReorderSubtasks(
+	ctx context.Context,
+	req *todo_protobuf_v1.ReorderSubtasksRequest,
+) (*todo_protobuf_v1.ChangedTaskByIdResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsUpdaterService.Reorder(ctx, req.GetParentId(), req.GetTaskIds(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v1.ChangedTaskByIdResponce{
+		TaskId:    req.GetParentId(),
+		IsSuccess: true,
+	}, nil
+}
+
+func (s *serverAPI) ListSubtasks(
+	ctx context.Context,
+	req *todo_protobuf_v1.ListSubtasksRequest,
+) (*todo_protobuf_v1.ListTasksResponce, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := req.GetParentId()
+	page, err := s.todoItemsGetterService.GetList(ctx, ownerID, serviceDTO.ToDoItemListQuery{
+		ParentID:  &parentID,
+		SortOrder: serviceDTO.ToDoItemSortByPositionAsc,
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		if errors.Is(err, todoService.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, todoItemError(err)
+	}
+
+	responseItems := make([]*todo_protobuf_v1.GetTaskByIdResponce, 0, len(page.Items))
+	for _, item := range page.Items {
+		responseItems = append(responseItems, taskToProto(item))
+	}
+	return &todo_protobuf_v1.ListTasksResponce{
+		Tasks:         responseItems,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
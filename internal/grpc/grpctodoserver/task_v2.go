@@ -0,0 +1,565 @@
+package grpctodoserver
+
+import (
+	"context"
+	"errors"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	todoService "github.com/IldarGaleev/todo-backend-service/internal/services/todoservice"
+	todo_protobuf_v2 "github.com/IldarGaleev/todo-backend-service/pkg/grpc/proto/todo/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errInvalidMask = errors.New("invalid update mask")
+
+// taskServerV2 implements v2 TaskService
+type taskServerV2 struct {
+	todo_protobuf_v2.UnimplementedTaskServiceServer
+	services
+}
+
+func (s *taskServerV2) CreateTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.CreateTaskRequest,
+) (*todo_protobuf_v2.CreateTaskResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := taskCreateFromProtoV2(req.GetTask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id, err := s.todoItemsCreatorService.Create(ctx, item, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v2.CreateTaskResponse{
+		TaskId: id,
+	}, nil
+}
+
+func (s *taskServerV2) GetTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.GetTaskRequest,
+) (*todo_protobuf_v2.Task, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.todoItemsGetterService.GetByID(ctx, req.GetTaskId(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return taskToProtoV2(*item), nil
+}
+
+func (s *taskServerV2) ListTasks(
+	ctx context.Context,
+	req *todo_protobuf_v2.ListTasksRequest,
+) (*todo_protobuf_v2.ListTasksResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	query := serviceDTO.ToDoItemListQuery{
+		ProjectID:     req.ProjectId,
+		IncludeShared: req.GetIncludeShared(),
+		IsComplete:    req.IsDone,
+		DueAfter:      timeFromProto(req.GetDueAfter()),
+		DueBefore:     timeFromProto(req.GetDueBefore()),
+		Overdue:       req.GetOverdue(),
+		SortOrder:     sortOrderFromProtoV2(req.GetSortOrder()),
+		PageSize:      int(req.GetPageSize()),
+		PageToken:     req.GetPageToken(),
+	}
+	for _, priority := range req.GetPriorities() {
+		if servicePriority := priorityFromProtoV2(priority); servicePriority != nil {
+			query.Priorities = append(query.Priorities, *servicePriority)
+		}
+	}
+	if req.GetTitleContains() != "" {
+		titleContains := req.GetTitleContains()
+		query.TitleContains = &titleContains
+	}
+
+	page, err := s.todoItemsGetterService.GetList(ctx, ownerID, query)
+	if err != nil {
+		return nil, listError(err)
+	}
+
+	return taskPageToProtoV2(page), nil
+}
+
+func (s *taskServerV2) UpdateTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.UpdateTaskRequest,
+) (*todo_protobuf_v2.Task, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := taskUpdateFromMask(req.GetTask(), req.GetUpdateMask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.todoItemsUpdaterService.Update(ctx, item, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	updated, err := s.todoItemsGetterService.GetByID(ctx, item.ID, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return taskToProtoV2(*updated), nil
+}
+
+func (s *taskServerV2) DeleteTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.DeleteTaskRequest,
+) (*todo_protobuf_v2.DeleteTaskResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsDeleterService.DeleteByID(ctx, req.GetTaskId(), ownerID, subtaskDeleteModeFromProtoV2(req.GetSubtasks()))
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v2.DeleteTaskResponse{}, nil
+}
+
+func (s *taskServerV2) BatchMutateTasks(
+	ctx context.Context,
+	req *todo_protobuf_v2.BatchMutateTasksRequest,
+) (*todo_protobuf_v2.BatchMutateTasksResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mutations := make([]serviceDTO.ToDoItemMutation, 0, len(req.GetMutations()))
+	for i, mutation := range req.GetMutations() {
+		item, err := taskMutationFromProtoV2(mutation)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "mutations[%d]: %s", i, err)
+		}
+		mutations = append(mutations, item)
+	}
+
+	results, err := s.todoItemsBatchService.Batch(
+		ctx,
+		mutations,
+		ownerID,
+		req.GetMode() == todo_protobuf_v2.BatchMode_BATCH_MODE_ATOMIC,
+	)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	response := &todo_protobuf_v2.BatchMutateTasksResponse{
+		Results: make([]*todo_protobuf_v2.TaskMutationResult, 0, len(results)),
+	}
+	for _, result := range results {
+		mutationResult := &todo_protobuf_v2.TaskMutationResult{TaskId: result.ID}
+		if result.Err != nil {
+			st := status.Convert(todoItemError(result.Err))
+			mutationResult.Code = int32(st.Code())
+			mutationResult.Message = st.Message()
+		}
+		response.Results = append(response.Results, mutationResult)
+	}
+
+	return response, nil
+}
+
+func (s *taskServerV2) PreviewRecurrence(
+	ctx context.Context,
+	req *todo_protobuf_v2.PreviewRecurrenceRequest,
+) (*todo_protobuf_v2.PreviewRecurrenceResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	query := serviceDTO.ToDoItemOccurrencesQuery{
+		Count: int(req.GetCount()),
+	}
+	switch source := req.GetSource().(type) {
+	case *todo_protobuf_v2.PreviewRecurrenceRequest_TaskId:
+		query.ItemID = source.TaskId
+	case *todo_protobuf_v2.PreviewRecurrenceRequest_Schedule:
+		if source.Schedule.GetStart() == nil {
+			return nil, status.Error(codes.InvalidArgument, "schedule start is required")
+		}
+		query.Recurrence = *recurrenceFromProtoV2(source.Schedule.GetRecurrence())
+		query.Start = source.Schedule.GetStart().AsTime()
+	default:
+		return nil, status.Error(codes.InvalidArgument, "task_id or schedule is required")
+	}
+
+	occurrences, err := s.todoItemsGetterService.Occurrences(ctx, query, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	response := &todo_protobuf_v2.PreviewRecurrenceResponse{
+		Occurrences: make([]*timestamppb.Timestamp, 0, len(occurrences)),
+	}
+	for _, occurrence := range occurrences {
+		response.Occurrences = append(response.Occurrences, timestamppb.New(occurrence))
+	}
+
+	return response, nil
+}
+
+func (s *taskServerV2) WatchTasks(
+	req *todo_protobuf_v2.WatchTasksRequest,
+	stream grpc.ServerStreamingServer[todo_protobuf_v2.TaskEvent],
+) error {
+	ctx := stream.Context()
+
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.todoItemsWatcherService.Watch(ctx, ownerID, req.GetResumeToken())
+	if err != nil {
+		return watchError(err)
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream overflow, reconnect with the last resume token")
+			}
+
+			taskEvent := &todo_protobuf_v2.TaskEvent{
+				Type:        taskEventTypeToProtoV2(event.Payload.Type),
+				TaskId:      event.Payload.Item.ID,
+				ResumeToken: event.Token,
+			}
+
+			if event.Payload.Type != serviceDTO.ToDoItemDeleted {
+				taskEvent.Task = taskToProtoV2(event.Payload.Item)
+			}
+
+			if err := stream.Send(taskEvent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *taskServerV2) ListDeletedTasks(
+	ctx context.Context,
+	_ *todo_protobuf_v2.ListDeletedTasksRequest,
+) (*todo_protobuf_v2.ListDeletedTasksResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.todoItemsGetterService.ListDeleted(ctx, ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	tasks := make([]*todo_protobuf_v2.Task, 0, len(items))
+	for _, item := range items {
+		tasks = append(tasks, taskToProtoV2(item))
+	}
+
+	return &todo_protobuf_v2.ListDeletedTasksResponse{
+		Tasks: tasks,
+	}, nil
+}
+
+func (s *taskServerV2) RestoreTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.RestoreTaskRequest,
+) (*todo_protobuf_v2.Task, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsUpdaterService.Restore(ctx, req.GetTaskId(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	restored, err := s.todoItemsGetterService.GetByID(ctx, req.GetTaskId(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return taskToProtoV2(*restored), nil
+}
+
+func (s *taskServerV2) PurgeTask(
+	ctx context.Context,
+	req *todo_protobuf_v2.PurgeTaskRequest,
+) (*todo_protobuf_v2.PurgeTaskResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsDeleterService.Purge(ctx, req.GetTaskId(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v2.PurgeTaskResponse{}, nil
+}
+
+func (s *taskServerV2) ListSubtasks(
+	ctx context.Context,
+	req *todo_protobuf_v2.ListSubtasksRequest,
+) (*todo_protobuf_v2.ListTasksResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := req.GetParentId()
+	page, err := s.todoItemsGetterService.GetList(ctx, ownerID, serviceDTO.ToDoItemListQuery{
+		ParentID:  &parentID,
+		SortOrder: serviceDTO.ToDoItemSortByPositionAsc,
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		if errors.Is(err, todoService.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, todoItemError(err)
+	}
+
+	return taskPageToProtoV2(page), nil
+}
+
+func (s *taskServerV2) ReorderSubtasks(
+	ctx context.Context,
+	req *todo_protobuf_v2.ReorderSubtasksRequest,
+) (*todo_protobuf_v2.ReorderSubtasksResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.todoItemsUpdaterService.Reorder(ctx, req.GetParentId(), req.GetTaskIds(), ownerID)
+	if err != nil {
+		return nil, todoItemError(err)
+	}
+
+	return &todo_protobuf_v2.ReorderSubtasksResponse{}, nil
+}
+
+func (s *taskServerV2) CreateProject(
+	ctx context.Context,
+	req *todo_protobuf_v2.CreateProjectRequest,
+) (*todo_protobuf_v2.CreateProjectResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.projectsCreatorService.Create(ctx, req.GetName(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v2.CreateProjectResponse{
+		ProjectId: id,
+	}, nil
+}
+
+func (s *taskServerV2) ListProjects(
+	ctx context.Context,
+	req *todo_protobuf_v2.ListProjectsRequest,
+) (*todo_protobuf_v2.ListProjectsResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectsGetterService.GetList(ctx, ownerID, req.GetIncludeArchived(), req.GetIncludeShared())
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	responseProjects := make([]*todo_protobuf_v2.Project, 0, len(projects))
+	for _, project := range projects {
+		responseProjects = append(responseProjects, projectToProtoV2(project))
+	}
+
+	return &todo_protobuf_v2.ListProjectsResponse{
+		Projects: responseProjects,
+	}, nil
+}
+
+func (s *taskServerV2) RenameProject(
+	ctx context.Context,
+	req *todo_protobuf_v2.RenameProjectRequest,
+) (*todo_protobuf_v2.RenameProjectResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.projectsUpdaterService.Rename(ctx, req.GetProjectId(), req.GetName(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v2.RenameProjectResponse{}, nil
+}
+
+func (s *taskServerV2) ArchiveProject(
+	ctx context.Context,
+	req *todo_protobuf_v2.ArchiveProjectRequest,
+) (*todo_protobuf_v2.ArchiveProjectResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.projectsUpdaterService.Archive(ctx, req.GetProjectId(), !req.GetUnarchive(), ownerID)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v2.ArchiveProjectResponse{}, nil
+}
+
+func (s *taskServerV2) DeleteProject(
+	ctx context.Context,
+	req *todo_protobuf_v2.DeleteProjectRequest,
+) (*todo_protobuf_v2.DeleteProjectResponse, error) {
+	ownerID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, ok := projectDeleteModeFromProtoV2(req.GetMode())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown delete mode")
+	}
+
+	err = s.projectsDeleterService.DeleteByID(ctx, req.GetProjectId(), ownerID, mode, req.GetReassignToProjectId())
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	return &todo_protobuf_v2.DeleteProjectResponse{}, nil
+}
+
+func (s *taskServerV2) InviteMember(
+	ctx context.Context,
+	req *todo_protobuf_v2.InviteMemberRequest,
+) (*todo_protobuf_v2.Member, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.membersCreatorService.Invite(
+		ctx,
+		shareResourceFromProtoV2(req.GetResource()),
+		req.GetUsername(),
+		shareRoleFromProtoV2(req.GetRole()),
+		callerUserID,
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return memberToProtoV2(*member), nil
+}
+
+func (s *taskServerV2) ChangeMemberRole(
+	ctx context.Context,
+	req *todo_protobuf_v2.ChangeMemberRoleRequest,
+) (*todo_protobuf_v2.ChangeMemberRoleResponse, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.membersUpdaterService.ChangeRole(
+		ctx,
+		shareResourceFromProtoV2(req.GetResource()),
+		req.GetUserId(),
+		shareRoleFromProtoV2(req.GetRole()),
+		callerUserID,
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &todo_protobuf_v2.ChangeMemberRoleResponse{}, nil
+}
+
+func (s *taskServerV2) RevokeMember(
+	ctx context.Context,
+	req *todo_protobuf_v2.RevokeMemberRequest,
+) (*todo_protobuf_v2.RevokeMemberResponse, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.membersDeleterService.Revoke(ctx, shareResourceFromProtoV2(req.GetResource()), req.GetUserId(), callerUserID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &todo_protobuf_v2.RevokeMemberResponse{}, nil
+}
+
+func (s *taskServerV2) ListMembers(
+	ctx context.Context,
+	req *todo_protobuf_v2.ListMembersRequest,
+) (*todo_protobuf_v2.ListMembersResponse, error) {
+	callerUserID, err := callerID(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.membersGetterService.ListMembers(ctx, shareResourceFromProtoV2(req.GetResource()), callerUserID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	members := make([]*todo_protobuf_v2.Member, 0, len(list.Members))
+	for _, member := range list.Members {
+		members = append(members, memberToProtoV2(member))
+	}
+
+	return &todo_protobuf_v2.ListMembersResponse{
+		OwnerId: list.OwnerID,
+		Members: members,
+	}, nil
+}
@@ -0,0 +1,48 @@
+// Package authcontext keeps authenticated caller identity in request context
+package authcontext
+
+import (
+	"context"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+)
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying authenticated user
+func WithUser(ctx context.Context, user serviceDTO.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns authenticated user. False if request is anonymous
+func UserFromContext(ctx context.Context) (*serviceDTO.User, bool) {
+	user, ok := ctx.Value(userKey{}).(serviceDTO.User)
+	if !ok || user.UserID == nil {
+		return nil, false
+	}
+	return &user, true
+}
+
+// Service identity of a caller authenticated with verified client certificate
+type Service struct {
+	// Name is common name of certificate subject
+	Name     string
+	DNSNames []string
+	URIs     []string
+}
+
+type serviceKey struct{}
+
+// WithService returns a copy of ctx carrying authenticated service
+func WithService(ctx context.Context, service Service) context.Context {
+	return context.WithValue(ctx, serviceKey{}, service)
+}
+
+// ServiceFromContext returns service authenticated with client certificate. False if caller sent no certificate
+func ServiceFromContext(ctx context.Context) (*Service, bool) {
+	service, ok := ctx.Value(serviceKey{}).(Service)
+	if !ok {
+		return nil, false
+	}
+	return &service, true
+}
@@ -0,0 +1,216 @@
+// Package eventhub implements in-process pub/sub with resumable subscriptions
+package eventhub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidResumeToken = errors.New("event hub: invalid resume token")
+	ErrResumeTokenExpired = errors.New("event hub: resume token expired")
+	ErrSlowSubscriber     = errors.New("event hub: subscriber is too slow")
+)
+
+// Event published event. Token resumes subscription right after this event
+type Event[T any] struct {
+	Token   string
+	Payload T
+}
+
+type entry[T any] struct {
+	seq     uint64
+	key     uint64
+	payload T
+}
+
+// Subscription receives events published for one key
+type Subscription[T any] struct {
+	C <-chan Event[T]
+
+	hub    *Hub[T]
+	key    uint64
+	events chan Event[T]
+	err    error
+	closed bool
+}
+
+// Hub fans out events to subscribers of the same key and keeps
+// the last published events to replay them for resumed subscriptions
+type Hub[T any] struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []entry[T]
+	historyNext int
+	bufferSize  int
+	subscribers map[uint64]map[*Subscription[T]]struct{}
+}
+
+// New create event hub. historySize events are kept for resuming,
+// bufferSize events may be queued for a subscriber before it is dropped.
+// Negative sizes are treated as zero
+func New[T any](historySize int, bufferSize int) *Hub[T] {
+	historySize = max(historySize, 0)
+	bufferSize = max(bufferSize, 0)
+
+	epoch := make([]byte, 8)
+	_, _ = rand.Read(epoch)
+
+	return &Hub[T]{
+		epoch:       hex.EncodeToString(epoch),
+		history:     make([]entry[T], 0, historySize),
+		bufferSize:  bufferSize,
+		subscribers: make(map[uint64]map[*Subscription[T]]struct{}),
+	}
+}
+
+func (h *Hub[T]) token(seq uint64) string {
+	return h.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseToken returns sequence number of token issued by this hub instance
+func (h *Hub[T]) parseToken(token string) (uint64, error) {
+	epoch, seqStr, found := strings.Cut(token, "-")
+	if !found {
+		return 0, ErrInvalidResumeToken
+	}
+
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidResumeToken
+	}
+
+	if epoch != h.epoch || seq > h.seq {
+		// token of another hub instance: events may be lost after restart
+		return 0, ErrResumeTokenExpired
+	}
+
+	return seq, nil
+}
+
+// oldestSeq returns sequence number of the oldest event in history
+func (h *Hub[T]) oldestSeq() uint64 {
+	if len(h.history) == 0 {
+		return h.seq + 1
+	}
+	if len(h.history) < cap(h.history) {
+		return h.history[0].seq
+	}
+	return h.history[h.historyNext].seq
+}
+
+func (h *Hub[T]) remember(e entry[T]) {
+	if cap(h.history) == 0 {
+		return
+	}
+	if len(h.history) < cap(h.history) {
+		h.history = append(h.history, e)
+		return
+	}
+	h.history[h.historyNext] = e
+	h.historyNext = (h.historyNext + 1) % cap(h.history)
+}
+
+// replay returns remembered events of key published after seq
+func (h *Hub[T]) replay(key uint64, seq uint64) []Event[T] {
+	var events []Event[T]
+	for i := 0; i < len(h.history); i++ {
+		e := h.history[(h.historyNext+i)%len(h.history)]
+		if e.key == key && e.seq > seq {
+			events = append(events, Event[T]{Token: h.token(e.seq), Payload: e.payload})
+		}
+	}
+	return events
+}
+
+// Publish sends payload to all subscribers of key
+func (h *Hub[T]) Publish(key uint64, payload T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	h.remember(entry[T]{seq: h.seq, key: key, payload: payload})
+
+	event := Event[T]{Token: h.token(h.seq), Payload: payload}
+	for sub := range h.subscribers[key] {
+		select {
+		case sub.events <- event:
+		default:
+			h.drop(sub, ErrSlowSubscriber)
+		}
+	}
+}
+
+// Subscribe returns subscription for events of key.
+// Non-empty resumeToken replays events published after the event with this token
+func (h *Hub[T]) Subscribe(key uint64, resumeToken string) (*Subscription[T], error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Event[T]
+	if resumeToken != "" {
+		seq, err := h.parseToken(resumeToken)
+		if err != nil {
+			return nil, err
+		}
+		if seq+1 < h.oldestSeq() {
+			return nil, ErrResumeTokenExpired
+		}
+		backlog = h.replay(key, seq)
+	}
+
+	events := make(chan Event[T], h.bufferSize+len(backlog))
+	for _, event := range backlog {
+		events <- event
+	}
+
+	sub := &Subscription[T]{
+		C:      events,
+		hub:    h,
+		key:    key,
+		events: events,
+	}
+
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = make(map[*Subscription[T]]struct{})
+	}
+	h.subscribers[key][sub] = struct{}{}
+
+	return sub, nil
+}
+
+// drop removes subscriber and closes its channel. Must be called with lock held
+func (h *Hub[T]) drop(sub *Subscription[T], err error) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	sub.err = err
+	close(sub.events)
+
+	delete(h.subscribers[sub.key], sub)
+	if len(h.subscribers[sub.key]) == 0 {
+		delete(h.subscribers, sub.key)
+	}
+}
+
+// Close unsubscribe from hub
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.drop(s, nil)
+}
+
+// Err returns reason why hub closed subscription channel
+func (s *Subscription[T]) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	return s.err
+}
@@ -0,0 +1,142 @@
+package eventhub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func receiveAll[T any](sub *Subscription[T]) []T {
+	var payloads []T
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return payloads
+			}
+			payloads = append(payloads, event.Payload)
+		default:
+			return payloads
+		}
+	}
+}
+
+func TestHub_PublishToKeySubscribers(t *testing.T) {
+	hub := New[string](10, 10)
+
+	sub, err := hub.Subscribe(1, "")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	hub.Publish(1, "first")
+	hub.Publish(2, "foreign")
+	hub.Publish(1, "second")
+
+	require.Equal(t, []string{"first", "second"}, receiveAll(sub))
+}
+
+func TestHub_ResumeReplaysMissedEvents(t *testing.T) {
+	hub := New[string](10, 10)
+
+	sub, err := hub.Subscribe(1, "")
+	require.NoError(t, err)
+
+	hub.Publish(1, "seen")
+	seen := <-sub.C
+	sub.Close()
+
+	hub.Publish(1, "missed")
+	hub.Publish(2, "foreign")
+
+	resumed, err := hub.Subscribe(1, seen.Token)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	hub.Publish(1, "live")
+
+	require.Equal(t, []string{"missed", "live"}, receiveAll(resumed))
+}
+
+func TestHub_ResumeTokenErrors(t *testing.T) {
+	hub := New[string](2, 10)
+
+	sub, err := hub.Subscribe(1, "")
+	require.NoError(t, err)
+	hub.Publish(1, "old")
+	old := <-sub.C
+	sub.Close()
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(1, "new")
+	}
+
+	anotherHub := New[string](2, 10)
+	anotherHub.Publish(1, "another")
+
+	testCases := []struct {
+		name          string
+		token         string
+		expectedError error
+	}{
+		{
+			name:          "malformed token",
+			token:         "token",
+			expectedError: ErrInvalidResumeToken,
+		},
+		{
+			name:          "malformed sequence",
+			token:         hub.epoch + "-seq",
+			expectedError: ErrInvalidResumeToken,
+		},
+		{
+			name:          "evicted from history",
+			token:         old.Token,
+			expectedError: ErrResumeTokenExpired,
+		},
+		{
+			name:          "issued before restart",
+			token:         anotherHub.token(1),
+			expectedError: ErrResumeTokenExpired,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := hub.Subscribe(1, testCase.token)
+
+			require.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestHub_SlowSubscriberDropped(t *testing.T) {
+	hub := New[int](10, 2)
+
+	sub, err := hub.Subscribe(1, "")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(1, i)
+	}
+
+	require.Equal(t, []int{0, 1}, receiveAll(sub))
+	_, ok := <-sub.C
+	require.False(t, ok)
+	require.ErrorIs(t, sub.Err(), ErrSlowSubscriber)
+
+	sub.Close()
+}
+
+func TestHub_NegativeSizes(t *testing.T) {
+	hub := New[int](-1, -1)
+
+	sub, err := hub.Subscribe(1, "")
+	require.NoError(t, err)
+
+	hub.Publish(1, 1)
+
+	require.Empty(t, receiveAll(sub))
+	require.ErrorIs(t, sub.Err(), ErrSlowSubscriber)
+
+	sub.Close()
+}
@@ -0,0 +1,350 @@
+// Package jwtkeys implements key ring for signing and verifying JWT.
+// Keys are loaded from PEM files and reloaded periodically, so keys rotate without restart
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSABits minimal accepted size of RSA keys
+const MinRSABits = 2048
+
+// keyFileExt extension of key files loaded from directory
+const keyFileExt = ".pem"
+
+var (
+	ErrNoSigningKey   = errors.New("jwtkeys: no signing key")
+	ErrUnknownKey     = errors.New("jwtkeys: unknown key")
+	ErrUnsupportedKey = errors.New("jwtkeys: unsupported key")
+	ErrDuplicateKey   = errors.New("jwtkeys: duplicate key id")
+)
+
+// Key signs or verifies JWT
+type Key struct {
+	// ID is sent in kid header of tokens. Empty for symmetric key
+	ID     string
+	Method jwt.SigningMethod
+	// SignKey is nil for verification only keys
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+// JWK public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// KeyRing keeps verification keys and the key signing new tokens
+type KeyRing struct {
+	logger         *slog.Logger
+	dir            string
+	files          []string
+	signingKeyID   string
+	reloadInterval time.Duration
+
+	mu         sync.RWMutex
+	keys       map[string]*Key
+	signingKey *Key
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates key ring loading every *.pem file of dir and files.
+// Key id is the file name without extension. Unless signingKeyID is set,
+// private key with the greatest id signs tokens. Keys are loaded by MustRun
+func New(
+	log *slog.Logger,
+	dir string,
+	files []string,
+	signingKeyID string,
+	reloadInterval time.Duration,
+) *KeyRing {
+	return &KeyRing{
+		logger:         log.With(slog.String("module", "jwtKeys")),
+		dir:            dir,
+		files:          files,
+		signingKeyID:   signingKeyID,
+		reloadInterval: reloadInterval,
+	}
+}
+
+// NewHMAC creates key ring with single HS256 key. Tokens signed with it have no kid header
+func NewHMAC(log *slog.Logger, secret []byte) *KeyRing {
+	key := &Key{
+		Method:    jwt.SigningMethodHS256,
+		SignKey:   secret,
+		VerifyKey: secret,
+	}
+	return &KeyRing{
+		logger:     log.With(slog.String("module", "jwtKeys")),
+		keys:       map[string]*Key{key.ID: key},
+		signingKey: key,
+	}
+}
+
+// MustRun loads keys and starts periodic reload. Panic if failed
+func (r *KeyRing) MustRun() {
+	if r.dir == "" && len(r.files) == 0 {
+		return
+	}
+
+	if err := r.Reload(); err != nil {
+		panic(err)
+	}
+
+	if r.reloadInterval <= 0 {
+		return
+	}
+
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.watch()
+}
+
+// Stop stops periodic reload
+func (r *KeyRing) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+}
+
+func (r *KeyRing) watch() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				r.logger.Error("keys reload error, previous keys kept", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// Reload replaces keys with keys from files. Keys are kept unchanged if any file is invalid
+func (r *KeyRing) Reload() error {
+	paths, err := r.keyPaths()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*Key, len(paths))
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if _, ok := keys[key.ID]; ok {
+			return fmt.Errorf("%s: %w", path, ErrDuplicateKey)
+		}
+		keys[key.ID] = key
+	}
+
+	signingKey, err := r.selectSigningKey(keys)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	previous := r.signingKey
+	r.keys = keys
+	r.signingKey = signingKey
+	r.mu.Unlock()
+
+	if previous == nil || previous.ID != signingKey.ID {
+		r.logger.Info("signing key changed", slog.String("kid", signingKey.ID), slog.Int("keys", len(keys)))
+	}
+
+	return nil
+}
+
+func (r *KeyRing) keyPaths() ([]string, error) {
+	var paths []string
+
+	if r.dir != "" {
+		entries, err := os.ReadDir(r.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+				continue
+			}
+			paths = append(paths, filepath.Join(r.dir, entry.Name()))
+		}
+	}
+
+	return append(paths, r.files...), nil
+}
+
+func (r *KeyRing) selectSigningKey(keys map[string]*Key) (*Key, error) {
+	if r.signingKeyID != "" {
+		key, ok := keys[r.signingKeyID]
+		if !ok || key.SignKey == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNoSigningKey, r.signingKeyID)
+		}
+		return key, nil
+	}
+
+	var signingKey *Key
+	for _, key := range keys {
+		if key.SignKey != nil && (signingKey == nil || key.ID > signingKey.ID) {
+			signingKey = key
+		}
+	}
+
+	if signingKey == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return signingKey, nil
+}
+
+// SigningKey returns key signing new tokens
+func (r *KeyRing) SigningKey() (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.signingKey == nil {
+		return nil, ErrNoSigningKey
+	}
+	return r.signingKey, nil
+}
+
+// VerificationKey returns key by kid header of token
+func (r *KeyRing) VerificationKey(id string) (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// JWKS returns public keys ordered by id. Symmetric keys are never published
+func (r *KeyRing) JWKS() []JWK {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jwks := make([]JWK, 0, len(r.keys))
+	for _, key := range r.keys {
+		jwk := JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+
+		switch publicKey := key.VerifyKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		default:
+			continue
+		}
+
+		jwks = append(jwks, jwk)
+	}
+
+	sort.Slice(jwks, func(i, j int) bool {
+		return jwks[i].KeyID < jwks[j].KeyID
+	})
+
+	return jwks
+}
+
+// loadKey reads PEM encoded Ed25519 or RSA key. Private keys sign and verify, public keys only verify
+func loadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, ErrUnsupportedKey
+	}
+	if err != nil {
+		return nil, errors.Join(ErrUnsupportedKey, err)
+	}
+
+	key := &Key{
+		ID: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+
+	switch typedKey := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.SignKey = typedKey
+		key.VerifyKey = typedKey.Public()
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.VerifyKey = typedKey
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+		key.SignKey = typedKey
+		key.VerifyKey = &typedKey.PublicKey
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+		key.VerifyKey = typedKey
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	if publicKey, ok := key.VerifyKey.(*rsa.PublicKey); ok && publicKey.N.BitLen() < MinRSABits {
+		return nil, fmt.Errorf("%w: RSA key shorter than %d bits", ErrUnsupportedKey, MinRSABits)
+	}
+
+	return key, nil
+}
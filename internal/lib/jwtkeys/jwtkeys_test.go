@@ -0,0 +1,155 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func writeEd25519Key(t *testing.T, dir string, id string) ed25519.PublicKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, id+".pem"), "PRIVATE KEY", der)
+
+	return publicKey
+}
+
+func createKeyRing(dir string, files []string, signingKeyID string) *KeyRing {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(logger, dir, files, signingKeyID, 0)
+}
+
+func TestKeyRing_Reload_NewestPrivateKeySigns(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "2026-01")
+	writeEd25519Key(t, dir, "2026-02")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600))
+
+	keyRing := createKeyRing(dir, nil, "")
+	keyRing.MustRun()
+
+	signingKey, err := keyRing.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, "2026-02", signingKey.ID)
+	require.Equal(t, "EdDSA", signingKey.Method.Alg())
+
+	_, err = keyRing.VerificationKey("2026-01")
+	require.NoError(t, err)
+
+	writeEd25519Key(t, dir, "2026-03")
+	require.NoError(t, keyRing.Reload())
+
+	signingKey, err = keyRing.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, "2026-03", signingKey.ID)
+}
+
+func TestKeyRing_Reload_PublicKeyOnlyVerifies(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "current")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, MinRSABits)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "retired.pem"), "PUBLIC KEY", der)
+
+	keyRing := createKeyRing(dir, nil, "")
+	require.NoError(t, keyRing.Reload())
+
+	signingKey, err := keyRing.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, "current", signingKey.ID)
+
+	retired, err := keyRing.VerificationKey("retired")
+	require.NoError(t, err)
+	require.Nil(t, retired.SignKey)
+	require.Equal(t, "RS256", retired.Method.Alg())
+
+	err = createKeyRing(dir, nil, "retired").Reload()
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestKeyRing_Reload_Error_KeepsKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "current")
+
+	keyRing := createKeyRing(dir, nil, "")
+	require.NoError(t, keyRing.Reload())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("broken"), 0o600))
+	require.ErrorIs(t, keyRing.Reload(), ErrUnsupportedKey)
+
+	signingKey, err := keyRing.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, "current", signingKey.ID)
+}
+
+func TestKeyRing_Reload_Error_ShortRSAKey(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	path := filepath.Join(dir, "short.pem")
+	writePEM(t, path, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	err = createKeyRing("", []string{path}, "").Reload()
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	publicKey := writeEd25519Key(t, dir, "ed")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, MinRSABits)
+	require.NoError(t, err)
+	rsaPath := filepath.Join(t.TempDir(), "rsa.pem")
+	writePEM(t, rsaPath, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	keyRing := createKeyRing(dir, []string{rsaPath}, "")
+	require.NoError(t, keyRing.Reload())
+
+	jwks := keyRing.JWKS()
+
+	require.Len(t, jwks, 2)
+	require.Equal(t, JWK{
+		KeyType:   "OKP",
+		KeyID:     "ed",
+		Use:       "sig",
+		Algorithm: "EdDSA",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(publicKey),
+	}, jwks[0])
+	require.Equal(t, "RSA", jwks[1].KeyType)
+	require.Equal(t, "rsa", jwks[1].KeyID)
+	require.Equal(t, "RS256", jwks[1].Algorithm)
+	require.Equal(t, "AQAB", jwks[1].E)
+}
+
+func TestKeyRing_JWKS_HMACNotPublished(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	keyRing := NewHMAC(logger, []byte("secret"))
+
+	signingKey, err := keyRing.SigningKey()
+	require.NoError(t, err)
+	require.Equal(t, "HS256", signingKey.Method.Alg())
+	require.Empty(t, keyRing.JWKS())
+}
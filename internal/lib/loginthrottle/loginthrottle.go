@@ -0,0 +1,125 @@
+// Package loginthrottle implements failed login counters with exponential backoff and lockout
+package loginthrottle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxBackoffShift keeps backoff doubling from overflow
+const maxBackoffShift = 30
+
+// purgeInterval minimal period between purges of stale memory counters
+const purgeInterval = time.Minute
+
+// Policy limits failed logins of single key.
+// Every failure delays the next attempt by BackoffBase doubled per failure up to BackoffMax.
+// Reaching Threshold failures locks the key for LockoutDuration.
+// Failures older than LockoutDuration are forgotten
+type Policy struct {
+	Threshold       int
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	LockoutDuration time.Duration
+}
+
+// Attempts failed logins of single key
+type Attempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Fail returns attempts after failed login at now
+func (p Policy) Fail(attempts Attempts, now time.Time) Attempts {
+	if now.Sub(attempts.LastFailureAt) > p.LockoutDuration {
+		attempts.Failures = 0
+	}
+
+	attempts.Failures++
+	attempts.LastFailureAt = now
+
+	if p.Threshold > 0 && attempts.Failures >= p.Threshold {
+		attempts.Failures = 0
+		attempts.LockedUntil = now.Add(p.LockoutDuration)
+		return attempts
+	}
+
+	attempts.LockedUntil = now.Add(p.backoff(attempts.Failures))
+	return attempts
+}
+
+// Stale reports whether attempts neither lock nor count anymore
+func (p Policy) Stale(attempts Attempts, now time.Time) bool {
+	return !now.Before(attempts.LockedUntil) && now.Sub(attempts.LastFailureAt) > p.LockoutDuration
+}
+
+func (p Policy) backoff(failures int) time.Duration {
+	if p.BackoffBase <= 0 {
+		return 0
+	}
+
+	shift := failures - 1
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+
+	delay := p.BackoffBase << shift
+	if p.BackoffMax > 0 && (delay > p.BackoffMax || delay <= 0) {
+		delay = p.BackoffMax
+	}
+	return delay
+}
+
+// MemoryCounter keeps failed login counters in process memory
+type MemoryCounter struct {
+	mu        sync.Mutex
+	attempts  map[string]Attempts
+	lastPurge time.Time
+}
+
+// NewMemoryCounter creates empty counter
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{
+		attempts: make(map[string]Attempts),
+	}
+}
+
+// GetLoginLock implements authService.ILoginAttemptCounter
+func (c *MemoryCounter) GetLoginLock(ctx context.Context, key string) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.attempts[key].LockedUntil, nil
+}
+
+// RegisterLoginFailure implements authService.ILoginAttemptCounter
+func (c *MemoryCounter) RegisterLoginFailure(ctx context.Context, key string, policy Policy) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastPurge) > purgeInterval {
+		for staleKey, attempts := range c.attempts {
+			if policy.Stale(attempts, now) {
+				delete(c.attempts, staleKey)
+			}
+		}
+		c.lastPurge = now
+	}
+
+	attempts := policy.Fail(c.attempts[key], now)
+	c.attempts[key] = attempts
+
+	return attempts.LockedUntil, nil
+}
+
+// ResetLoginFailures implements authService.ILoginAttemptCounter
+func (c *MemoryCounter) ResetLoginFailures(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.attempts, key)
+	return nil
+}
@@ -0,0 +1,80 @@
+package loginthrottle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	Threshold:       4,
+	BackoffBase:     time.Second,
+	BackoffMax:      3 * time.Second,
+	LockoutDuration: time.Minute,
+}
+
+func TestPolicy_Fail_BackoffThenLockout(t *testing.T) {
+	now := time.Now()
+
+	expectedDelays := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		3 * time.Second,
+		time.Minute,
+	}
+
+	var attempts Attempts
+	for i, expected := range expectedDelays {
+		attempts = testPolicy.Fail(attempts, now)
+		require.Equal(t, now.Add(expected), attempts.LockedUntil, "failure %d", i+1)
+	}
+
+	require.Equal(t, 0, attempts.Failures)
+}
+
+func TestPolicy_Fail_ForgetsOldFailures(t *testing.T) {
+	now := time.Now()
+
+	attempts := Attempts{
+		Failures:      3,
+		LastFailureAt: now.Add(-2 * time.Minute),
+	}
+
+	attempts = testPolicy.Fail(attempts, now)
+
+	require.Equal(t, 1, attempts.Failures)
+	require.Equal(t, now.Add(time.Second), attempts.LockedUntil)
+}
+
+func TestPolicy_Stale(t *testing.T) {
+	now := time.Now()
+
+	require.False(t, testPolicy.Stale(Attempts{LastFailureAt: now, LockedUntil: now.Add(time.Second)}, now))
+	require.False(t, testPolicy.Stale(Attempts{LastFailureAt: now.Add(-time.Second)}, now))
+	require.True(t, testPolicy.Stale(Attempts{LastFailureAt: now.Add(-2 * time.Minute)}, now))
+}
+
+func TestMemoryCounter(t *testing.T) {
+	ctx := context.Background()
+	counter := NewMemoryCounter()
+
+	lockedUntil, err := counter.RegisterLoginFailure(ctx, "user:test", testPolicy)
+	require.NoError(t, err)
+	require.True(t, lockedUntil.After(time.Now()))
+
+	lock, err := counter.GetLoginLock(ctx, "user:test")
+	require.NoError(t, err)
+	require.Equal(t, lockedUntil, lock)
+
+	lock, err = counter.GetLoginLock(ctx, "user:other")
+	require.NoError(t, err)
+	require.True(t, lock.IsZero())
+
+	require.NoError(t, counter.ResetLoginFailures(ctx, "user:test"))
+
+	lock, err = counter.GetLoginLock(ctx, "user:test")
+	require.NoError(t, err)
+	require.True(t, lock.IsZero())
+}
@@ -0,0 +1,92 @@
+// Package notifier delivers account notifications to users.
+// Default implementations write notifications to the service log or a file for delivery by other tools
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrDelivery = errors.New("notifier: delivery error")
+
+// LogNotifier writes notifications to the service log
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLog creates notifier writing to log
+func NewLog(log *slog.Logger) *LogNotifier {
+	return &LogNotifier{
+		logger: log.With(slog.String("module", "logNotifier")),
+	}
+}
+
+// NotifyPasswordReset implements accountService.IPasswordResetNotifier
+func (n *LogNotifier) NotifyPasswordReset(ctx context.Context, username string, token string, expiresAt time.Time) error {
+	n.logger.Info(
+		"password reset requested",
+		slog.String("username", username),
+		slog.String("token", token),
+		slog.Time("expires_at", expiresAt),
+	)
+	return nil
+}
+
+// Notification line written by FileNotifier
+type Notification struct {
+	Kind      string    `json:"kind"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FileNotifier appends notifications to file as JSON lines
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates notifier appending to file at path. File is created on first notification
+func NewFile(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// NotifyPasswordReset implements accountService.IPasswordResetNotifier
+func (n *FileNotifier) NotifyPasswordReset(ctx context.Context, username string, token string, expiresAt time.Time) error {
+	return n.write(Notification{
+		Kind:      "password_reset",
+		Username:  username,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (n *FileNotifier) write(notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return errors.Join(ErrDelivery, err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Join(ErrDelivery, err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return errors.Join(ErrDelivery, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier_NotifyPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier := NewFile(path)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, notifier.NotifyPasswordReset(ctx, "user1", "token1", expiresAt))
+	require.NoError(t, notifier.NotifyPasswordReset(ctx, "user2", "token2", expiresAt))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var notifications []Notification
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification Notification
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &notification))
+		notifications = append(notifications, notification)
+	}
+
+	require.Len(t, notifications, 2)
+	require.Equal(t, "password_reset", notifications[0].Kind)
+	require.Equal(t, "user2", notifications[1].Username)
+	require.Equal(t, "token2", notifications[1].Token)
+	require.True(t, expiresAt.Equal(notifications[1].ExpiresAt))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
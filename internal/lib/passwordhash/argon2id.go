@@ -0,0 +1,139 @@
+package passwordhash
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Default argon2id parameters, RFC 9106 second recommended option with fewer threads
+const (
+	DefaultArgon2Time      = 3
+	DefaultArgon2MemoryKiB = 64 * 1024
+	DefaultArgon2Threads   = 2
+	argon2SaltLength       = 16
+	argon2KeyLength        = 32
+)
+
+const argon2Prefix = "$argon2id$"
+
+// Argon2id hashes passwords with argon2id. Hashes are encoded in PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type Argon2id struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+// argon2Hash decoded PHC string
+type argon2Hash struct {
+	version   int
+	time      uint32
+	memoryKiB uint32
+	threads   uint8
+	salt      []byte
+	key       []byte
+}
+
+func (a Argon2id) params() Argon2id {
+	if a.Time == 0 {
+		a.Time = DefaultArgon2Time
+	}
+	if a.MemoryKiB == 0 {
+		a.MemoryKiB = DefaultArgon2MemoryKiB
+	}
+	if a.Threads == 0 {
+		a.Threads = DefaultArgon2Threads
+	}
+	return a
+}
+
+func (a Argon2id) Hash(password []byte) ([]byte, error) {
+	params := a.params()
+
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey(password, salt, params.Time, params.MemoryKiB, params.Threads, argon2KeyLength)
+
+	return []byte(fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix,
+		argon2.Version,
+		params.MemoryKiB,
+		params.Time,
+		params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+func (a Argon2id) Identifies(hash []byte) bool {
+	return bytes.HasPrefix(hash, []byte(argon2Prefix))
+}
+
+func (a Argon2id) Verify(hash []byte, password []byte) error {
+	decoded, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return err
+	}
+
+	key := argon2.IDKey(password, decoded.salt, decoded.time, decoded.memoryKiB, decoded.threads, uint32(len(decoded.key)))
+	if subtle.ConstantTimeCompare(key, decoded.key) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+func (a Argon2id) Weaker(hash []byte) bool {
+	params := a.params()
+
+	decoded, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return decoded.version < argon2.Version ||
+		decoded.time < params.Time ||
+		decoded.memoryKiB < params.MemoryKiB ||
+		len(decoded.key) < argon2KeyLength
+}
+
+func decodeArgon2Hash(hash []byte) (*argon2Hash, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHash
+	}
+
+	var decoded argon2Hash
+	_, err := fmt.Sscanf(parts[2], "v=%d", &decoded.version)
+	if err != nil {
+		return nil, ErrUnknownHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &decoded.memoryKiB, &decoded.time, &decoded.threads)
+	if err != nil || decoded.time == 0 || decoded.threads == 0 {
+		return nil, ErrUnknownHash
+	}
+
+	decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, ErrUnknownHash
+	}
+
+	decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(decoded.key) == 0 {
+		return nil, ErrUnknownHash
+	}
+
+	return &decoded, nil
+}
@@ -0,0 +1,49 @@
+package passwordhash
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt hashes passwords with bcrypt of Cost
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+func (b Bcrypt) Hash(password []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, b.cost())
+}
+
+func (b Bcrypt) Identifies(hash []byte) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if bytes.HasPrefix(hash, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Bcrypt) Verify(hash []byte, password []byte) error {
+	err := bcrypt.CompareHashAndPassword(hash, password)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	if err != nil {
+		return errors.Join(ErrUnknownHash, err)
+	}
+	return nil
+}
+
+func (b Bcrypt) Weaker(hash []byte) bool {
+	cost, err := bcrypt.Cost(hash)
+	return err != nil || cost < b.cost()
+}
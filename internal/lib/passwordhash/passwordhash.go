@@ -0,0 +1,98 @@
+// Package passwordhash hashes passwords with bcrypt or argon2id.
+// Hashes of every supported algorithm are verified, new hashes use the configured one,
+// so stored hashes migrate on login
+package passwordhash
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	ErrMismatch         = errors.New("password hash: password does not match")
+	ErrUnknownHash      = errors.New("password hash: unknown hash format")
+	ErrUnknownAlgorithm = errors.New("password hash: unknown algorithm")
+)
+
+// Algorithm single hashing scheme with its parameters
+type Algorithm interface {
+	// Hash returns encoded hash of password
+	Hash(password []byte) ([]byte, error)
+	// Identifies reports whether encoded hash is made by the algorithm
+	Identifies(hash []byte) bool
+	// Verify returns ErrMismatch if password does not match hash
+	Verify(hash []byte, password []byte) error
+	// Weaker reports whether hash was made with weaker parameters than the algorithm's
+	Weaker(hash []byte) bool
+}
+
+// Params selects algorithm of new hashes
+type Params struct {
+	Algorithm       string
+	BcryptCost      int
+	Argon2Time      uint32
+	Argon2MemoryKiB uint32
+	Argon2Threads   uint8
+}
+
+// Hasher hashes passwords with current algorithm and verifies hashes of every known one
+type Hasher struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+// New creates hasher using algorithm of params for new hashes
+func New(params Params) (*Hasher, error) {
+	bcryptAlgorithm := Bcrypt{Cost: params.BcryptCost}
+	argon2Algorithm := Argon2id{
+		Time:      params.Argon2Time,
+		MemoryKiB: params.Argon2MemoryKiB,
+		Threads:   params.Argon2Threads,
+	}
+
+	switch params.Algorithm {
+	case AlgorithmBcrypt:
+		return NewWithAlgorithms(bcryptAlgorithm, argon2Algorithm), nil
+	case AlgorithmArgon2id:
+		return NewWithAlgorithms(argon2Algorithm, bcryptAlgorithm), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, params.Algorithm)
+	}
+}
+
+// NewWithAlgorithms creates hasher hashing with current and verifying hashes of current and others
+func NewWithAlgorithms(current Algorithm, others ...Algorithm) *Hasher {
+	return &Hasher{
+		current: current,
+		known:   append([]Algorithm{current}, others...),
+	}
+}
+
+// Hash returns hash of password made by current algorithm
+func (h *Hasher) Hash(password string) ([]byte, error) {
+	return h.current.Hash([]byte(password))
+}
+
+// Verify checks password against hash. Returns ErrMismatch for wrong password.
+// rehash reports that hash is made by other algorithm or weaker parameters and should be replaced
+func (h *Hasher) Verify(hash []byte, password string) (rehash bool, err error) {
+	for _, algorithm := range h.known {
+		if !algorithm.Identifies(hash) {
+			continue
+		}
+
+		err = algorithm.Verify(hash, []byte(password))
+		if err != nil {
+			return false, err
+		}
+
+		return algorithm != h.current || h.current.Weaker(hash), nil
+	}
+
+	return false, ErrUnknownHash
+}
@@ -0,0 +1,98 @@
+package passwordhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2 cheap parameters keeping tests fast
+var testArgon2 = Argon2id{Time: 1, MemoryKiB: 1024, Threads: 1}
+
+func TestHasher_Verify(t *testing.T) {
+	testCases := []struct {
+		name           string
+		stored         Algorithm
+		current        Algorithm
+		expectedRehash bool
+	}{
+		{
+			name:    "bcrypt current cost",
+			stored:  Bcrypt{Cost: bcrypt.MinCost},
+			current: Bcrypt{Cost: bcrypt.MinCost},
+		},
+		{
+			name:           "bcrypt lower cost",
+			stored:         Bcrypt{Cost: bcrypt.MinCost},
+			current:        Bcrypt{Cost: bcrypt.MinCost + 1},
+			expectedRehash: true,
+		},
+		{
+			name:           "bcrypt to argon2id",
+			stored:         Bcrypt{Cost: bcrypt.MinCost},
+			current:        testArgon2,
+			expectedRehash: true,
+		},
+		{
+			name:    "argon2id current parameters",
+			stored:  testArgon2,
+			current: testArgon2,
+		},
+		{
+			name:           "argon2id less memory",
+			stored:         testArgon2,
+			current:        Argon2id{Time: 1, MemoryKiB: 2048, Threads: 1},
+			expectedRehash: true,
+		},
+		{
+			name:           "argon2id to bcrypt",
+			stored:         testArgon2,
+			current:        Bcrypt{Cost: bcrypt.MinCost},
+			expectedRehash: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			hash, err := testCase.stored.Hash([]byte("Correct7Horse"))
+			require.NoError(t, err)
+
+			var other Algorithm = testArgon2
+			if _, ok := testCase.current.(Argon2id); ok {
+				other = Bcrypt{Cost: bcrypt.MinCost}
+			}
+			hasher := NewWithAlgorithms(testCase.current, other)
+
+			rehash, err := hasher.Verify(hash, "Correct7Horse")
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedRehash, rehash)
+
+			_, err = hasher.Verify(hash, "wrong")
+			require.ErrorIs(t, err, ErrMismatch)
+		})
+	}
+}
+
+func TestArgon2id_Hash_Format(t *testing.T) {
+	hash, err := testArgon2.Hash([]byte("Correct7Horse"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=1024,t=1,p=1$"))
+}
+
+func TestHasher_Verify_Error_UnknownHash(t *testing.T) {
+	hasher, err := New(Params{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	_, err = hasher.Verify([]byte("plain"), "plain")
+	require.ErrorIs(t, err, ErrUnknownHash)
+
+	_, err = hasher.Verify([]byte("$argon2id$v=19$broken"), "plain")
+	require.ErrorIs(t, err, ErrUnknownHash)
+}
+
+func TestNew_Error_UnknownAlgorithm(t *testing.T) {
+	_, err := New(Params{Algorithm: "md5"})
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
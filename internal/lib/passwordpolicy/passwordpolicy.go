@@ -0,0 +1,99 @@
+// Package passwordpolicy implements password strength rules
+package passwordpolicy
+
+import (
+	_ "embed"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength bcrypt ignores password bytes after this limit
+const MaxLength = 72
+
+var (
+	ErrTooShort       = errors.New("password is too short")
+	ErrTooLong        = errors.New("password is too long")
+	ErrMissingUpper   = errors.New("password must contain an upper case letter")
+	ErrMissingLower   = errors.New("password must contain a lower case letter")
+	ErrMissingDigit   = errors.New("password must contain a digit")
+	ErrMissingSymbol  = errors.New("password must contain a symbol")
+	ErrCommonPassword = errors.New("password is too common")
+)
+
+//go:embed common-passwords.txt
+var commonPasswordsList string
+
+// commonPasswords lower case passwords from the bundled blocklist
+var commonPasswords = func() map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswordsList, "\n") {
+		password := strings.TrimSpace(line)
+		if password == "" || strings.HasPrefix(password, "#") {
+			continue
+		}
+		passwords[strings.ToLower(password)] = struct{}{}
+	}
+	return passwords
+}()
+
+// Policy password requirements
+type Policy struct {
+	// MinLength in characters
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// BlockCommon rejects passwords from the bundled blocklist regardless of case
+	BlockCommon bool
+}
+
+// Validate returns all rules password breaks joined in one error. Nil if password is accepted
+func (p Policy) Validate(password string) error {
+	var errs []error
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		errs = append(errs, ErrTooShort)
+	}
+
+	if len(password) > MaxLength {
+		errs = append(errs, ErrTooLong)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		errs = append(errs, ErrMissingUpper)
+	}
+	if p.RequireLower && !hasLower {
+		errs = append(errs, ErrMissingLower)
+	}
+	if p.RequireDigit && !hasDigit {
+		errs = append(errs, ErrMissingDigit)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		errs = append(errs, ErrMissingSymbol)
+	}
+
+	if p.BlockCommon {
+		if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+			errs = append(errs, ErrCommonPassword)
+		}
+	}
+
+	return errors.Join(errs...)
+}
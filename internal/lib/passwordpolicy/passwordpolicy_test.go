@@ -0,0 +1,69 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	policy := Policy{
+		MinLength:    8,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		BlockCommon:  true,
+	}
+
+	testCases := []struct {
+		name           string
+		password       string
+		expectedErrors []error
+	}{
+		{
+			name:     "strong password",
+			password: "Correct7Horse",
+		},
+		{
+			name:           "too short",
+			password:       "Ab1",
+			expectedErrors: []error{ErrTooShort},
+		},
+		{
+			name:           "too long for bcrypt",
+			password:       "Aa1" + strings.Repeat("x", MaxLength),
+			expectedErrors: []error{ErrTooLong},
+		},
+		{
+			name:           "missing character classes",
+			password:       "lowercaseonly",
+			expectedErrors: []error{ErrMissingUpper, ErrMissingDigit},
+		},
+		{
+			name:           "common password in another case",
+			password:       "Password1",
+			expectedErrors: []error{ErrCommonPassword},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := policy.Validate(testCase.password)
+			if len(testCase.expectedErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, expectedError := range testCase.expectedErrors {
+				require.ErrorIs(t, err, expectedError)
+			}
+		})
+	}
+}
+
+func TestPolicy_Validate_Symbol(t *testing.T) {
+	policy := Policy{RequireSymbol: true}
+
+	require.ErrorIs(t, policy.Validate("NoSymbols1"), ErrMissingSymbol)
+	require.NoError(t, policy.Validate("with symbol!"))
+}
@@ -0,0 +1,419 @@
+// Package rrule implements a subset of iCalendar (RFC 5545) recurrence rules:
+// DAILY, WEEKLY, MONTHLY and YEARLY frequency with INTERVAL, BYDAY, COUNT and UNTIL.
+// Occurrences keep the wall clock time of the series start in its location across DST changes
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// zone database is embedded, the service image has no zoneinfo
+	_ "time/tzdata"
+)
+
+// Frequency period of rule
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+const (
+	untilLayout     = "20060102T150405Z"
+	untilDateLayout = "20060102"
+	// maxEmptyPeriods periods without occurrences after which series is considered finished
+	maxEmptyPeriods = 1000
+)
+
+var ErrInvalidRule = errors.New("rrule: invalid rule")
+
+// Weekday BYDAY entry. Non-zero N selects N-th weekday of month, negative N counts from the month end
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule recurrence rule. The series start is always its first occurrence
+type Rule struct {
+	Freq Frequency
+	// Interval of periods between occurrences, one if zero
+	Interval int
+	// ByDay weekdays of occurrences. Ordinal weekdays are allowed with Monthly frequency only,
+	// Yearly frequency does not support ByDay
+	ByDay []Weekday
+	// Count of occurrences including the series start, zero is unlimited
+	Count int
+	// Until last allowed occurrence time, zero is unlimited
+	Until time.Time
+	// UntilDate compares only dates of occurrences in the start location with Until
+	UntilDate bool
+}
+
+// Parse parses RRULE value like FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10. Optional "RRULE:" prefix is skipped
+func Parse(value string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return rule, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return rule, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			for freq, name := range frequencyNames {
+				if name == val {
+					rule.Freq = freq
+				}
+			}
+			if rule.Freq == 0 {
+				err = fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positiveInt(key, val)
+		case "COUNT":
+			rule.Count, err = positiveInt(key, val)
+		case "UNTIL":
+			rule.Until, err = time.Parse(untilLayout, val)
+			if err != nil {
+				rule.Until, err = time.Parse(untilDateLayout, val)
+				rule.UntilDate = true
+			}
+			if err != nil {
+				err = fmt.Errorf("%w: UNTIL must be UTC date-time or date", ErrInvalidRule)
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "WKST":
+			if val != "MO" {
+				err = fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRule)
+			}
+		default:
+			err = fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, key)
+		}
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	return rule, rule.Validate()
+}
+
+func positiveInt(key string, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: %s must be positive integer", ErrInvalidRule, key)
+	}
+	return n, nil
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var result []Weekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("%w: malformed BYDAY %q", ErrInvalidRule, item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidRule, item)
+		}
+
+		weekday := Weekday{Day: day}
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("%w: BYDAY ordinal must be from -5 to 5 except zero", ErrInvalidRule)
+			}
+			weekday.N = n
+		}
+		result = append(result, weekday)
+	}
+	return result, nil
+}
+
+// Validate checks that rule is supported
+func (r Rule) Validate() error {
+	if r.Freq < Daily || r.Freq > Yearly {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Interval < 0 || r.Count < 0 {
+		return fmt.Errorf("%w: INTERVAL and COUNT must be positive", ErrInvalidRule)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("%w: COUNT and UNTIL are exclusive", ErrInvalidRule)
+	}
+	if r.Freq == Yearly && len(r.ByDay) > 0 {
+		return fmt.Errorf("%w: BYDAY is not supported with YEARLY", ErrInvalidRule)
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.N != 0 && r.Freq != Monthly {
+			return fmt.Errorf("%w: BYDAY ordinal requires MONTHLY", ErrInvalidRule)
+		}
+	}
+	return nil
+}
+
+// String returns rule in RRULE value form without prefix
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + frequencyNames[r.Freq]}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			day := strings.ToUpper(weekday.Day.String()[:2])
+			if weekday.N != 0 {
+				day = strconv.Itoa(weekday.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+		}
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Occurrences returns up to limit occurrences of series started at start which are later than after
+func (r Rule) Occurrences(start time.Time, after time.Time, limit int) []time.Time {
+	var result []time.Time
+	if limit < 1 {
+		return result
+	}
+
+	r.iterate(start, func(occurrence time.Time) bool {
+		if occurrence.After(after) {
+			result = append(result, occurrence)
+		}
+		return len(result) < limit
+	})
+	return result
+}
+
+// Next returns the second occurrence of series started at start and rule of series continuing from it.
+// Returns false if start is the last occurrence
+func (r Rule) Next(start time.Time) (time.Time, Rule, bool) {
+	occurrences := r.Occurrences(start, start, 1)
+	if len(occurrences) == 0 {
+		return time.Time{}, r, false
+	}
+
+	if r.Count > 0 {
+		r.Count--
+	}
+	return occurrences[0], r, true
+}
+
+// iterate calls yield with occurrences in order until it returns false or series ends
+func (r Rule) iterate(start time.Time, yield func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	count := 1
+	if !r.allowed(start) || !yield(start) || r.Count == count {
+		return
+	}
+
+	for period, empty := 0, 0; empty < maxEmptyPeriods; period += interval {
+		found := false
+		for _, occurrence := range r.periodOccurrences(start, period) {
+			if !occurrence.After(start) {
+				continue
+			}
+			if !r.allowed(occurrence) {
+				return
+			}
+
+			found = true
+			count++
+			if !yield(occurrence) || r.Count == count {
+				return
+			}
+		}
+
+		if found {
+			empty = 0
+		} else {
+			empty++
+		}
+	}
+}
+
+// allowed checks occurrence against Until
+func (r Rule) allowed(occurrence time.Time) bool {
+	if r.Until.IsZero() {
+		return true
+	}
+	if r.UntilDate {
+		year, month, day := occurrence.Date()
+		return !time.Date(year, month, day, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+	return !occurrence.After(r.Until)
+}
+
+// periodOccurrences returns sorted occurrences of period shifted from the start one by offset periods
+func (r Rule) periodOccurrences(start time.Time, offset int) []time.Time {
+	var dates []time.Time
+	switch r.Freq {
+	case Daily:
+		date := dateOf(start).AddDate(0, 0, offset)
+		if len(r.ByDay) == 0 || r.hasWeekday(date.Weekday()) {
+			dates = append(dates, date)
+		}
+	case Weekly:
+		date := dateOf(start)
+		monday := date.AddDate(0, 0, 7*offset-daysSinceMonday(date.Weekday()))
+		if len(r.ByDay) == 0 {
+			dates = append(dates, monday.AddDate(0, 0, daysSinceMonday(date.Weekday())))
+		}
+		for _, weekday := range r.ByDay {
+			dates = append(dates, monday.AddDate(0, 0, daysSinceMonday(weekday.Day)))
+		}
+	case Monthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		if len(r.ByDay) == 0 {
+			dates = appendValidDate(dates, month.Year(), month.Month(), start.Day())
+		}
+		for _, weekday := range r.ByDay {
+			dates = append(dates, monthWeekdays(month, weekday)...)
+		}
+	case Yearly:
+		dates = appendValidDate(dates, start.Year()+offset, start.Month(), start.Day())
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	hour, minute, second := start.Clock()
+	result := make([]time.Time, 0, len(dates))
+	for i, date := range dates {
+		if i > 0 && date.Equal(dates[i-1]) {
+			continue
+		}
+		result = append(result, localTime(
+			date.Year(), date.Month(), date.Day(),
+			hour, minute, second, start.Nanosecond(),
+			start.Location(),
+		))
+	}
+	return result
+}
+
+func (r Rule) hasWeekday(day time.Weekday) bool {
+	for _, weekday := range r.ByDay {
+		if weekday.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// daysSinceMonday returns position of day in week starting on Monday
+func daysSinceMonday(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// dateOf returns date of t in its location as UTC midnight
+func dateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// appendValidDate appends date if day exists in month
+func appendValidDate(dates []time.Time, year int, month time.Month, day int) []time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != month {
+		return dates
+	}
+	return append(dates, date)
+}
+
+// monthWeekdays returns dates of weekday in month, all of them when weekday.N is zero
+func monthWeekdays(month time.Time, weekday Weekday) []time.Time {
+	var dates []time.Time
+	first := month.AddDate(0, 0, (int(weekday.Day)-int(month.Weekday())+7)%7)
+	for date := first; date.Month() == month.Month(); date = date.AddDate(0, 0, 7) {
+		dates = append(dates, date)
+	}
+
+	switch {
+	case weekday.N == 0:
+		return dates
+	case weekday.N > 0 && weekday.N <= len(dates):
+		return dates[weekday.N-1 : weekday.N]
+	case weekday.N < 0 && -weekday.N <= len(dates):
+		return dates[len(dates)+weekday.N : len(dates)+weekday.N+1]
+	default:
+		return nil
+	}
+}
+
+// localTime returns instant of wall clock time in loc as RFC 5545 defines it:
+// time skipped by DST change is shifted by the change and repeated time is its first instance
+func localTime(year int, month time.Month, day, hour, minute, second, nanosecond int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, minute, second, nanosecond, time.UTC)
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	var result time.Time
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		instant := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, actual := instant.Zone(); actual != offset {
+			continue
+		}
+		if result.IsZero() || instant.Before(result) {
+			result = instant
+		}
+	}
+
+	if result.IsZero() {
+		result = wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	}
+	return result
+}
@@ -0,0 +1,280 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{value: "rrule:freq=weekly;interval=2;byday=MO,we", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{value: "FREQ=WEEKLY;WKST=MO;INTERVAL=1", expected: "FREQ=WEEKLY"},
+		{value: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", expected: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{value: "FREQ=MONTHLY;BYDAY=+2TU,SU", expected: "FREQ=MONTHLY;BYDAY=2TU,SU"},
+		{value: "FREQ=YEARLY;UNTIL=20301231T235959Z", expected: "FREQ=YEARLY;UNTIL=20301231T235959Z"},
+		{value: "FREQ=DAILY;UNTIL=20261231", expected: "FREQ=DAILY;UNTIL=20261231"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.value, func(t *testing.T) {
+			rule, err := Parse(testCase.value)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, rule.String())
+
+			reparsed, err := Parse(rule.String())
+			require.NoError(t, err)
+			require.Equal(t, rule, reparsed)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"FREQ",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"FREQ=DAILY;UNTIL=2026-01-01",
+		"FREQ=DAILY;UNTIL=20260101T000000",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ=DAILY;WKST=SU",
+	} {
+		t.Run(value, func(t *testing.T) {
+			_, err := Parse(value)
+			require.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestRule_Occurrences(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	testCases := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "daily interval",
+			rule:     "FREQ=DAILY;INTERVAL=2",
+			start:    time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC),
+			limit:    4,
+			expected: []string{"2026-01-30T09:00:00Z", "2026-02-01T09:00:00Z", "2026-02-03T09:00:00Z", "2026-02-05T09:00:00Z"},
+		},
+		{
+			name:     "daily on weekdays",
+			rule:     "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start:    time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC),
+			limit:    3,
+			expected: []string{"2026-01-30T09:00:00Z", "2026-02-02T09:00:00Z", "2026-02-03T09:00:00Z"},
+		},
+		{
+			name:     "weekly on start weekday",
+			rule:     "FREQ=WEEKLY",
+			start:    time.Date(2026, 1, 4, 10, 0, 0, 0, time.UTC),
+			limit:    3,
+			expected: []string{"2026-01-04T10:00:00Z", "2026-01-11T10:00:00Z", "2026-01-18T10:00:00Z"},
+		},
+		{
+			name:  "every other week on days",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE,MO",
+			start: time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC),
+			limit: 5,
+			expected: []string{
+				"2026-01-05T10:00:00Z", "2026-01-07T10:00:00Z",
+				"2026-01-19T10:00:00Z", "2026-01-21T10:00:00Z",
+				"2026-02-02T10:00:00Z",
+			},
+		},
+		{
+			name:     "start not matching days is first occurrence",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,FR",
+			start:    time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC),
+			limit:    4,
+			expected: []string{"2026-01-07T10:00:00Z", "2026-01-09T10:00:00Z", "2026-01-12T10:00:00Z", "2026-01-16T10:00:00Z"},
+		},
+		{
+			name:     "monthly skips months without day",
+			rule:     "FREQ=MONTHLY",
+			start:    time.Date(2026, 1, 31, 8, 0, 0, 0, time.UTC),
+			limit:    4,
+			expected: []string{"2026-01-31T08:00:00Z", "2026-03-31T08:00:00Z", "2026-05-31T08:00:00Z", "2026-07-31T08:00:00Z"},
+		},
+		{
+			name:     "monthly on last friday",
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR",
+			start:    time.Date(2026, 1, 30, 8, 0, 0, 0, time.UTC),
+			limit:    4,
+			expected: []string{"2026-01-30T08:00:00Z", "2026-02-27T08:00:00Z", "2026-03-27T08:00:00Z", "2026-04-24T08:00:00Z"},
+		},
+		{
+			name:     "monthly on second tuesday",
+			rule:     "FREQ=MONTHLY;BYDAY=2TU",
+			start:    time.Date(2026, 1, 13, 8, 0, 0, 0, time.UTC),
+			limit:    3,
+			expected: []string{"2026-01-13T08:00:00Z", "2026-02-10T08:00:00Z", "2026-03-10T08:00:00Z"},
+		},
+		{
+			name:     "monthly skips months without fifth monday",
+			rule:     "FREQ=MONTHLY;BYDAY=5MO",
+			start:    time.Date(2026, 3, 30, 8, 0, 0, 0, time.UTC),
+			limit:    3,
+			expected: []string{"2026-03-30T08:00:00Z", "2026-06-29T08:00:00Z", "2026-08-31T08:00:00Z"},
+		},
+		{
+			name:     "yearly on leap day",
+			rule:     "FREQ=YEARLY",
+			start:    time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+			limit:    3,
+			expected: []string{"2024-02-29T12:00:00Z", "2028-02-29T12:00:00Z", "2032-02-29T12:00:00Z"},
+		},
+		{
+			name:     "count",
+			rule:     "FREQ=DAILY;COUNT=3",
+			start:    time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			limit:    10,
+			expected: []string{"2026-01-01T09:00:00Z", "2026-01-02T09:00:00Z", "2026-01-03T09:00:00Z"},
+		},
+		{
+			name:     "until is inclusive",
+			rule:     "FREQ=DAILY;UNTIL=20260103T090000Z",
+			start:    time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			limit:    10,
+			expected: []string{"2026-01-01T09:00:00Z", "2026-01-02T09:00:00Z", "2026-01-03T09:00:00Z"},
+		},
+		{
+			name:     "until date in start location",
+			rule:     "FREQ=DAILY;UNTIL=20260102",
+			start:    time.Date(2026, 1, 1, 23, 0, 0, 0, tokyo),
+			limit:    10,
+			expected: []string{"2026-01-01T23:00:00+09:00", "2026-01-02T23:00:00+09:00"},
+		},
+		{
+			name:     "wall clock kept across spring forward",
+			rule:     "FREQ=DAILY",
+			start:    time.Date(2026, 3, 7, 9, 0, 0, 0, newYork),
+			limit:    3,
+			expected: []string{"2026-03-07T09:00:00-05:00", "2026-03-08T09:00:00-04:00", "2026-03-09T09:00:00-04:00"},
+		},
+		{
+			name:     "time skipped by spring forward is shifted",
+			rule:     "FREQ=DAILY",
+			start:    time.Date(2026, 3, 7, 2, 30, 0, 0, newYork),
+			limit:    3,
+			expected: []string{"2026-03-07T02:30:00-05:00", "2026-03-08T03:30:00-04:00", "2026-03-09T02:30:00-04:00"},
+		},
+		{
+			name:     "time repeated by fall back is first instance",
+			rule:     "FREQ=DAILY",
+			start:    time.Date(2026, 10, 31, 1, 30, 0, 0, newYork),
+			limit:    3,
+			expected: []string{"2026-10-31T01:30:00-04:00", "2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		},
+		{
+			name:     "weekly across spring forward east of UTC",
+			rule:     "FREQ=WEEKLY",
+			start:    time.Date(2026, 3, 22, 2, 30, 0, 0, berlin),
+			limit:    3,
+			expected: []string{"2026-03-22T02:30:00+01:00", "2026-03-29T03:30:00+02:00", "2026-04-05T02:30:00+02:00"},
+		},
+		{
+			name:     "time repeated by fall back east of UTC is first instance",
+			rule:     "FREQ=DAILY",
+			start:    time.Date(2026, 10, 24, 2, 30, 0, 0, berlin),
+			limit:    3,
+			expected: []string{"2026-10-24T02:30:00+02:00", "2026-10-25T02:30:00+02:00", "2026-10-26T02:30:00+01:00"},
+		},
+		{
+			name:     "monthly across fall back",
+			rule:     "FREQ=MONTHLY",
+			start:    time.Date(2026, 10, 15, 8, 0, 0, 0, newYork),
+			limit:    2,
+			expected: []string{"2026-10-15T08:00:00-04:00", "2026-11-15T08:00:00-05:00"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			require.NoError(t, err)
+
+			occurrences := rule.Occurrences(testCase.start, testCase.start.Add(-time.Nanosecond), testCase.limit)
+
+			actual := make([]string, 0, len(occurrences))
+			for _, occurrence := range occurrences {
+				actual = append(actual, occurrence.Format(time.RFC3339))
+			}
+			require.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestRule_Occurrences_After(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=5")
+	require.NoError(t, err)
+
+	start := time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)
+	occurrences := rule.Occurrences(start, time.Date(2026, 1, 13, 9, 0, 0, 0, time.UTC), 10)
+
+	require.Equal(t, []time.Time{
+		time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 20, 9, 0, 0, 0, time.UTC),
+	}, occurrences)
+
+	require.Empty(t, rule.Occurrences(start, start, 0))
+}
+
+func TestRule_Next(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=2")
+	require.NoError(t, err)
+
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	next, rest, ok := rule.Next(start)
+	require.True(t, ok)
+	require.Equal(t, start.AddDate(0, 0, 1), next)
+	require.Equal(t, "FREQ=DAILY;COUNT=1", rest.String())
+
+	_, _, ok = rest.Next(next)
+	require.False(t, ok)
+
+	rule, err = Parse("FREQ=MONTHLY;UNTIL=20260115T000000Z")
+	require.NoError(t, err)
+
+	_, _, ok = rule.Next(start)
+	require.False(t, ok)
+}
+
+func TestRule_Occurrences_LongGap(t *testing.T) {
+	rule, err := Parse("FREQ=YEARLY;INTERVAL=100")
+	require.NoError(t, err)
+
+	start := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)
+	require.Equal(t,
+		[]time.Time{time.Date(2400, 2, 29, 0, 0, 0, 0, time.UTC)},
+		rule.Occurrences(start, start, 1),
+	)
+}
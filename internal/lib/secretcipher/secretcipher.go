@@ -0,0 +1,78 @@
+// Package secretcipher encrypts secrets stored in database with AES-256-GCM
+package secretcipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// KeySize length of key in bytes
+const KeySize = 32
+
+var (
+	ErrKeySize    = errors.New("secret cipher: key must be 32 bytes")
+	ErrCiphertext = errors.New("secret cipher: malformed or tampered ciphertext")
+)
+
+// Cipher encrypts and authenticates secrets
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates cipher with 32 bytes key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// NewFromBase64 creates cipher with standard base64 encoded key
+func NewFromBase64(key string) (*Cipher, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.Join(ErrKeySize, err)
+	}
+	return New(decoded)
+}
+
+// Encrypt returns random nonce followed by sealed plaintext.
+// additionalData binds ciphertext to its owner and must be passed to Decrypt
+func (c *Cipher) Encrypt(plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens ciphertext made by Encrypt
+func (c *Cipher) Decrypt(ciphertext []byte, additionalData []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrCiphertext
+	}
+
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrCiphertext
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,36 @@
+package secretcipher
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte("secret"), []byte("user:1"))
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), "secret")
+
+	plaintext, err := c.Decrypt(ciphertext, []byte("user:1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), plaintext)
+
+	_, err = c.Decrypt(ciphertext, []byte("user:2"))
+	require.ErrorIs(t, err, ErrCiphertext)
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = c.Decrypt(ciphertext, []byte("user:1"))
+	require.ErrorIs(t, err, ErrCiphertext)
+}
+
+func TestNew_Error_KeySize(t *testing.T) {
+	_, err := New([]byte("short"))
+	require.ErrorIs(t, err, ErrKeySize)
+
+	_, err = NewFromBase64("not base64")
+	require.ErrorIs(t, err, ErrKeySize)
+}
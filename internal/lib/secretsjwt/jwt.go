@@ -3,56 +3,98 @@ package secretsjwt
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"log/slog"
 	"time"
 
 	configApp "github.com/IldarGaleev/todo-backend-service/internal/app/configapp"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/jwtkeys"
 	secretsDTO "github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt/secretsdto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// refreshTokenSize count of random bytes in refresh token
+const refreshTokenSize = 32
+
+// validMethods signing algorithms accepted in alg header
+var validMethods = []string{
+	jwt.SigningMethodEdDSA.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodHS256.Alg(),
+}
+
 var (
 	ErrVerifyError = errors.New("jwt verify error")
 	ErrCreateError = errors.New("jwt create error")
+	// ErrRefreshReused refresh token was already used. Its session is revoked
+	ErrRefreshReused   = errors.New("jwt refresh token reused")
+	ErrSessionNotFound = errors.New("jwt session not found")
+	ErrRevokeError     = errors.New("jwt revoke error")
 )
 
 type IJWTIndexer interface {
-	CreateNewID(ctx context.Context) uint64
+	CreateNewJWTID(ctx context.Context) (uint64, error)
 }
 
 type IJWTRevoker interface {
-	IsJWTRevoked(ctx context.Context, id uint64) bool
-	RevokeJWT(ctx context.Context, id uint64)
+	IsJWTRevoked(ctx context.Context, id uint64) (bool, error)
+	RevokeJWT(ctx context.Context, id uint64, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error
+	RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error)
+}
+
+// IKeyRing provides keys for signing and verifying tokens
+type IKeyRing interface {
+	SigningKey() (*jwtkeys.Key, error)
+	VerificationKey(id string) (*jwtkeys.Key, error)
+	JWKS() []jwtkeys.JWK
+}
+
+// IRefreshTokenStorage keeps hashes of refresh tokens
+type IRefreshTokenStorage interface {
+	CreateRefreshToken(ctx context.Context, token storageDTO.RefreshToken, tokenHash []byte) error
+	UseRefreshToken(ctx context.Context, tokenHash []byte) (*storageDTO.RefreshToken, error)
 }
 
 type SecretJWT struct {
-	logger     *slog.Logger
-	maxAge     time.Duration
-	secretKey  []byte
-	jwtIndexer IJWTIndexer
-	jwtRevoker IJWTRevoker
+	logger        *slog.Logger
+	maxAge        time.Duration
+	refreshMaxAge time.Duration
+	keys          IKeyRing
+	jwtIndexer    IJWTIndexer
+	jwtRevoker    IJWTRevoker
+	refreshTokens IRefreshTokenStorage
 }
 
 type TokenClaims struct {
-	UserID   uint64 `json:"userid"`
-	Username string `json:"username"`
-	TokenID  uint64 `json:"tokenid"`
+	UserID    uint64 `json:"userid"`
+	Username  string `json:"username"`
+	TokenID   uint64 `json:"tokenid"`
+	SessionID uint64 `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func New(
 	log *slog.Logger,
 	config configApp.AppConfig,
+	keys IKeyRing,
 	jwtIndexer IJWTIndexer,
 	jwtRevoker IJWTRevoker,
+	refreshTokens IRefreshTokenStorage,
 ) *SecretJWT {
 	return &SecretJWT{
-		logger:     log.With(slog.String("module", "secretsJwt")),
-		maxAge:     config.SecretsMaxAge,
-		secretKey:  config.SecretKey,
-		jwtIndexer: jwtIndexer,
-		jwtRevoker: jwtRevoker,
+		logger:        log.With(slog.String("module", "secretsJwt")),
+		maxAge:        config.SecretsMaxAge,
+		refreshMaxAge: config.RefreshTokenMaxAge,
+		keys:          keys,
+		jwtIndexer:    jwtIndexer,
+		jwtRevoker:    jwtRevoker,
+		refreshTokens: refreshTokens,
 	}
 }
 
@@ -61,8 +103,17 @@ func (s *SecretJWT) decodeToken(secret []byte) (*TokenClaims, error) {
 		string(secret),
 		&TokenClaims{},
 		func(token *jwt.Token) (interface{}, error) {
-			return s.secretKey, nil
+			keyID, _ := token.Header["kid"].(string)
+			key, err := s.keys.VerificationKey(keyID)
+			if err != nil {
+				return nil, err
+			}
+			if token.Method.Alg() != key.Method.Alg() {
+				return nil, ErrVerifyError
+			}
+			return key.VerifyKey, nil
 		},
+		jwt.WithValidMethods(validMethods),
 	)
 
 	if err != nil {
@@ -85,42 +136,145 @@ func (s *SecretJWT) ValidateSecret(ctx context.Context, secret []byte) (*secrets
 		return nil, ErrVerifyError
 	}
 
-	if s.jwtRevoker.IsJWTRevoked(ctx, claims.TokenID) {
+	revoked, err := s.jwtRevoker.IsJWTRevoked(ctx, claims.TokenID)
+	if err != nil {
+		log.Error("jwt revocation check error", slog.Any("err", err))
+		return nil, ErrVerifyError
+	}
+
+	if revoked {
 		return nil, ErrVerifyError
 	}
 
-	return &secretsDTO.User{
+	user := &secretsDTO.User{
 		UserID:   &claims.UserID,
 		Username: &claims.Username,
-	}, nil
+	}
+	if claims.SessionID != 0 {
+		user.SessionID = &claims.SessionID
+	}
+
+	return user, nil
 
 }
 
-func (s *SecretJWT) CreateSecret(ctx context.Context, user secretsDTO.User) ([]byte, error) {
+// CreateSecret issues first token pair of user session
+func (s *SecretJWT) CreateSecret(ctx context.Context, user secretsDTO.User) (*secretsDTO.TokenPair, error) {
 	log := s.logger.With(slog.String("method", "CreateSecret"))
 
+	if user.SessionID == nil {
+		log.Error("session id is not set")
+		return nil, ErrCreateError
+	}
+
+	return s.createTokenPair(ctx, log, *user.UserID, *user.Username, *user.SessionID)
+}
+
+// RefreshSecret exchanges refresh token for new token pair of the same session.
+// Reusing refresh token revokes the session
+func (s *SecretJWT) RefreshSecret(ctx context.Context, refreshToken []byte) (*secretsDTO.TokenPair, error) {
+	log := s.logger.With(slog.String("method", "RefreshSecret"))
+
+	token, err := s.refreshTokens.UseRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrTokenReused):
+			log.Warn("refresh token reused, session revoked")
+			return nil, ErrRefreshReused
+		case errors.Is(err, storage.ErrNotFound):
+			return nil, ErrVerifyError
+		}
+		log.Error("refresh token use error", slog.Any("err", err))
+		return nil, ErrVerifyError
+	}
+
+	return s.createTokenPair(ctx, log, token.UserID, token.Username, token.FamilyID)
+}
+
+func (s *SecretJWT) createTokenPair(
+	ctx context.Context,
+	log *slog.Logger,
+	userID uint64,
+	username string,
+	sessionID uint64,
+) (*secretsDTO.TokenPair, error) {
+	tokenID, err := s.jwtIndexer.CreateNewJWTID(ctx)
+	if err != nil {
+		log.Error("jwt id create error", slog.Any("err", err))
+		return nil, ErrCreateError
+	}
+
+	now := time.Now()
+	accessExpiresAt := now.Add(s.maxAge)
+
 	claims := TokenClaims{
-		UserID:   *user.UserID,
-		Username: *user.Username,
-		TokenID:  s.jwtIndexer.CreateNewID(ctx),
+		UserID:    userID,
+		Username:  username,
+		TokenID:   tokenID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.maxAge)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(accessExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Subject:   "user",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key, err := s.keys.SigningKey()
+	if err != nil {
+		log.Error("jwt signing key error", slog.Any("err", err))
+		return nil, ErrCreateError
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
 
-	tokenString, err := token.SignedString(s.secretKey)
+	tokenString, err := token.SignedString(key.SignKey)
 
 	if err != nil {
 		log.Debug("jwt create error", slog.Any("err", err))
 		return nil, ErrCreateError
 	}
 
-	return []byte(tokenString), nil
+	refreshToken := make([]byte, refreshTokenSize)
+	_, err = rand.Read(refreshToken)
+	if err != nil {
+		log.Error("refresh token generate error", slog.Any("err", err))
+		return nil, ErrCreateError
+	}
+	refreshString := base64.RawURLEncoding.EncodeToString(refreshToken)
+	refreshExpiresAt := now.Add(s.refreshMaxAge)
+
+	err = s.refreshTokens.CreateRefreshToken(
+		ctx,
+		storageDTO.RefreshToken{
+			FamilyID:        sessionID,
+			UserID:          userID,
+			AccessTokenID:   tokenID,
+			AccessExpiresAt: accessExpiresAt,
+			ExpiresAt:       refreshExpiresAt,
+		},
+		hashRefreshToken([]byte(refreshString)),
+	)
+	if err != nil {
+		log.Error("refresh token store error", slog.Any("err", err))
+		return nil, ErrCreateError
+	}
+
+	return &secretsDTO.TokenPair{
+		AccessToken:      []byte(tokenString),
+		AccessExpiresAt:  claims.ExpiresAt.Time,
+		RefreshToken:     []byte(refreshString),
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// hashRefreshToken returns hash of refresh token kept in storage
+func hashRefreshToken(refreshToken []byte) []byte {
+	hash := sha256.Sum256(refreshToken)
+	return hash[:]
 }
 
 func (s *SecretJWT) DeleteSecret(ctx context.Context, secret []byte) error {
@@ -132,9 +286,99 @@ func (s *SecretJWT) DeleteSecret(ctx context.Context, secret []byte) error {
 		log.Debug("jwt parse error", slog.Any("err", err))
 		return ErrVerifyError
 	}
-	if s.jwtRevoker.IsJWTRevoked(ctx, claims.TokenID) {
+
+	revoked, err := s.jwtRevoker.IsJWTRevoked(ctx, claims.TokenID)
+	if err != nil {
+		log.Error("jwt revocation check error", slog.Any("err", err))
+		return ErrVerifyError
+	}
+
+	if revoked {
+		return ErrVerifyError
+	}
+
+	expiresAt := time.Now().Add(s.maxAge)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	err = s.jwtRevoker.RevokeJWT(ctx, claims.TokenID, expiresAt)
+	if err != nil {
+		log.Error("jwt revoke error", slog.Any("err", err))
 		return ErrVerifyError
 	}
-	s.jwtRevoker.RevokeJWT(ctx, claims.TokenID)
+
+	if claims.SessionID != 0 {
+		err = s.jwtRevoker.RevokeSession(ctx, claims.UserID, claims.SessionID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Error("session revoke error", slog.Any("err", err))
+			return ErrVerifyError
+		}
+	}
+
+	return nil
+}
+
+// RevokeSession revokes session of user with every token issued for it
+func (s *SecretJWT) RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error {
+	log := s.logger.With(slog.String("method", "RevokeSession"))
+
+	err := s.jwtRevoker.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		log.Error("session revoke error", slog.Any("err", err))
+		return ErrRevokeError
+	}
+
 	return nil
 }
+
+// RevokeOtherSessions revokes every session of user except sessionID and returns count of revoked sessions
+func (s *SecretJWT) RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error) {
+	log := s.logger.With(slog.String("method", "RevokeOtherSessions"))
+
+	count, err := s.jwtRevoker.RevokeOtherSessions(ctx, userID, sessionID)
+	if err != nil {
+		log.Error("sessions revoke error", slog.Any("err", err))
+		return 0, ErrRevokeError
+	}
+
+	return count, nil
+}
+
+// RevokeUserSessions revokes every session of user and returns count of revoked sessions
+func (s *SecretJWT) RevokeUserSessions(ctx context.Context, userID uint64) (int64, error) {
+	log := s.logger.With(slog.String("method", "RevokeUserSessions"))
+
+	// session ids start from 1, so no session is kept
+	count, err := s.jwtRevoker.RevokeOtherSessions(ctx, userID, 0)
+	if err != nil {
+		log.Error("sessions revoke error", slog.Any("err", err))
+		return 0, ErrRevokeError
+	}
+
+	return count, nil
+}
+
+// PublicKeys returns keys verifying issued tokens
+func (s *SecretJWT) PublicKeys(ctx context.Context) []secretsDTO.PublicKey {
+	jwks := s.keys.JWKS()
+
+	keys := make([]secretsDTO.PublicKey, 0, len(jwks))
+	for _, jwk := range jwks {
+		keys = append(keys, secretsDTO.PublicKey{
+			KeyType:   jwk.KeyType,
+			KeyID:     jwk.KeyID,
+			Use:       jwk.Use,
+			Algorithm: jwk.Algorithm,
+			Curve:     jwk.Curve,
+			X:         jwk.X,
+			N:         jwk.N,
+			E:         jwk.E,
+		})
+	}
+
+	return keys
+}
@@ -0,0 +1,156 @@
+package secretsjwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configApp "github.com/IldarGaleev/todo-backend-service/internal/app/configapp"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/jwtkeys"
+	secretsDTO "github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt/secretsdto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTokenStorage struct {
+	lastID uint64
+}
+
+func (f *fakeTokenStorage) CreateNewJWTID(ctx context.Context) (uint64, error) {
+	f.lastID++
+	return f.lastID, nil
+}
+
+func (f *fakeTokenStorage) IsJWTRevoked(ctx context.Context, id uint64) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeTokenStorage) RevokeJWT(ctx context.Context, id uint64, expiresAt time.Time) error {
+	return nil
+}
+
+func (f *fakeTokenStorage) CreateRefreshToken(ctx context.Context, token storageDTO.RefreshToken, tokenHash []byte) error {
+	return nil
+}
+
+func (f *fakeTokenStorage) UseRefreshToken(ctx context.Context, tokenHash []byte) (*storageDTO.RefreshToken, error) {
+	return nil, nil
+}
+
+func (f *fakeTokenStorage) RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error {
+	return nil
+}
+
+func (f *fakeTokenStorage) RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error) {
+	return 0, nil
+}
+
+func createSecretJWT(t *testing.T) (*SecretJWT, ed25519.PublicKey) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "main.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	require.NoError(t, err)
+
+	keyRing := jwtkeys.New(logger, dir, nil, "", 0)
+	require.NoError(t, keyRing.Reload())
+
+	tokenStorage := &fakeTokenStorage{}
+	secrets := New(
+		logger,
+		configApp.AppConfig{SecretsMaxAge: time.Minute, RefreshTokenMaxAge: time.Hour},
+		keyRing,
+		tokenStorage,
+		tokenStorage,
+		tokenStorage,
+	)
+
+	return secrets, publicKey
+}
+
+func TestSecretJWT_CreateSecret_SignedWithKeyID(t *testing.T) {
+	ctx := context.Background()
+	secrets, _ := createSecretJWT(t)
+
+	userID := uint64(1)
+	username := "test_user"
+	sessionID := uint64(5)
+	tokens, err := secrets.CreateSecret(ctx, secretsDTO.User{UserID: &userID, Username: &username, SessionID: &sessionID})
+	require.NoError(t, err)
+
+	token, _, err := jwt.NewParser().ParseUnverified(string(tokens.AccessToken), &TokenClaims{})
+	require.NoError(t, err)
+	require.Equal(t, "EdDSA", token.Method.Alg())
+	require.Equal(t, "main", token.Header["kid"])
+
+	user, err := secrets.ValidateSecret(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, userID, *user.UserID)
+	require.Equal(t, username, *user.Username)
+	require.Equal(t, sessionID, *user.SessionID)
+}
+
+func TestSecretJWT_ValidateSecret_Error_AlgorithmMismatch(t *testing.T) {
+	ctx := context.Background()
+	secrets, publicKey := createSecretJWT(t)
+
+	claims := TokenClaims{
+		UserID:   1,
+		Username: "test_user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		method jwt.SigningMethod
+		keyID  string
+		key    interface{}
+	}{
+		{
+			name:   "HS256 signed with public key",
+			method: jwt.SigningMethodHS256,
+			keyID:  "main",
+			key:    []byte(publicKey),
+		},
+		{
+			name:   "unsigned",
+			method: jwt.SigningMethodNone,
+			keyID:  "main",
+			key:    jwt.UnsafeAllowNoneSignatureType,
+		},
+		{
+			name:   "unknown key",
+			method: jwt.SigningMethodHS256,
+			keyID:  "",
+			key:    []byte("secret"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			token := jwt.NewWithClaims(testCase.method, claims)
+			token.Header["kid"] = testCase.keyID
+			tokenString, err := token.SignedString(testCase.key)
+			require.NoError(t, err)
+
+			_, err = secrets.ValidateSecret(ctx, []byte(tokenString))
+			require.ErrorIs(t, err, ErrVerifyError)
+		})
+	}
+}
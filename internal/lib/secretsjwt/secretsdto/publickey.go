@@ -0,0 +1,13 @@
+package secretsdto
+
+// PublicKey key verifying tokens in JSON Web Key format
+type PublicKey struct {
+	KeyType   string
+	KeyID     string
+	Use       string
+	Algorithm string
+	Curve     string
+	X         string
+	N         string
+	E         string
+}
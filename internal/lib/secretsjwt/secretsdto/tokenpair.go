@@ -0,0 +1,11 @@
+package secretsdto
+
+import "time"
+
+// TokenPair short-lived access token with single-use refresh token renewing it
+type TokenPair struct {
+	AccessToken      []byte
+	AccessExpiresAt  time.Time
+	RefreshToken     []byte
+	RefreshExpiresAt time.Time
+}
@@ -2,7 +2,8 @@
 package secretsdto
 
 type User struct {
-	UserID   *uint64
-	Username *string
-	Payload  interface{}
+	UserID    *uint64
+	Username  *string
+	SessionID *uint64
+	Payload   interface{}
 }
\ No newline at end of file
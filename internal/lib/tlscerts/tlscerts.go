@@ -0,0 +1,220 @@
+// Package tlscerts implements TLS configuration of the gRPC and HTTP servers.
+// Certificate, key and client CA files are reloaded periodically, so certificates rotate without restart
+package tlscerts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownVersion  = errors.New("tlscerts: unknown TLS version")
+	ErrInvalidClientCA = errors.New("tlscerts: no certificates in client CA file")
+)
+
+// versions accepted minimal TLS versions
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseVersion returns TLS version constant of "1.2" or "1.3"
+func ParseVersion(version string) (uint16, error) {
+	value, ok := versions[version]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownVersion, version)
+	}
+	return value, nil
+}
+
+// Options of TLS server.
+// Client certificates are verified against ClientCAFile when it is set,
+// RequireClientCert rejects clients without certificate
+type Options struct {
+	CertFile          string
+	KeyFile           string
+	ClientCAFile      string
+	RequireClientCert bool
+	MinVersion        uint16
+	ReloadInterval    time.Duration
+}
+
+// Store keeps server certificate and client CA pool loaded from files
+type Store struct {
+	logger  *slog.Logger
+	options Options
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	modTimes    map[string]time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates certificate store. Files are loaded by MustRun
+func New(log *slog.Logger, options Options) *Store {
+	return &Store{
+		logger:  log.With(slog.String("module", "tlsCerts")),
+		options: options,
+	}
+}
+
+// MustRun loads files and starts periodic reload. Panic if failed
+func (s *Store) MustRun() {
+	if err := s.Reload(); err != nil {
+		panic(err)
+	}
+
+	if s.options.ReloadInterval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.watch()
+}
+
+// Stop stops periodic reload
+func (s *Store) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Store) watch() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Reload(); err != nil {
+				s.logger.Error("certificates reload error, previous certificates kept", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// Reload replaces certificate and client CA pool when any file changed on disk.
+// Previous certificates are kept if any file is invalid
+func (s *Store) Reload() error {
+	modTimes, err := s.fileModTimes()
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	changed := !equalModTimes(s.modTimes, modTimes)
+	s.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(s.options.CertFile, s.options.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if s.options.ClientCAFile != "" {
+		data, err := os.ReadFile(s.options.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s: %w", s.options.ClientCAFile, ErrInvalidClientCA)
+		}
+	}
+
+	s.mu.Lock()
+	s.certificate = &certificate
+	s.clientCAs = clientCAs
+	s.modTimes = modTimes
+	s.mu.Unlock()
+
+	s.logger.Info("certificates loaded", slog.Bool("mtls", clientCAs != nil))
+
+	return nil
+}
+
+func (s *Store) fileModTimes() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range []string{s.options.CertFile, s.options.KeyFile, s.options.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func equalModTimes(a map[string]time.Time, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, modTime := range a {
+		if !modTime.Equal(b[path]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServerConfig returns TLS configuration which takes certificates of the latest reload on every handshake
+func (s *Store) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: s.minVersion(),
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.handshakeConfig(), nil
+		},
+	}
+}
+
+func (s *Store) minVersion() uint16 {
+	if s.options.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return s.options.MinVersion
+}
+
+func (s *Store) handshakeConfig() *tls.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config := &tls.Config{
+		MinVersion: s.minVersion(),
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	if s.certificate != nil {
+		config.Certificates = []tls.Certificate{*s.certificate}
+	}
+
+	if s.clientCAs != nil {
+		config.ClientCAs = s.clientCAs
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if s.options.RequireClientCert {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return config
+}
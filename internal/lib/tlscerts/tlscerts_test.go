@@ -0,0 +1,235 @@
+package tlscerts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPEM     []byte
+	keyPEM      []byte
+}
+
+func createCert(t *testing.T, name string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		certificate: certificate,
+		key:         key,
+		certPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:      pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeCert(t *testing.T, dir string, name string, cert *testCert, modTime time.Time) {
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, cert.certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, cert.keyPEM, 0o600))
+	require.NoError(t, os.Chtimes(certPath, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyPath, modTime, modTime))
+}
+
+// handshake connects client to server config and returns certificate presented by server
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (*x509.Certificate, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, serverConfig).Handshake()
+	}()
+
+	client, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		<-serverErr
+		return nil, err
+	}
+	defer client.Close()
+
+	if err := <-serverErr; err != nil {
+		return nil, err
+	}
+	return client.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestStore_Reload_ChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := createCert(t, "ca", 1, nil)
+	writeCert(t, dir, "server", createCert(t, "server", 2, ca), time.Now().Add(-time.Minute))
+
+	store := New(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	})
+	store.MustRun()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	clientConfig := &tls.Config{RootCAs: roots, ServerName: "server"}
+
+	certificate, err := handshake(t, store.ServerConfig(), clientConfig)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), certificate.SerialNumber.Int64())
+
+	writeCert(t, dir, "server", createCert(t, "server", 3, ca), time.Now())
+	require.NoError(t, store.Reload())
+
+	certificate, err = handshake(t, store.ServerConfig(), clientConfig)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), certificate.SerialNumber.Int64())
+}
+
+func TestStore_Reload_Error_KeepsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := createCert(t, "ca", 1, nil)
+	writeCert(t, dir, "server", createCert(t, "server", 2, ca), time.Now().Add(-time.Minute))
+
+	store := New(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	})
+	store.MustRun()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.key"), []byte("broken"), 0o600))
+	require.Error(t, store.Reload())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	certificate, err := handshake(t, store.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "server"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), certificate.SerialNumber.Int64())
+}
+
+func TestStore_ServerConfig_ClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := createCert(t, "ca", 1, nil)
+	otherCA := createCert(t, "other-ca", 1, nil)
+	writeCert(t, dir, "server", createCert(t, "server", 2, ca), time.Now())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), ca.certPEM, 0o600))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+
+	clientCertificate := func(cert *testCert) []tls.Certificate {
+		pair, err := tls.X509KeyPair(cert.certPEM, cert.keyPEM)
+		require.NoError(t, err)
+		return []tls.Certificate{pair}
+	}
+
+	testCases := []struct {
+		name              string
+		requireClientCert bool
+		clientCerts       []tls.Certificate
+		expectedError     bool
+	}{
+		{
+			name:        "trusted client certificate",
+			clientCerts: clientCertificate(createCert(t, "worker", 4, ca)),
+		},
+		{
+			name: "no client certificate",
+		},
+		{
+			name:              "no client certificate when required",
+			requireClientCert: true,
+			expectedError:     true,
+		},
+		{
+			name:          "untrusted client certificate",
+			clientCerts:   clientCertificate(createCert(t, "worker", 5, otherCA)),
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			store := New(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+				CertFile:          filepath.Join(dir, "server.crt"),
+				KeyFile:           filepath.Join(dir, "server.key"),
+				ClientCAFile:      filepath.Join(dir, "ca.crt"),
+				RequireClientCert: testCase.requireClientCert,
+				MinVersion:        tls.VersionTLS13,
+			})
+			store.MustRun()
+
+			_, err := handshake(t, store.ServerConfig(), &tls.Config{
+				RootCAs:    roots,
+				ServerName: "server",
+				// sends the certificate even if server does not accept its issuer
+				GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					if len(testCase.clientCerts) == 0 {
+						return &tls.Certificate{}, nil
+					}
+					return &testCase.clientCerts[0], nil
+				},
+			})
+
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	version, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = ParseVersion("1.0")
+	require.ErrorIs(t, err, ErrUnknownVersion)
+}
@@ -0,0 +1,59 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret SHA1 secret of RFC 6238 test vectors
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode_RFC6238(t *testing.T) {
+	testCases := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "94287082"},
+		{unix: 1111111109, expected: "07081804"},
+		{unix: 1111111111, expected: "14050471"},
+		{unix: 1234567890, expected: "89005924"},
+		{unix: 2000000000, expected: "69279037"},
+		{unix: 20000000000, expected: "65353130"},
+	}
+
+	for _, testCase := range testCases {
+		step := Step(time.Unix(testCase.unix, 0))
+		require.Equal(t, testCase.expected, code(rfcSecret, step, 8))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	step, ok := Validate(rfcSecret, Code(rfcSecret, current), now)
+	require.True(t, ok)
+	require.Equal(t, current, step)
+
+	step, ok = Validate(rfcSecret, Code(rfcSecret, current-1), now)
+	require.True(t, ok)
+	require.Equal(t, current-1, step)
+
+	_, ok = Validate(rfcSecret, Code(rfcSecret, current+2), now)
+	require.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Todo App", "user@example.com", rfcSecret)
+
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Todo%20App:user@example.com?"))
+	require.Contains(t, uri, "secret="+EncodeSecret(rfcSecret))
+	require.Contains(t, uri, "issuer=Todo+App")
+	require.Contains(t, uri, "digits=6")
+}
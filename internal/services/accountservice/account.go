@@ -0,0 +1,304 @@
+// Package accountservice implements user accounts operations
+package accountservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordpolicy"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+type IAccountCreator interface {
+	CreateAccount(ctx context.Context, username string, passwordHash []byte) (*serviceDTO.User, error)
+}
+
+type IAccountGetter interface {
+	GetAccountByUsername(ctx context.Context, username string) (*storageDTO.User, error)
+	GetAccountByID(ctx context.Context, userID uint64) (*storageDTO.User, error)
+}
+
+type IAccountUpdater interface {
+	UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash []byte) error
+	ResetPassword(ctx context.Context, tokenHash []byte, passwordHash []byte) (uint64, error)
+}
+
+type IPasswordResetCreator interface {
+	CreatePasswordResetToken(ctx context.Context, userID uint64, tokenHash []byte, expiresAt time.Time) error
+}
+
+// IPasswordHasher hashes passwords with the configured algorithm
+type IPasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Verify(hash []byte, password string) (rehash bool, err error)
+}
+
+// IPasswordResetNotifier delivers password reset token to user
+type IPasswordResetNotifier interface {
+	NotifyPasswordReset(ctx context.Context, username string, token string, expiresAt time.Time) error
+}
+
+// ISessionRevoker ends every session of user after password change
+type ISessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID uint64) (int64, error)
+}
+
+type AccountService struct {
+	logger               *slog.Logger
+	accountCreator       IAccountCreator
+	accountGetter        IAccountGetter
+	accountUpdater       IAccountUpdater
+	passwordResetCreator IPasswordResetCreator
+	resetNotifier        IPasswordResetNotifier
+	sessionRevoker       ISessionRevoker
+	passwordPolicy       passwordpolicy.Policy
+	passwordHasher       IPasswordHasher
+	registrationEnabled  bool
+	resetTokenMaxAge     time.Duration
+}
+
+// MaxUsernameLength username limit in characters
+const MaxUsernameLength = 40
+
+// resetTokenSize length of password reset token in random bytes
+const resetTokenSize = 32
+
+var (
+	ErrRegistrationDisabled = errors.New("account service: registration is disabled")
+	ErrInvalidUsername      = errors.New("account service: invalid username")
+	ErrWeakPassword         = errors.New("account service: password does not match policy")
+	ErrAlreadyExists        = errors.New("account service: username is taken")
+	ErrNotFound             = errors.New("account service: account not found")
+	ErrWrongPassword        = errors.New("account service: wrong password")
+	// ErrInvalidResetToken reset token is unknown, used or expired
+	ErrInvalidResetToken = errors.New("account service: invalid reset token")
+	ErrInternal          = errors.New("account service: internal error")
+)
+
+func New(
+	log *slog.Logger,
+	accountCreator IAccountCreator,
+	accountGetter IAccountGetter,
+	accountUpdater IAccountUpdater,
+	passwordResetCreator IPasswordResetCreator,
+	resetNotifier IPasswordResetNotifier,
+	sessionRevoker ISessionRevoker,
+	passwordPolicy passwordpolicy.Policy,
+	passwordHasher IPasswordHasher,
+	registrationEnabled bool,
+	resetTokenMaxAge time.Duration,
+) *AccountService {
+	return &AccountService{
+		logger:               log.With(slog.String("module", "accountService")),
+		accountCreator:       accountCreator,
+		accountGetter:        accountGetter,
+		accountUpdater:       accountUpdater,
+		passwordResetCreator: passwordResetCreator,
+		resetNotifier:        resetNotifier,
+		sessionRevoker:       sessionRevoker,
+		passwordPolicy:       passwordPolicy,
+		passwordHasher:       passwordHasher,
+		registrationEnabled:  registrationEnabled,
+		resetTokenMaxAge:     resetTokenMaxAge,
+	}
+}
+
+// hashResetToken returns hash of reset token kept in storage
+func hashResetToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// hashPassword checks password against policy and returns its hash
+func (s *AccountService) hashPassword(log *slog.Logger, password string) ([]byte, error) {
+	err := s.passwordPolicy.Validate(password)
+	if err != nil {
+		return nil, errors.Join(ErrWeakPassword, err)
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		log.Error("password hash error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return passwordHash, nil
+}
+
+// revokeSessions ends sessions of user after password is changed
+func (s *AccountService) revokeSessions(ctx context.Context, log *slog.Logger, userID uint64) error {
+	count, err := s.sessionRevoker.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		log.Error("sessions revoke error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	log.Info("password changed, sessions revoked", slog.Uint64("user_id", userID), slog.Int64("count", count))
+	return nil
+}
+
+// validUsername reports whether username fits users table and has no spaces or control characters
+func validUsername(username string) bool {
+	if username == "" || utf8.RuneCountInString(username) > MaxUsernameLength {
+		return false
+	}
+
+	for _, r := range username {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+
+	return utf8.ValidString(username)
+}
+
+// Register creates account with password matching policy.
+// Returned ErrWeakPassword is joined with the broken passwordpolicy rules
+func (s *AccountService) Register(ctx context.Context, username string, password string) (*serviceDTO.User, error) {
+	log := s.logger.With(slog.String("method", "Register"))
+
+	if !s.registrationEnabled {
+		return nil, ErrRegistrationDisabled
+	}
+
+	username = strings.TrimSpace(username)
+	if !validUsername(username) {
+		return nil, ErrInvalidUsername
+	}
+
+	passwordHash, err := s.hashPassword(log, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.accountCreator.CreateAccount(ctx, username, passwordHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, ErrAlreadyExists
+		}
+		log.Error("create account error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces password of user after checking the current one.
+// Every session of user is revoked, including the caller's
+func (s *AccountService) ChangePassword(
+	ctx context.Context,
+	userID uint64,
+	currentPassword string,
+	newPassword string,
+) error {
+	log := s.logger.With(slog.String("method", "ChangePassword"))
+
+	account, err := s.accountGetter.GetAccountByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrNotFound
+		}
+		log.Error("get account error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	_, err = s.passwordHasher.Verify(account.PasswordHash, currentPassword)
+	if err != nil {
+		return ErrWrongPassword
+	}
+
+	passwordHash, err := s.hashPassword(log, newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.accountUpdater.UpdatePasswordHash(ctx, userID, passwordHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrNotFound
+		}
+		log.Error("password update error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	return s.revokeSessions(ctx, log, userID)
+}
+
+// RequestPasswordReset sends reset token to the account owner through notifier.
+// Unknown usernames are not reported, so accounts can not be enumerated
+func (s *AccountService) RequestPasswordReset(ctx context.Context, username string) error {
+	log := s.logger.With(slog.String("method", "RequestPasswordReset"))
+
+	username = strings.TrimSpace(username)
+	if !validUsername(username) {
+		return ErrInvalidUsername
+	}
+
+	account, err := s.accountGetter.GetAccountByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Debug("password reset of unknown account")
+			return nil
+		}
+		log.Error("get account error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	tokenBytes := make([]byte, resetTokenSize)
+	_, err = rand.Read(tokenBytes)
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	expiresAt := time.Now().Add(s.resetTokenMaxAge)
+
+	err = s.passwordResetCreator.CreatePasswordResetToken(ctx, account.Id, hashResetToken(token), expiresAt)
+	if err != nil {
+		log.Error("reset token create error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	err = s.resetNotifier.NotifyPasswordReset(ctx, account.Username, token, expiresAt)
+	if err != nil {
+		log.Error("reset token delivery error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// ConfirmPasswordReset sets new password of reset token owner. Token works once.
+// Every session of user is revoked
+func (s *AccountService) ConfirmPasswordReset(ctx context.Context, token string, newPassword string) error {
+	log := s.logger.With(slog.String("method", "ConfirmPasswordReset"))
+
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+
+	passwordHash, err := s.hashPassword(log, newPassword)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.accountUpdater.ResetPassword(ctx, hashResetToken(token), passwordHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		log.Error("password reset error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	return s.revokeSessions(ctx, log, userID)
+}
@@ -0,0 +1,247 @@
+package accountservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordhash"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordpolicy"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accountStorage keeps created accounts by username
+type accountStorage struct {
+	hashes      map[string][]byte
+	resetTokens map[string]string
+}
+
+func (s *accountStorage) CreateAccount(_ context.Context, username string, passwordHash []byte) (*serviceDTO.User, error) {
+	if _, ok := s.hashes[username]; ok {
+		return nil, storage.ErrAlreadyExists
+	}
+	s.hashes[username] = passwordHash
+
+	userID := uint64(len(s.hashes))
+	return &serviceDTO.User{UserID: &userID, Username: &username}, nil
+}
+
+func (s *accountStorage) GetAccountByUsername(_ context.Context, username string) (*storageDTO.User, error) {
+	passwordHash, ok := s.hashes[username]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &storageDTO.User{Id: 1, Username: username, PasswordHash: passwordHash}, nil
+}
+
+func (s *accountStorage) GetAccountByID(ctx context.Context, userID uint64) (*storageDTO.User, error) {
+	return s.GetAccountByUsername(ctx, "taken")
+}
+
+func (s *accountStorage) UpdatePasswordHash(_ context.Context, userID uint64, passwordHash []byte) error {
+	s.hashes["taken"] = passwordHash
+	return nil
+}
+
+func (s *accountStorage) ResetPassword(_ context.Context, tokenHash []byte, passwordHash []byte) (uint64, error) {
+	username, ok := s.resetTokens[string(tokenHash)]
+	if !ok {
+		return 0, storage.ErrNotFound
+	}
+	delete(s.resetTokens, string(tokenHash))
+	s.hashes[username] = passwordHash
+	return 1, nil
+}
+
+func (s *accountStorage) CreatePasswordResetToken(_ context.Context, userID uint64, tokenHash []byte, expiresAt time.Time) error {
+	s.resetTokens[string(tokenHash)] = "taken"
+	return nil
+}
+
+// resetNotifier keeps the last delivered reset token
+type resetNotifier struct {
+	username string
+	token    string
+}
+
+func (n *resetNotifier) NotifyPasswordReset(_ context.Context, username string, token string, expiresAt time.Time) error {
+	n.username = username
+	n.token = token
+	return nil
+}
+
+// sessionRevoker counts revocations of user sessions
+type sessionRevoker struct {
+	revoked int
+}
+
+func (r *sessionRevoker) RevokeUserSessions(_ context.Context, userID uint64) (int64, error) {
+	r.revoked++
+	return 1, nil
+}
+
+type accountServiceFakes struct {
+	accounts *accountStorage
+	notifier *resetNotifier
+	revoker  *sessionRevoker
+}
+
+func createAccountService(registrationEnabled bool) (*accountStorage, *AccountService) {
+	fakes, service := createAccountServiceFakes(registrationEnabled)
+	return fakes.accounts, service
+}
+
+func createAccountServiceFakes(registrationEnabled bool) (*accountServiceFakes, *AccountService) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fakes := &accountServiceFakes{
+		accounts: &accountStorage{
+			hashes:      map[string][]byte{"taken": []byte("hash")},
+			resetTokens: map[string]string{},
+		},
+		notifier: &resetNotifier{},
+		revoker:  &sessionRevoker{},
+	}
+
+	service := New(
+		logger,
+		fakes.accounts,
+		fakes.accounts,
+		fakes.accounts,
+		fakes.accounts,
+		fakes.notifier,
+		fakes.revoker,
+		passwordpolicy.Policy{MinLength: 8, RequireDigit: true, BlockCommon: true},
+		passwordhash.NewWithAlgorithms(passwordhash.Bcrypt{Cost: bcrypt.MinCost}),
+		registrationEnabled,
+		time.Hour,
+	)
+
+	return fakes, service
+}
+
+func TestAccountService_Register_Success(t *testing.T) {
+	ctx := context.Background()
+	accounts, service := createAccountService(true)
+
+	user, err := service.Register(ctx, " new_user ", "Correct7Horse")
+
+	require.NoError(t, err)
+	require.Equal(t, "new_user", *user.Username)
+	require.NoError(t, bcrypt.CompareHashAndPassword(accounts.hashes["new_user"], []byte("Correct7Horse")))
+}
+
+func TestAccountService_Register_Errors(t *testing.T) {
+	testCases := []struct {
+		name                string
+		registrationEnabled bool
+		username            string
+		password            string
+		expectedError       error
+	}{
+		{
+			name:          "registration disabled",
+			username:      "new_user",
+			password:      "Correct7Horse",
+			expectedError: ErrRegistrationDisabled,
+		},
+		{
+			name:                "empty username",
+			registrationEnabled: true,
+			username:            "  ",
+			password:            "Correct7Horse",
+			expectedError:       ErrInvalidUsername,
+		},
+		{
+			name:                "username too long",
+			registrationEnabled: true,
+			username:            strings.Repeat("u", MaxUsernameLength+1),
+			password:            "Correct7Horse",
+			expectedError:       ErrInvalidUsername,
+		},
+		{
+			name:                "username with space",
+			registrationEnabled: true,
+			username:            "new user",
+			password:            "Correct7Horse",
+			expectedError:       ErrInvalidUsername,
+		},
+		{
+			name:                "common password",
+			registrationEnabled: true,
+			username:            "new_user",
+			password:            "password1",
+			expectedError:       passwordpolicy.ErrCommonPassword,
+		},
+		{
+			name:                "duplicate username",
+			registrationEnabled: true,
+			username:            "taken",
+			password:            "Correct7Horse",
+			expectedError:       ErrAlreadyExists,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, service := createAccountService(testCase.registrationEnabled)
+
+			user, err := service.Register(ctx, testCase.username, testCase.password)
+
+			require.ErrorIs(t, err, testCase.expectedError)
+			require.Nil(t, user)
+		})
+	}
+}
+
+func TestAccountService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	fakes, service := createAccountServiceFakes(false)
+
+	currentHash, err := bcrypt.GenerateFromPassword([]byte("Current7Horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	fakes.accounts.hashes["taken"] = currentHash
+
+	err = service.ChangePassword(ctx, 1, "wrong", "Battery8Staple")
+	require.ErrorIs(t, err, ErrWrongPassword)
+
+	err = service.ChangePassword(ctx, 1, "Current7Horse", "short")
+	require.ErrorIs(t, err, ErrWeakPassword)
+	require.Equal(t, 0, fakes.revoker.revoked)
+
+	err = service.ChangePassword(ctx, 1, "Current7Horse", "Battery8Staple")
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword(fakes.accounts.hashes["taken"], []byte("Battery8Staple")))
+	require.Equal(t, 1, fakes.revoker.revoked)
+}
+
+func TestAccountService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	fakes, service := createAccountServiceFakes(false)
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "unknown"))
+	require.Empty(t, fakes.notifier.token)
+
+	require.NoError(t, service.RequestPasswordReset(ctx, "taken"))
+	require.Equal(t, "taken", fakes.notifier.username)
+	require.NotEmpty(t, fakes.notifier.token)
+	require.NotContains(t, fakes.accounts.resetTokens, fakes.notifier.token)
+
+	err := service.ConfirmPasswordReset(ctx, fakes.notifier.token, "short")
+	require.ErrorIs(t, err, ErrWeakPassword)
+
+	err = service.ConfirmPasswordReset(ctx, fakes.notifier.token, "Battery8Staple")
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword(fakes.accounts.hashes["taken"], []byte("Battery8Staple")))
+	require.Equal(t, 1, fakes.revoker.revoked)
+
+	err = service.ConfirmPasswordReset(ctx, fakes.notifier.token, "Battery8Staple")
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+}
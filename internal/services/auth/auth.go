@@ -4,50 +4,282 @@ package authservice
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/loginthrottle"
+	secretsJwt "github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt"
 	secretsDTO "github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt/secretsdto"
 	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage"
 	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrArguments   = errors.New("argument error")
 	ErrNotFound    = errors.New("account not found")
 	ErrWrongSecret = errors.New("wrong secret")
-	ErrInternal    = errors.New("internal error")
+	// ErrSecretReused refresh secret was already used. Secrets issued from the same login are revoked
+	ErrSecretReused    = errors.New("secret reused")
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrLocked too many failed logins. Returned as *LockedError
+	ErrLocked = errors.New("too many failed attempts")
+	// ErrMfaUnavailable MFA encryption key is not configured
+	ErrMfaUnavailable = errors.New("mfa is not configured")
+	ErrMfaEnabled     = errors.New("mfa is already enabled")
+	ErrMfaNotEnabled  = errors.New("mfa is not enabled")
+	ErrWrongMfaCode   = errors.New("wrong mfa code")
+	ErrInternal       = errors.New("internal error")
 )
 
+// LockedError login is locked after failed attempts
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLocked, e.RetryAfter)
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// LoginLimits limits failed logins per username and per source address
+type LoginLimits struct {
+	User loginthrottle.Policy
+	IP   loginthrottle.Policy
+}
+
 //go:generate mockery --name IAccountGetter
 type IAccountGetter interface {
 	GetAccountByUsername(ctx context.Context, username string) (*storageDTO.User, error)
 	GetAccountByID(ctx context.Context, userID uint64) (*storageDTO.User, error)
 }
 
+//go:generate mockery --name IAccountUpdater
+type IAccountUpdater interface {
+	UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash []byte) error
+}
+
+// IPasswordHasher verifies password hashes of every supported algorithm
+type IPasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	Verify(hash []byte, password string) (rehash bool, err error)
+}
+
 //go:generate mockery --name ISecretProvider
 type ISecretProvider interface {
-	CreateSecret(ctx context.Context, user secretsDTO.User) ([]byte, error)
+	CreateSecret(ctx context.Context, user secretsDTO.User) (*secretsDTO.TokenPair, error)
+	RefreshSecret(ctx context.Context, refreshToken []byte) (*secretsDTO.TokenPair, error)
 	ValidateSecret(ctx context.Context, secret []byte) (*secretsDTO.User, error)
 	DeleteSecret(ctx context.Context, secret []byte) error
+	PublicKeys(ctx context.Context) []secretsDTO.PublicKey
+	RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error
+	RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error)
 }
 
+//go:generate mockery --name ISessionCreator
+type ISessionCreator interface {
+	CreateSession(ctx context.Context, session storageDTO.Session) (uint64, error)
+}
+
+//go:generate mockery --name ISessionGetter
+type ISessionGetter interface {
+	GetSessions(ctx context.Context, userID uint64) ([]storageDTO.Session, error)
+}
+
+//go:generate mockery --name ISessionUpdater
+type ISessionUpdater interface {
+	TouchSession(ctx context.Context, sessionID uint64, seenAt time.Time, interval time.Duration) error
+}
+
+//go:generate mockery --name IMfaCreator
+type IMfaCreator interface {
+	SetTOTPSecret(ctx context.Context, userID uint64, secret []byte) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes [][]byte) error
+	CreateMfaChallenge(ctx context.Context, challenge storageDTO.MfaChallenge, tokenHash []byte) error
+}
+
+//go:generate mockery --name IMfaGetter
+type IMfaGetter interface {
+	GetTOTP(ctx context.Context, userID uint64) (*storageDTO.TOTP, error)
+	GetMfaChallenge(ctx context.Context, tokenHash []byte) (*storageDTO.MfaChallenge, error)
+}
+
+//go:generate mockery --name IMfaUpdater
+type IMfaUpdater interface {
+	ConfirmTOTP(ctx context.Context, userID uint64, step int64) error
+	UseTOTPStep(ctx context.Context, userID uint64, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash []byte) error
+}
+
+//go:generate mockery --name IMfaDeleter
+type IMfaDeleter interface {
+	DeleteTOTP(ctx context.Context, userID uint64) error
+	DeleteMfaChallenge(ctx context.Context, challengeID uint64) error
+}
+
+// ISecretCipher encrypts stored MFA secrets
+type ISecretCipher interface {
+	Encrypt(plaintext []byte, additionalData []byte) ([]byte, error)
+	Decrypt(ciphertext []byte, additionalData []byte) ([]byte, error)
+}
+
+// ILoginAttemptCounter keeps failed login counters
+type ILoginAttemptCounter interface {
+	GetLoginLock(ctx context.Context, key string) (time.Time, error)
+	RegisterLoginFailure(ctx context.Context, key string, policy loginthrottle.Policy) (time.Time, error)
+	ResetLoginFailures(ctx context.Context, key string) error
+}
+
+// sessionSeenInterval precision of session last seen time, session is touched at most once per interval
+const sessionSeenInterval = time.Minute
+
 type AuthService struct {
 	logger         *slog.Logger
 	accountGetter  IAccountGetter
 	secretProvider ISecretProvider
+	sessionCreator ISessionCreator
+	sessionGetter  ISessionGetter
+	sessionUpdater ISessionUpdater
+	attemptCounter ILoginAttemptCounter
+	loginLimits    LoginLimits
+	mfaCreator     IMfaCreator
+	mfaGetter      IMfaGetter
+	mfaUpdater     IMfaUpdater
+	mfaDeleter     IMfaDeleter
+	mfaCipher      ISecretCipher
+	mfaIssuer      string
+	accountUpdater IAccountUpdater
+	passwordHasher IPasswordHasher
+
+	dummyHashOnce sync.Once
+	// dummyHash verified for unknown accounts to keep login timing the same
+	dummyHash []byte
+
+	seenMu sync.Mutex
+	// sessionsSeen sessions touched since seenResetAt
+	sessionsSeen map[uint64]struct{}
+	seenResetAt  time.Time
+}
+
+// loginKey counter of failed logins limited by policy
+type loginKey struct {
+	key    string
+	policy loginthrottle.Policy
 }
 
 func New(
 	log *slog.Logger,
 	secretProvider ISecretProvider,
 	accountGetter IAccountGetter,
+	sessionCreator ISessionCreator,
+	sessionGetter ISessionGetter,
+	sessionUpdater ISessionUpdater,
+	attemptCounter ILoginAttemptCounter,
+	loginLimits LoginLimits,
+	mfaCreator IMfaCreator,
+	mfaGetter IMfaGetter,
+	mfaUpdater IMfaUpdater,
+	mfaDeleter IMfaDeleter,
+	mfaCipher ISecretCipher,
+	mfaIssuer string,
+	accountUpdater IAccountUpdater,
+	passwordHasher IPasswordHasher,
 ) *AuthService {
 	return &AuthService{
 		logger:         log.With(slog.String("module", "authService")),
 		secretProvider: secretProvider,
 		accountGetter:  accountGetter,
+		sessionCreator: sessionCreator,
+		sessionGetter:  sessionGetter,
+		sessionUpdater: sessionUpdater,
+		attemptCounter: attemptCounter,
+		loginLimits:    loginLimits,
+		mfaCreator:     mfaCreator,
+		mfaGetter:      mfaGetter,
+		mfaUpdater:     mfaUpdater,
+		mfaDeleter:     mfaDeleter,
+		mfaCipher:      mfaCipher,
+		mfaIssuer:      mfaIssuer,
+		accountUpdater: accountUpdater,
+		passwordHasher: passwordHasher,
+		sessionsSeen:   make(map[uint64]struct{}),
+		seenResetAt:    time.Now(),
+	}
+}
+
+// dummyPassword hashed to verify passwords of unknown accounts
+const dummyPassword = "dummy-password"
+
+// getDummyHash returns hash of dummyPassword made by current hasher on first call
+func (s *AuthService) getDummyHash(log *slog.Logger) []byte {
+	s.dummyHashOnce.Do(func() {
+		hash, err := s.passwordHasher.Hash(dummyPassword)
+		if err != nil {
+			log.Error("dummy password hash error", slog.Any("err", err))
+			return
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
+// loginKeys returns counters of login attempt. Account counter goes first
+func (s *AuthService) loginKeys(user serviceDTO.User, client serviceDTO.Client) []loginKey {
+	keys := make([]loginKey, 0, 2)
+
+	if user.Username != nil {
+		keys = append(keys, loginKey{key: "user:" + strings.ToLower(*user.Username), policy: s.loginLimits.User})
+	} else {
+		keys = append(keys, loginKey{key: "id:" + strconv.FormatUint(*user.UserID, 10), policy: s.loginLimits.User})
+	}
+
+	if client.IP != "" {
+		keys = append(keys, loginKey{key: "ip:" + client.IP, policy: s.loginLimits.IP})
+	}
+
+	return keys
+}
+
+// checkLoginLock returns *LockedError if any counter is locked
+func (s *AuthService) checkLoginLock(ctx context.Context, keys []loginKey) error {
+	now := time.Now()
+
+	var lockedUntil time.Time
+	for _, key := range keys {
+		keyLockedUntil, err := s.attemptCounter.GetLoginLock(ctx, key.key)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+		if keyLockedUntil.After(lockedUntil) {
+			lockedUntil = keyLockedUntil
+		}
+	}
+
+	if lockedUntil.After(now) {
+		return &LockedError{RetryAfter: lockedUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+// registerLoginFailure counts failed login in every counter
+func (s *AuthService) registerLoginFailure(ctx context.Context, log *slog.Logger, keys []loginKey) {
+	for _, key := range keys {
+		lockedUntil, err := s.attemptCounter.RegisterLoginFailure(ctx, key.key, key.policy)
+		if err != nil {
+			log.Error("login failure count error", slog.Any("err", err))
+			continue
+		}
+		if lockedUntil.Sub(time.Now()) >= key.policy.LockoutDuration {
+			log.Warn("login locked", slog.String("key", key.key), slog.Time("until", lockedUntil))
+		}
 	}
 }
 
@@ -58,12 +290,42 @@ func (s *AuthService) CheckSecret(ctx context.Context, secret []byte) (*serviceD
 		log.Debug("wrong secret", slog.Any("err", err))
 		return nil, ErrWrongSecret
 	}
+
+	if user.SessionID != nil {
+		s.touchSession(ctx, log, *user.SessionID)
+	}
+
 	return &serviceDTO.User{
-		UserID:   user.UserID,
-		Username: user.Username,
+		UserID:    user.UserID,
+		Username:  user.Username,
+		SessionID: user.SessionID,
 	}, nil
 }
 
+// touchSession updates last seen time of session unless it was touched within sessionSeenInterval.
+// Touches are tracked in memory to skip storage, storage skips sessions touched by other instances
+func (s *AuthService) touchSession(ctx context.Context, log *slog.Logger, sessionID uint64) {
+	now := time.Now()
+
+	s.seenMu.Lock()
+	if now.Sub(s.seenResetAt) >= sessionSeenInterval {
+		clear(s.sessionsSeen)
+		s.seenResetAt = now
+	}
+	_, seen := s.sessionsSeen[sessionID]
+	s.sessionsSeen[sessionID] = struct{}{}
+	s.seenMu.Unlock()
+
+	if seen {
+		return
+	}
+
+	err := s.sessionUpdater.TouchSession(ctx, sessionID, now, sessionSeenInterval)
+	if err != nil {
+		log.Warn("touch session error", slog.Any("err", err))
+	}
+}
+
 func (s *AuthService) DeleteSecret(ctx context.Context, secret []byte) error {
 	log := s.logger.With(slog.String("method", "CheckSecret"))
 	err := s.secretProvider.DeleteSecret(ctx, secret)
@@ -74,16 +336,29 @@ func (s *AuthService) DeleteSecret(ctx context.Context, secret []byte) error {
 	return nil
 }
 
-func (s *AuthService) CreateUserSecret(ctx context.Context, user serviceDTO.User) (string, error) {
+// CreateUserSecret checks password and starts new session of client.
+// Accounts with MFA get challenge for VerifyMfa instead of tokens.
+// Failed attempts delay next attempts of the same username and client address,
+// returns *LockedError until delay passes
+func (s *AuthService) CreateUserSecret(
+	ctx context.Context,
+	user serviceDTO.User,
+	client serviceDTO.Client,
+) (*serviceDTO.LoginResult, error) {
 	log := s.logger.With(slog.String("method", "CreateUserSecret"))
 
 	if (user.UserID == nil && user.Username == nil) || user.Password == "" {
 		log.Error("wrong arguments")
-		return "", ErrArguments
+		return nil, ErrArguments
+	}
+
+	keys := s.loginKeys(user, client)
+	err := s.checkLoginLock(ctx, keys)
+	if err != nil {
+		return nil, err
 	}
 
 	var userAccount *storageDTO.User
-	var err error
 
 	if user.Username != nil {
 		userAccount, err = s.accountGetter.GetAccountByUsername(ctx, *user.Username)
@@ -93,28 +368,202 @@ func (s *AuthService) CreateUserSecret(ctx context.Context, user serviceDTO.User
 
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return "", ErrNotFound
+			_, _ = s.passwordHasher.Verify(s.getDummyHash(log), user.Password)
+			s.registerLoginFailure(ctx, log, keys)
+			return nil, ErrNotFound
 		}
 		log.Error("get account error", slog.Any("err", err))
-		return "", errors.Join(ErrInternal, err)
+		return nil, errors.Join(ErrInternal, err)
 	}
 
-	err = bcrypt.CompareHashAndPassword(userAccount.PasswordHash, []byte(user.Password))
+	rehash, err := s.passwordHasher.Verify(userAccount.PasswordHash, user.Password)
 
 	if err != nil {
 		log.Debug("pasword hash compare error", slog.Any("err", err))
-		return "", ErrWrongSecret
+		s.registerLoginFailure(ctx, log, keys)
+		return nil, ErrWrongSecret
 	}
 
-	secretBytes, err := s.secretProvider.CreateSecret(ctx, secretsDTO.User{
-		UserID:   &userAccount.Id,
-		Username: &userAccount.Username,
+	if rehash {
+		s.rehashPassword(ctx, log, userAccount.Id, user.Password)
+	}
+
+	mfaEnabled, err := s.mfaEnabled(ctx, userAccount.Id)
+	if err != nil {
+		log.Error("get mfa error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	if mfaEnabled {
+		challenge, err := s.createMfaChallenge(ctx, userAccount.Id)
+		if err != nil {
+			log.Error("mfa challenge create error", slog.Any("err", err))
+			return nil, errors.Join(ErrInternal, err)
+		}
+		return &serviceDTO.LoginResult{MfaChallenge: challenge}, nil
+	}
+
+	tokens, err := s.startSession(ctx, log, userAccount, client, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceDTO.LoginResult{Tokens: tokens}, nil
+}
+
+// rehashPassword replaces weaker password hash of user with hash of current algorithm.
+// Failures are logged only, old hash keeps working
+func (s *AuthService) rehashPassword(ctx context.Context, log *slog.Logger, userID uint64, password string) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		log.Error("password rehash error", slog.Any("err", err))
+		return
+	}
+
+	err = s.accountUpdater.UpdatePasswordHash(ctx, userID, passwordHash)
+	if err != nil {
+		log.Error("password hash update error", slog.Any("err", err))
+		return
+	}
+
+	log.Debug("password rehashed", slog.Uint64("user_id", userID))
+}
+
+// startSession creates session of client with its tokens and resets failed logins of account
+func (s *AuthService) startSession(
+	ctx context.Context,
+	log *slog.Logger,
+	userAccount *storageDTO.User,
+	client serviceDTO.Client,
+	keys []loginKey,
+) (*serviceDTO.TokenPair, error) {
+	err := s.attemptCounter.ResetLoginFailures(ctx, keys[0].key)
+	if err != nil {
+		log.Error("login failures reset error", slog.Any("err", err))
+	}
+
+	sessionID, err := s.sessionCreator.CreateSession(ctx, storageDTO.Session{
+		UserID:    userAccount.Id,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	})
+	if err != nil {
+		log.Error("session create error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	tokens, err := s.secretProvider.CreateSecret(ctx, secretsDTO.User{
+		UserID:    &userAccount.Id,
+		Username:  &userAccount.Username,
+		SessionID: &sessionID,
 	})
 
 	if err != nil {
-		log.Debug("pasword hash compare error", slog.Any("err", err))
-		return "", errors.Join(ErrInternal, err)
+		log.Debug("secret create error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
 	}
 
-	return string(secretBytes), nil
+	return tokenPairFromSecrets(tokens), nil
+}
+
+// RefreshUserSecret exchanges refresh secret for new secrets
+func (s *AuthService) RefreshUserSecret(ctx context.Context, refreshToken string) (*serviceDTO.TokenPair, error) {
+	log := s.logger.With(slog.String("method", "RefreshUserSecret"))
+
+	if refreshToken == "" {
+		return nil, ErrArguments
+	}
+
+	tokens, err := s.secretProvider.RefreshSecret(ctx, []byte(refreshToken))
+	if err != nil {
+		if errors.Is(err, secretsJwt.ErrRefreshReused) {
+			return nil, ErrSecretReused
+		}
+		log.Debug("wrong refresh secret", slog.Any("err", err))
+		return nil, ErrWrongSecret
+	}
+
+	return tokenPairFromSecrets(tokens), nil
+}
+
+// ListSessions returns active sessions of user. Session currentSessionID is marked current
+func (s *AuthService) ListSessions(
+	ctx context.Context,
+	userID uint64,
+	currentSessionID uint64,
+) ([]serviceDTO.Session, error) {
+	log := s.logger.With(slog.String("method", "ListSessions"))
+
+	sessions, err := s.sessionGetter.GetSessions(ctx, userID)
+	if err != nil {
+		log.Error("get sessions error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	result := make([]serviceDTO.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, serviceDTO.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession ends session of user
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error {
+	err := s.secretProvider.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		if errors.Is(err, secretsJwt.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return errors.Join(ErrInternal, err)
+	}
+	return nil
+}
+
+// RevokeAllOtherSessions ends every session of user except currentSessionID.
+// Returns count of ended sessions
+func (s *AuthService) RevokeAllOtherSessions(ctx context.Context, userID uint64, currentSessionID uint64) (int64, error) {
+	count, err := s.secretProvider.RevokeOtherSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+	return count, nil
+}
+
+// PublicKeys returns keys verifying issued secrets
+func (s *AuthService) PublicKeys(ctx context.Context) []serviceDTO.PublicKey {
+	secretKeys := s.secretProvider.PublicKeys(ctx)
+
+	keys := make([]serviceDTO.PublicKey, 0, len(secretKeys))
+	for _, key := range secretKeys {
+		keys = append(keys, serviceDTO.PublicKey{
+			KeyType:   key.KeyType,
+			KeyID:     key.KeyID,
+			Use:       key.Use,
+			Algorithm: key.Algorithm,
+			Curve:     key.Curve,
+			X:         key.X,
+			N:         key.N,
+			E:         key.E,
+		})
+	}
+
+	return keys
+}
+
+func tokenPairFromSecrets(tokens *secretsDTO.TokenPair) *serviceDTO.TokenPair {
+	return &serviceDTO.TokenPair{
+		AccessToken:      string(tokens.AccessToken),
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshToken:     string(tokens.RefreshToken),
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
 }
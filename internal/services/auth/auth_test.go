@@ -1,8 +1,13 @@
 package authservice
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/loginthrottle"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/passwordhash"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/secretcipher"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt"
 	"github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt/secretsdto"
 	"github.com/IldarGaleev/todo-backend-service/internal/services/auth/mocks"
 	"github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
@@ -14,21 +19,87 @@ import (
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 )
 
 func createAuthService(t *testing.T) (*mocks.ISecretProvider, *mocks.IAccountGetter, *AuthService) {
+	secretProvider, accountGetter, _, _, authService := createAuthServiceWithSessions(t)
+	return secretProvider, accountGetter, authService
+}
+
+func createAuthServiceWithSessions(t *testing.T) (
+	*mocks.ISecretProvider,
+	*mocks.IAccountGetter,
+	*mocks.ISessionCreator,
+	*mocks.ISessionGetter,
+	*AuthService,
+) {
+	m, authService := createAuthServiceMocks(t)
+
+	m.mfaGetter.On(
+		"GetTOTP",
+		mock.Anything,
+		mock.Anything,
+	).Return(nil, storage.ErrNotFound).Maybe()
+
+	return m.secretProvider, m.accountGetter, m.sessionCreator, m.sessionGetter, authService
+}
+
+type authServiceMocks struct {
+	secretProvider *mocks.ISecretProvider
+	accountGetter  *mocks.IAccountGetter
+	sessionCreator *mocks.ISessionCreator
+	sessionGetter  *mocks.ISessionGetter
+	sessionUpdater *mocks.ISessionUpdater
+	mfaCreator     *mocks.IMfaCreator
+	mfaGetter      *mocks.IMfaGetter
+	mfaUpdater     *mocks.IMfaUpdater
+	mfaDeleter     *mocks.IMfaDeleter
+	accountUpdater *mocks.IAccountUpdater
+}
+
+func createAuthServiceMocks(t *testing.T) (*authServiceMocks, *AuthService) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	secretProvider := mocks.NewISecretProvider(t)
-	accountGetter := mocks.NewIAccountGetter(t)
+	m := &authServiceMocks{
+		secretProvider: mocks.NewISecretProvider(t),
+		accountGetter:  mocks.NewIAccountGetter(t),
+		sessionCreator: mocks.NewISessionCreator(t),
+		sessionGetter:  mocks.NewISessionGetter(t),
+		sessionUpdater: mocks.NewISessionUpdater(t),
+		mfaCreator:     mocks.NewIMfaCreator(t),
+		mfaGetter:      mocks.NewIMfaGetter(t),
+		mfaUpdater:     mocks.NewIMfaUpdater(t),
+		mfaDeleter:     mocks.NewIMfaDeleter(t),
+		accountUpdater: mocks.NewIAccountUpdater(t),
+	}
+
+	mfaCipher, err := secretcipher.New(bytes.Repeat([]byte{7}, secretcipher.KeySize))
+	require.NoError(t, err)
 
 	authService := New(
 		logger,
-		secretProvider,
-		accountGetter,
+		m.secretProvider,
+		m.accountGetter,
+		m.sessionCreator,
+		m.sessionGetter,
+		m.sessionUpdater,
+		loginthrottle.NewMemoryCounter(),
+		LoginLimits{
+			User: loginthrottle.Policy{Threshold: 2, LockoutDuration: time.Minute},
+			IP:   loginthrottle.Policy{Threshold: 3, LockoutDuration: time.Minute},
+		},
+		m.mfaCreator,
+		m.mfaGetter,
+		m.mfaUpdater,
+		m.mfaDeleter,
+		mfaCipher,
+		"ToDo",
+		m.accountUpdater,
+		passwordhash.NewWithAlgorithms(passwordhash.Bcrypt{Cost: bcrypt.DefaultCost}),
 	)
 
-	return secretProvider, accountGetter, authService
+	return m, authService
 }
 
 func TestAuthService_CheckSecret_Valid(t *testing.T) {
@@ -59,6 +130,36 @@ func TestAuthService_CheckSecret_Valid(t *testing.T) {
 	require.Equal(t, &userId, usr.UserID)
 }
 
+func TestAuthService_CheckSecret_TouchesSession(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	secret := []byte("secret")
+	userID := uint64(1)
+	sessionID := uint64(9)
+
+	m.secretProvider.On(
+		"ValidateSecret",
+		mock.Anything,
+		secret,
+	).Return(&secretsdto.User{UserID: &userID, SessionID: &sessionID}, nil)
+
+	m.sessionUpdater.On(
+		"TouchSession",
+		mock.Anything,
+		sessionID,
+		mock.AnythingOfType("time.Time"),
+		time.Minute,
+	).Return(errors.New("touch failed")).Once()
+
+	for i := 0; i < 3; i++ {
+		usr, err := authService.CheckSecret(ctx, secret)
+
+		require.NoError(t, err)
+		require.Equal(t, &sessionID, usr.SessionID)
+	}
+}
+
 func TestAuthService_CheckSecret_Invalid(t *testing.T) {
 	ctx := context.Background()
 	secretProvider, _, authService := createAuthService(t)
@@ -100,43 +201,123 @@ func prepareAccountGetter(ag *mocks.IAccountGetter, userPassword string, t *test
 
 func TestAuthService_CreateUserSecret_Valid(t *testing.T) {
 	ctx := context.Background()
-	secretProvider, accountGetter, authService := createAuthService(t)
+	secretProvider, accountGetter, sessionCreator, _, authService := createAuthServiceWithSessions(t)
 
 	userPassword := "secret"
 	username := "test_user"
+	sessionID := uint64(5)
 	prepareAccountGetter(accountGetter, userPassword, t)
 
+	sessionCreator.On(
+		"CreateSession",
+		mock.Anything,
+		storageDTO.Session{UserAgent: "grpc-go/1.0", IP: "10.0.0.1"},
+	).Return(sessionID, nil)
+
 	secretProvider.On(
 		"CreateSecret",
 		mock.Anything,
-		mock.Anything,
-	).Return([]byte("generated_token"), nil)
-
-	token, err := authService.CreateUserSecret(ctx, servicedto.User{Username: &username, Password: userPassword})
+		mock.MatchedBy(func(user secretsdto.User) bool {
+			return user.SessionID != nil && *user.SessionID == sessionID
+		}),
+	).Return(&secretsdto.TokenPair{
+		AccessToken:  []byte("generated_token"),
+		RefreshToken: []byte("generated_refresh_token"),
+	}, nil)
+
+	result, err := authService.CreateUserSecret(
+		ctx,
+		servicedto.User{Username: &username, Password: userPassword},
+		servicedto.Client{UserAgent: "grpc-go/1.0", IP: "10.0.0.1"},
+	)
 
 	require.NoError(t, err)
-	require.Equal(t, "generated_token", token)
+	require.Nil(t, result.MfaChallenge)
+	require.Equal(t, "generated_token", result.Tokens.AccessToken)
+	require.Equal(t, "generated_refresh_token", result.Tokens.RefreshToken)
 }
 
 func TestAuthService_CreateUserSecret_CreateSecret_InternalError(t *testing.T) {
 	ctx := context.Background()
-	secretProvider, accountGetter, authService := createAuthService(t)
+	secretProvider, accountGetter, sessionCreator, _, authService := createAuthServiceWithSessions(t)
 
 	userPassword := "secret"
 	username := "test_user"
 	prepareAccountGetter(accountGetter, userPassword, t)
 
+	sessionCreator.On(
+		"CreateSession",
+		mock.Anything,
+		mock.Anything,
+	).Return(uint64(5), nil)
+
 	secretProvider.On(
 		"CreateSecret",
 		mock.Anything,
 		mock.Anything,
 	).Return(nil, errors.New("create secret internal error"))
 
-	_, err := authService.CreateUserSecret(ctx, servicedto.User{Username: &username, Password: userPassword})
+	_, err := authService.CreateUserSecret(ctx, servicedto.User{Username: &username, Password: userPassword}, servicedto.Client{})
 
 	require.ErrorIs(t, err, ErrInternal)
 }
 
+func TestAuthService_RefreshUserSecret(t *testing.T) {
+	testCases := []struct {
+		name          string
+		refreshError  error
+		expectedError error
+	}{
+		{
+			name:          "invalid refresh token",
+			refreshError:  secretsjwt.ErrVerifyError,
+			expectedError: ErrWrongSecret,
+		},
+		{
+			name:          "reused refresh token",
+			refreshError:  secretsjwt.ErrRefreshReused,
+			expectedError: ErrSecretReused,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			secretProvider, _, authService := createAuthService(t)
+
+			secretProvider.On(
+				"RefreshSecret",
+				mock.Anything,
+				[]byte("refresh_token"),
+			).Return(nil, testCase.refreshError)
+
+			_, err := authService.RefreshUserSecret(ctx, "refresh_token")
+
+			require.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestAuthService_RefreshUserSecret_Valid(t *testing.T) {
+	ctx := context.Background()
+	secretProvider, _, authService := createAuthService(t)
+
+	secretProvider.On(
+		"RefreshSecret",
+		mock.Anything,
+		[]byte("refresh_token"),
+	).Return(&secretsdto.TokenPair{
+		AccessToken:  []byte("new_token"),
+		RefreshToken: []byte("new_refresh_token"),
+	}, nil)
+
+	tokens, err := authService.RefreshUserSecret(ctx, "refresh_token")
+
+	require.NoError(t, err)
+	require.Equal(t, "new_token", tokens.AccessToken)
+	require.Equal(t, "new_refresh_token", tokens.RefreshToken)
+}
+
 func TestAuthService_CreateUserSecret_ByUserID_NotFound(t *testing.T) {
 	ctx := context.Background()
 	_, accountGetter, authService := createAuthService(t)
@@ -149,11 +330,39 @@ func TestAuthService_CreateUserSecret_ByUserID_NotFound(t *testing.T) {
 		mock.Anything,
 	).Return(nil, storage.ErrNotFound)
 
-	_, err := authService.CreateUserSecret(ctx, servicedto.User{UserID: &userId, Password: "pwd"})
+	_, err := authService.CreateUserSecret(ctx, servicedto.User{UserID: &userId, Password: "pwd"}, servicedto.Client{})
 
 	require.ErrorIs(t, err, ErrNotFound)
 }
 
+// verifyCountingHasher counts verified passwords of wrapped hasher
+type verifyCountingHasher struct {
+	IPasswordHasher
+	verified []string
+}
+
+func (h *verifyCountingHasher) Verify(hash []byte, password string) (bool, error) {
+	h.verified = append(h.verified, password)
+	return h.IPasswordHasher.Verify(hash, password)
+}
+
+func TestAuthService_CreateUserSecret_NotFound_VerifiesDummyHash(t *testing.T) {
+	ctx := context.Background()
+	_, accountGetter, authService := createAuthService(t)
+
+	hasher := &verifyCountingHasher{IPasswordHasher: authService.passwordHasher}
+	authService.passwordHasher = hasher
+
+	username := "unknown"
+	accountGetter.On("GetAccountByUsername", mock.Anything, username).Return(nil, storage.ErrNotFound)
+
+	_, err := authService.CreateUserSecret(ctx, servicedto.User{Username: &username, Password: "pwd"}, servicedto.Client{})
+
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, []string{"pwd"}, hasher.verified)
+	require.NotEmpty(t, authService.dummyHash)
+}
+
 func TestAuthService_CreateUserSecret_ByUserID_AccountGetterInternalError(t *testing.T) {
 	ctx := context.Background()
 	_, accountGetter, authService := createAuthService(t)
@@ -166,7 +375,7 @@ func TestAuthService_CreateUserSecret_ByUserID_AccountGetterInternalError(t *tes
 		mock.Anything,
 	).Return(nil, errors.New("account getter internal error"))
 
-	_, err := authService.CreateUserSecret(ctx, servicedto.User{UserID: &userId, Password: "pwd"})
+	_, err := authService.CreateUserSecret(ctx, servicedto.User{UserID: &userId, Password: "pwd"}, servicedto.Client{})
 
 	require.ErrorIs(t, err, ErrInternal)
 }
@@ -175,7 +384,7 @@ func TestAuthService_CreateUserSecret_InvalidUserData(t *testing.T) {
 	ctx := context.Background()
 	_, _, authService := createAuthService(t)
 
-	_, err := authService.CreateUserSecret(ctx, servicedto.User{})
+	_, err := authService.CreateUserSecret(ctx, servicedto.User{}, servicedto.Client{})
 
 	require.ErrorIs(t, err, ErrArguments)
 }
@@ -211,7 +420,8 @@ func TestAuthService_CreateUserSecret_InvalidUserPassword(t *testing.T) {
 				servicedto.User{
 					Username: &username,
 					Password: testCase.password,
-				})
+				},
+				servicedto.Client{})
 
 			require.ErrorIs(t, err, testCase.expectedError)
 		})
@@ -247,3 +457,128 @@ func TestAuthService_DeleteSecret_Failed(t *testing.T) {
 
 	require.ErrorIs(t, err, ErrWrongSecret)
 }
+
+func TestAuthService_ListSessions_MarksCurrent(t *testing.T) {
+	ctx := context.Background()
+	_, _, _, sessionGetter, authService := createAuthServiceWithSessions(t)
+
+	userID := uint64(1)
+
+	sessionGetter.On(
+		"GetSessions",
+		mock.Anything,
+		userID,
+	).Return([]storageDTO.Session{{ID: 5, UserID: userID}, {ID: 6, UserID: userID}}, nil)
+
+	sessions, err := authService.ListSessions(ctx, userID, 6)
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.False(t, sessions[0].Current)
+	require.True(t, sessions[1].Current)
+}
+
+func TestAuthService_RevokeSession_NotFound(t *testing.T) {
+	ctx := context.Background()
+	secretProvider, _, authService := createAuthService(t)
+
+	secretProvider.On(
+		"RevokeSession",
+		mock.Anything,
+		uint64(1),
+		uint64(5),
+	).Return(secretsjwt.ErrSessionNotFound)
+
+	err := authService.RevokeSession(ctx, 1, 5)
+
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestAuthService_CreateUserSecret_LockedAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	_, accountGetter, authService := createAuthService(t)
+
+	prepareAccountGetter(accountGetter, "secret", t)
+
+	username := "user"
+	for i := 0; i < 2; i++ {
+		_, err := authService.CreateUserSecret(
+			ctx,
+			servicedto.User{Username: &username, Password: "wrong_password"},
+			servicedto.Client{IP: "10.0.0.1"},
+		)
+		require.ErrorIs(t, err, ErrWrongSecret)
+	}
+
+	_, err := authService.CreateUserSecret(
+		ctx,
+		servicedto.User{Username: &username, Password: "secret"},
+		servicedto.Client{IP: "10.0.0.2"},
+	)
+
+	var lockedErr *LockedError
+	require.ErrorAs(t, err, &lockedErr)
+	require.ErrorIs(t, err, ErrLocked)
+	require.Greater(t, lockedErr.RetryAfter, time.Duration(0))
+	accountGetter.AssertNumberOfCalls(t, "GetAccountByUsername", 2)
+}
+
+func TestAuthService_CreateUserSecret_LockedSourceAddress(t *testing.T) {
+	ctx := context.Background()
+	_, accountGetter, authService := createAuthService(t)
+
+	prepareAccountGetter(accountGetter, "secret", t)
+
+	for _, username := range []string{"user1", "user2", "user3"} {
+		_, err := authService.CreateUserSecret(
+			ctx,
+			servicedto.User{Username: &username, Password: "wrong_password"},
+			servicedto.Client{IP: "10.0.0.1"},
+		)
+		require.ErrorIs(t, err, ErrWrongSecret)
+	}
+
+	username := "user4"
+	_, err := authService.CreateUserSecret(
+		ctx,
+		servicedto.User{Username: &username, Password: "secret"},
+		servicedto.Client{IP: "10.0.0.1"},
+	)
+
+	require.ErrorIs(t, err, ErrLocked)
+}
+
+func TestAuthService_CreateUserSecret_RehashWeakerPassword(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	username := "test_user"
+	weakHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	m.accountGetter.On("GetAccountByUsername", mock.Anything, username).
+		Return(&storageDTO.User{Id: userID, Username: username, PasswordHash: weakHash}, nil)
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(nil, storage.ErrNotFound)
+	m.accountUpdater.On(
+		"UpdatePasswordHash",
+		mock.Anything,
+		userID,
+		mock.MatchedBy(func(passwordHash []byte) bool {
+			cost, err := bcrypt.Cost(passwordHash)
+			return err == nil && cost == bcrypt.DefaultCost &&
+				bcrypt.CompareHashAndPassword(passwordHash, []byte("secret")) == nil
+		}),
+	).Return(nil)
+	m.sessionCreator.On("CreateSession", mock.Anything, mock.Anything).Return(uint64(5), nil)
+	m.secretProvider.On("CreateSecret", mock.Anything, mock.Anything).
+		Return(&secretsdto.TokenPair{AccessToken: []byte("generated_token")}, nil)
+
+	_, err = authService.CreateUserSecret(
+		ctx,
+		servicedto.User{Username: &username, Password: "secret"},
+		servicedto.Client{},
+	)
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,372 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/totp"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+const (
+	// mfaChallengeMaxAge time to enter MFA code after password
+	mfaChallengeMaxAge = 5 * time.Minute
+	// mfaChallengeSize length of challenge token in random bytes
+	mfaChallengeSize = 32
+	// RecoveryCodeCount count of recovery codes generated at once
+	RecoveryCodeCount = 10
+	// recoveryCodeSize length of recovery code in random bytes, encoded to 8 characters
+	recoveryCodeSize = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpAdditionalData binds encrypted TOTP secret to its owner
+func totpAdditionalData(userID uint64) []byte {
+	return []byte("totp:" + strconv.FormatUint(userID, 10))
+}
+
+// hashToken returns hash of challenge token or normalized recovery code kept in storage
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// normalizeRecoveryCode drops separators and case of recovery code typed by user
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// mfaEnabled reports whether user confirmed TOTP enrollment
+func (s *AuthService) mfaEnabled(ctx context.Context, userID uint64) (bool, error) {
+	secret, err := s.mfaGetter.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return secret.ConfirmedAt != nil, nil
+}
+
+// createMfaChallenge stores challenge of login waiting for MFA code
+func (s *AuthService) createMfaChallenge(ctx context.Context, userID uint64) (*serviceDTO.MfaChallenge, error) {
+	tokenBytes := make([]byte, mfaChallengeSize)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &serviceDTO.MfaChallenge{
+		Token:     base64.RawURLEncoding.EncodeToString(tokenBytes),
+		ExpiresAt: time.Now().Add(mfaChallengeMaxAge),
+	}
+
+	err = s.mfaCreator.CreateMfaChallenge(ctx, storageDTO.MfaChallenge{
+		UserID:    userID,
+		ExpiresAt: challenge.ExpiresAt,
+	}, hashToken(challenge.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// decryptTOTPSecret returns plain TOTP secret of enrollment
+func (s *AuthService) decryptTOTPSecret(secret *storageDTO.TOTP) ([]byte, error) {
+	if s.mfaCipher == nil {
+		return nil, ErrMfaUnavailable
+	}
+	return s.mfaCipher.Decrypt(secret.Secret, totpAdditionalData(secret.UserID))
+}
+
+// useMfaCode accepts TOTP code or unused recovery code of confirmed enrollment.
+// Returns ErrWrongMfaCode for wrong and already used codes
+func (s *AuthService) useMfaCode(ctx context.Context, secret *storageDTO.TOTP, code string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		plainSecret, err := s.decryptTOTPSecret(secret)
+		if err != nil {
+			return errors.Join(ErrInternal, err)
+		}
+
+		step, ok := totp.Validate(plainSecret, code, time.Now())
+		if !ok || step <= secret.LastUsedStep {
+			return ErrWrongMfaCode
+		}
+
+		err = s.mfaUpdater.UseTOTPStep(ctx, secret.UserID, step)
+		if err != nil {
+			if errors.Is(err, storage.ErrTokenReused) {
+				return ErrWrongMfaCode
+			}
+			return errors.Join(ErrInternal, err)
+		}
+
+		return nil
+	}
+
+	err := s.mfaUpdater.UseRecoveryCode(ctx, secret.UserID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrWrongMfaCode
+		}
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// confirmedTOTP returns enrollment of user. Returns ErrMfaNotEnabled if it is missing or unconfirmed
+func (s *AuthService) confirmedTOTP(ctx context.Context, userID uint64) (*storageDTO.TOTP, error) {
+	secret, err := s.mfaGetter.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrMfaNotEnabled
+		}
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	if secret.ConfirmedAt == nil {
+		return nil, ErrMfaNotEnabled
+	}
+
+	return secret, nil
+}
+
+// VerifyMfa exchanges MFA challenge and code for tokens of new session.
+// Wrong codes count as failed logins of the account and client address
+func (s *AuthService) VerifyMfa(
+	ctx context.Context,
+	challengeToken string,
+	code string,
+	client serviceDTO.Client,
+) (*serviceDTO.TokenPair, error) {
+	log := s.logger.With(slog.String("method", "VerifyMfa"))
+
+	if challengeToken == "" || code == "" {
+		return nil, ErrArguments
+	}
+
+	challenge, err := s.mfaGetter.GetMfaChallenge(ctx, hashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrWrongSecret
+		}
+		log.Error("get mfa challenge error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	userAccount, err := s.accountGetter.GetAccountByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrWrongSecret
+		}
+		log.Error("get account error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	keys := s.loginKeys(serviceDTO.User{Username: &userAccount.Username}, client)
+	err = s.checkLoginLock(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.confirmedTOTP(ctx, userAccount.Id)
+	if err != nil {
+		if errors.Is(err, ErrMfaNotEnabled) {
+			return nil, ErrWrongSecret
+		}
+		log.Error("get mfa error", slog.Any("err", err))
+		return nil, err
+	}
+
+	err = s.useMfaCode(ctx, secret, code)
+	if err != nil {
+		if errors.Is(err, ErrWrongMfaCode) {
+			s.registerLoginFailure(ctx, log, keys)
+			return nil, err
+		}
+		log.Error("mfa code check error", slog.Any("err", err))
+		return nil, err
+	}
+
+	err = s.mfaDeleter.DeleteMfaChallenge(ctx, challenge.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrWrongSecret
+		}
+		log.Error("mfa challenge delete error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return s.startSession(ctx, log, userAccount, client, keys)
+}
+
+// EnrollTOTP creates unconfirmed TOTP secret of user replacing previous unconfirmed one.
+// MFA is enabled after ConfirmTOTP
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID uint64) (*serviceDTO.TOTPEnrollment, error) {
+	log := s.logger.With(slog.String("method", "EnrollTOTP"))
+
+	if s.mfaCipher == nil {
+		return nil, ErrMfaUnavailable
+	}
+
+	userAccount, err := s.accountGetter.GetAccountByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Error("get account error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	encrypted, err := s.mfaCipher.Encrypt(secret, totpAdditionalData(userID))
+	if err != nil {
+		log.Error("mfa secret encrypt error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	err = s.mfaCreator.SetTOTPSecret(ctx, userID, encrypted)
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, ErrMfaEnabled
+		}
+		log.Error("mfa secret save error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return &serviceDTO.TOTPEnrollment{
+		Secret: totp.EncodeSecret(secret),
+		URI:    totp.URI(s.mfaIssuer, userAccount.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables MFA after user proves enrolled secret with code
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID uint64, code string) error {
+	log := s.logger.With(slog.String("method", "ConfirmTOTP"))
+
+	secret, err := s.mfaGetter.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrMfaNotEnabled
+		}
+		log.Error("get mfa error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	if secret.ConfirmedAt != nil {
+		return ErrMfaEnabled
+	}
+
+	plainSecret, err := s.decryptTOTPSecret(secret)
+	if err != nil {
+		log.Error("mfa secret decrypt error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	step, ok := totp.Validate(plainSecret, code, time.Now())
+	if !ok {
+		return ErrWrongMfaCode
+	}
+
+	err = s.mfaUpdater.ConfirmTOTP(ctx, userID, step)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrWrongMfaCode
+		}
+		log.Error("mfa confirm error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// DisableTOTP removes TOTP secret and recovery codes of user.
+// Confirmed enrollment requires TOTP or recovery code
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uint64, code string) error {
+	log := s.logger.With(slog.String("method", "DisableTOTP"))
+
+	secret, err := s.mfaGetter.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrMfaNotEnabled
+		}
+		log.Error("get mfa error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	if secret.ConfirmedAt != nil {
+		err = s.useMfaCode(ctx, secret, code)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.mfaDeleter.DeleteTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrMfaNotEnabled
+		}
+		log.Error("mfa delete error", slog.Any("err", err))
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// GenerateRecoveryCodes replaces recovery codes of user with RecoveryCodeCount new ones.
+// Requires TOTP or recovery code. Codes are returned once, only their hashes are stored
+func (s *AuthService) GenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error) {
+	log := s.logger.With(slog.String("method", "GenerateRecoveryCodes"))
+
+	secret, err := s.confirmedTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.useMfaCode(ctx, secret, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	codeHashes := make([][]byte, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		codeBytes := make([]byte, recoveryCodeSize)
+		_, err = rand.Read(codeBytes)
+		if err != nil {
+			return nil, errors.Join(ErrInternal, err)
+		}
+
+		encoded := recoveryCodeEncoding.EncodeToString(codeBytes)
+		codes = append(codes, strings.ToLower(encoded[:4]+"-"+encoded[4:]))
+		codeHashes = append(codeHashes, hashToken(encoded))
+	}
+
+	err = s.mfaCreator.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+	if err != nil {
+		log.Error("recovery codes save error", slog.Any("err", err))
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return codes, nil
+}
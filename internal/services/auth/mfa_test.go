@@ -0,0 +1,222 @@
+package authservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/secretsjwt/secretsdto"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/totp"
+	"github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// confirmedTOTPSecret returns plain secret with its confirmed enrollment encrypted by authService
+func confirmedTOTPSecret(t *testing.T, authService *AuthService, userID uint64) ([]byte, *storageDTO.TOTP) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	encrypted, err := authService.mfaCipher.Encrypt(secret, totpAdditionalData(userID))
+	require.NoError(t, err)
+
+	confirmedAt := time.Now()
+	return secret, &storageDTO.TOTP{
+		UserID:      userID,
+		Secret:      encrypted,
+		ConfirmedAt: &confirmedAt,
+	}
+}
+
+func TestAuthService_CreateUserSecret_MfaChallenge(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	username := "test_user"
+	pwdHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	m.accountGetter.On("GetAccountByUsername", mock.Anything, username).
+		Return(&storageDTO.User{Id: userID, Username: username, PasswordHash: pwdHash}, nil)
+
+	_, enrollment := confirmedTOTPSecret(t, authService, userID)
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(enrollment, nil)
+
+	var challengeHash []byte
+	m.mfaCreator.On(
+		"CreateMfaChallenge",
+		mock.Anything,
+		mock.MatchedBy(func(challenge storageDTO.MfaChallenge) bool { return challenge.UserID == userID }),
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		challengeHash = args.Get(2).([]byte)
+	}).Return(nil)
+
+	result, err := authService.CreateUserSecret(
+		ctx,
+		servicedto.User{Username: &username, Password: "secret"},
+		servicedto.Client{},
+	)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Tokens)
+	require.NotNil(t, result.MfaChallenge)
+	require.Equal(t, hashToken(result.MfaChallenge.Token), challengeHash)
+}
+
+func TestAuthService_VerifyMfa_TOTPCode(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	username := "test_user"
+	sessionID := uint64(9)
+
+	secret, enrollment := confirmedTOTPSecret(t, authService, userID)
+	code := totp.Code(secret, totp.Step(time.Now()))
+
+	m.mfaGetter.On("GetMfaChallenge", mock.Anything, hashToken("challenge")).
+		Return(&storageDTO.MfaChallenge{ID: 4, UserID: userID}, nil)
+	m.accountGetter.On("GetAccountByID", mock.Anything, userID).
+		Return(&storageDTO.User{Id: userID, Username: username}, nil)
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(enrollment, nil)
+	m.mfaUpdater.On("UseTOTPStep", mock.Anything, userID, totp.Step(time.Now())).Return(nil)
+	m.mfaDeleter.On("DeleteMfaChallenge", mock.Anything, uint64(4)).Return(nil)
+	m.sessionCreator.On(
+		"CreateSession",
+		mock.Anything,
+		storageDTO.Session{UserID: userID, IP: "10.0.0.1"},
+	).Return(sessionID, nil)
+	m.secretProvider.On(
+		"CreateSecret",
+		mock.Anything,
+		mock.MatchedBy(func(user secretsdto.User) bool { return *user.SessionID == sessionID }),
+	).Return(&secretsdto.TokenPair{AccessToken: []byte("generated_token")}, nil)
+
+	tokens, err := authService.VerifyMfa(ctx, "challenge", code, servicedto.Client{IP: "10.0.0.1"})
+
+	require.NoError(t, err)
+	require.Equal(t, "generated_token", tokens.AccessToken)
+}
+
+func TestAuthService_VerifyMfa_RecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	_, enrollment := confirmedTOTPSecret(t, authService, userID)
+
+	m.mfaGetter.On("GetMfaChallenge", mock.Anything, mock.Anything).
+		Return(&storageDTO.MfaChallenge{ID: 4, UserID: userID}, nil)
+	m.accountGetter.On("GetAccountByID", mock.Anything, userID).
+		Return(&storageDTO.User{Id: userID, Username: "test_user"}, nil)
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(enrollment, nil)
+	m.mfaUpdater.On("UseRecoveryCode", mock.Anything, userID, hashToken("ABCDEFGH")).Return(nil)
+	m.mfaDeleter.On("DeleteMfaChallenge", mock.Anything, uint64(4)).Return(nil)
+	m.sessionCreator.On("CreateSession", mock.Anything, mock.Anything).Return(uint64(9), nil)
+	m.secretProvider.On("CreateSecret", mock.Anything, mock.Anything).
+		Return(&secretsdto.TokenPair{AccessToken: []byte("generated_token")}, nil)
+
+	_, err := authService.VerifyMfa(ctx, "challenge", "abcd-efgh", servicedto.Client{})
+
+	require.NoError(t, err)
+}
+
+func TestAuthService_VerifyMfa_WrongCodeLocks(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	_, enrollment := confirmedTOTPSecret(t, authService, userID)
+
+	m.mfaGetter.On("GetMfaChallenge", mock.Anything, mock.Anything).
+		Return(&storageDTO.MfaChallenge{ID: 4, UserID: userID}, nil)
+	m.accountGetter.On("GetAccountByID", mock.Anything, userID).
+		Return(&storageDTO.User{Id: userID, Username: "test_user"}, nil)
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(enrollment, nil)
+	m.mfaUpdater.On("UseRecoveryCode", mock.Anything, userID, mock.Anything).Return(storage.ErrNotFound)
+
+	for i := 0; i < 2; i++ {
+		_, err := authService.VerifyMfa(ctx, "challenge", "wrong-code", servicedto.Client{})
+		require.ErrorIs(t, err, ErrWrongMfaCode)
+	}
+
+	_, err := authService.VerifyMfa(ctx, "challenge", "wrong-code", servicedto.Client{})
+	require.ErrorIs(t, err, ErrLocked)
+}
+
+func TestAuthService_EnrollAndConfirmTOTP(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	m.accountGetter.On("GetAccountByID", mock.Anything, userID).
+		Return(&storageDTO.User{Id: userID, Username: "test_user"}, nil)
+
+	var stored []byte
+	m.mfaCreator.On("SetTOTPSecret", mock.Anything, userID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]byte)
+		}).Return(nil)
+
+	enrollment, err := authService.EnrollTOTP(ctx, userID)
+	require.NoError(t, err)
+	require.Contains(t, enrollment.URI, "otpauth://totp/ToDo:test_user?")
+	require.NotContains(t, string(stored), enrollment.Secret)
+
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).
+		Return(&storageDTO.TOTP{UserID: userID, Secret: stored}, nil)
+
+	secret, err := authService.decryptTOTPSecret(&storageDTO.TOTP{UserID: userID, Secret: stored})
+	require.NoError(t, err)
+	require.Equal(t, enrollment.Secret, totp.EncodeSecret(secret))
+
+	step := totp.Step(time.Now())
+	m.mfaUpdater.On("ConfirmTOTP", mock.Anything, userID, step).Return(nil)
+
+	require.ErrorIs(t, authService.ConfirmTOTP(ctx, userID, "000000x"), ErrWrongMfaCode)
+	require.NoError(t, authService.ConfirmTOTP(ctx, userID, totp.Code(secret, step)))
+}
+
+func TestAuthService_EnrollTOTP_AlreadyEnabled(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	m.accountGetter.On("GetAccountByID", mock.Anything, uint64(3)).
+		Return(&storageDTO.User{Id: 3, Username: "test_user"}, nil)
+	m.mfaCreator.On("SetTOTPSecret", mock.Anything, uint64(3), mock.Anything).Return(storage.ErrAlreadyExists)
+
+	_, err := authService.EnrollTOTP(ctx, 3)
+
+	require.ErrorIs(t, err, ErrMfaEnabled)
+}
+
+func TestAuthService_GenerateRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	m, authService := createAuthServiceMocks(t)
+
+	userID := uint64(3)
+	secret, enrollment := confirmedTOTPSecret(t, authService, userID)
+
+	m.mfaGetter.On("GetTOTP", mock.Anything, userID).Return(enrollment, nil)
+	m.mfaUpdater.On("UseTOTPStep", mock.Anything, userID, mock.Anything).Return(nil)
+
+	var hashes [][]byte
+	m.mfaCreator.On("ReplaceRecoveryCodes", mock.Anything, userID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			hashes = args.Get(2).([][]byte)
+		}).Return(nil)
+
+	codes, err := authService.GenerateRecoveryCodes(ctx, userID, totp.Code(secret, totp.Step(time.Now())))
+
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+	require.Len(t, hashes, RecoveryCodeCount)
+	for i, code := range codes {
+		require.Equal(t, hashToken(normalizeRecoveryCode(code)), hashes[i])
+	}
+}
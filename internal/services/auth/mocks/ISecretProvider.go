@@ -15,23 +15,23 @@ type ISecretProvider struct {
 }
 
 // CreateSecret provides a mock function with given fields: ctx, user
-func (_m *ISecretProvider) CreateSecret(ctx context.Context, user secretsdto.User) ([]byte, error) {
+func (_m *ISecretProvider) CreateSecret(ctx context.Context, user secretsdto.User) (*secretsdto.TokenPair, error) {
 	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateSecret")
 	}
 
-	var r0 []byte
+	var r0 *secretsdto.TokenPair
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, secretsdto.User) ([]byte, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, secretsdto.User) (*secretsdto.TokenPair, error)); ok {
 		return rf(ctx, user)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, secretsdto.User) []byte); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, secretsdto.User) *secretsdto.TokenPair); ok {
 		r0 = rf(ctx, user)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*secretsdto.TokenPair)
 		}
 	}
 
@@ -62,6 +62,102 @@ func (_m *ISecretProvider) DeleteSecret(ctx context.Context, secret []byte) erro
 	return r0
 }
 
+// PublicKeys provides a mock function with given fields: ctx
+func (_m *ISecretProvider) PublicKeys(ctx context.Context) []secretsdto.PublicKey {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublicKeys")
+	}
+
+	var r0 []secretsdto.PublicKey
+	if rf, ok := ret.Get(0).(func(context.Context) []secretsdto.PublicKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]secretsdto.PublicKey)
+		}
+	}
+
+	return r0
+}
+
+// RefreshSecret provides a mock function with given fields: ctx, refreshToken
+func (_m *ISecretProvider) RefreshSecret(ctx context.Context, refreshToken []byte) (*secretsdto.TokenPair, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshSecret")
+	}
+
+	var r0 *secretsdto.TokenPair
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*secretsdto.TokenPair, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *secretsdto.TokenPair); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*secretsdto.TokenPair)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeOtherSessions provides a mock function with given fields: ctx, userID, sessionID
+func (_m *ISecretProvider) RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error) {
+	ret := _m.Called(ctx, userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOtherSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (int64, error)); ok {
+		return rf(ctx, userID, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) int64); ok {
+		r0 = rf(ctx, userID, sessionID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, userID, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: ctx, userID, sessionID
+func (_m *ISecretProvider) RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error {
+	ret := _m.Called(ctx, userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ValidateSecret provides a mock function with given fields: ctx, secret
 func (_m *ISecretProvider) ValidateSecret(ctx context.Context, secret []byte) (*secretsdto.User, error) {
 	ret := _m.Called(ctx, secret)
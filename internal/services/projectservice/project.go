@@ -0,0 +1,214 @@
+// Package projectservice implements projects operations
+package projectservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+type IProjectCreator interface {
+	StorageProjectCreate(ctx context.Context, project storageDTO.Project, ownerID uint64) (uint64, error)
+}
+type IProjectUpdater interface {
+	StorageProjectUpdate(ctx context.Context, project storageDTO.Project, ownerID uint64) error
+}
+type IProjectGetter interface {
+	StorageProjectGetByID(ctx context.Context, projectID uint64, ownerID uint64) (*storageDTO.Project, error)
+	StorageProjectGetList(
+		ctx context.Context,
+		ownerID uint64,
+		includeArchived bool,
+		includeShared bool,
+	) ([]storageDTO.Project, error)
+}
+type IProjectDeleter interface {
+	StorageProjectDeleteByID(
+		ctx context.Context,
+		projectID uint64,
+		ownerID uint64,
+		mode storageDTO.ProjectDeleteMode,
+		reassignTo uint64,
+	) error
+}
+
+type ProjectService struct {
+	logger          *slog.Logger
+	projectsCreator IProjectCreator
+	projectsUpdater IProjectUpdater
+	projectsGetter  IProjectGetter
+	projectsDeleter IProjectDeleter
+}
+
+// MaxNameLength project name limit in characters
+const MaxNameLength = 255
+
+var (
+	ErrAccessDenied    = errors.New("project service: access denied")
+	ErrProjectNotFound = errors.New("project service: project not found")
+	ErrInvalidName     = errors.New("project service: invalid project name")
+	ErrInvalidReassign = errors.New("project service: invalid reassign project")
+	ErrInternal        = errors.New("project service: internal error")
+)
+
+func New(
+	log *slog.Logger,
+	projectsCreator IProjectCreator,
+	projectsUpdater IProjectUpdater,
+	projectsGetter IProjectGetter,
+	projectsDeleter IProjectDeleter,
+) *ProjectService {
+	return &ProjectService{
+		logger:          log.With(slog.String("module", "projectService")),
+		projectsCreator: projectsCreator,
+		projectsUpdater: projectsUpdater,
+		projectsGetter:  projectsGetter,
+		projectsDeleter: projectsDeleter,
+	}
+}
+
+// storageError maps storage layer errors to service errors
+func storageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return ErrProjectNotFound
+	case errors.Is(err, storage.ErrAccessDenied):
+		return ErrAccessDenied
+	case errors.Is(err, storage.ErrReferenceNotFound):
+		return ErrInvalidReassign
+	default:
+		return errors.Join(ErrInternal, err)
+	}
+}
+
+// normalizeName trims project name and checks its length
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+func serviceProject(project storageDTO.Project) serviceDTO.Project {
+	return serviceDTO.Project{
+		ID:         project.Id,
+		OwnerID:    project.OwnerId,
+		Name:       project.Name,
+		IsArchived: project.IsArchived,
+		CreatedAt:  project.CreatedAt,
+		UpdatedAt:  project.UpdatedAt,
+	}
+}
+
+func (s *ProjectService) Create(ctx context.Context, name string, ownerID uint64) (uint64, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.projectsCreator.StorageProjectCreate(ctx, storageDTO.Project{Name: &name}, ownerID)
+	if err != nil {
+		return 0, errors.Join(ErrInternal, err)
+	}
+
+	return id, nil
+}
+
+func (s *ProjectService) GetByID(ctx context.Context, projectID uint64, ownerID uint64) (*serviceDTO.Project, error) {
+	project, err := s.projectsGetter.StorageProjectGetByID(ctx, projectID, ownerID)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	result := serviceProject(*project)
+	return &result, nil
+}
+
+// GetList returns owner projects. includeShared adds projects shared with owner
+func (s *ProjectService) GetList(
+	ctx context.Context,
+	ownerID uint64,
+	includeArchived bool,
+	includeShared bool,
+) ([]serviceDTO.Project, error) {
+	projects, err := s.projectsGetter.StorageProjectGetList(ctx, ownerID, includeArchived, includeShared)
+	if err != nil {
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	result := make([]serviceDTO.Project, 0, len(projects))
+	for _, project := range projects {
+		result = append(result, serviceProject(project))
+	}
+
+	return result, nil
+}
+
+func (s *ProjectService) Rename(ctx context.Context, projectID uint64, name string, ownerID uint64) error {
+	name, err := normalizeName(name)
+	if err != nil {
+		return err
+	}
+
+	err = s.projectsUpdater.StorageProjectUpdate(ctx, storageDTO.Project{
+		Id:   projectID,
+		Name: &name,
+	}, ownerID)
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// Archive hides project from default project list. Archived projects keep their items
+func (s *ProjectService) Archive(ctx context.Context, projectID uint64, archived bool, ownerID uint64) error {
+	err := s.projectsUpdater.StorageProjectUpdate(ctx, storageDTO.Project{
+		Id:         projectID,
+		IsArchived: &archived,
+	}, ownerID)
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// DeleteByID deletes project. Project items are handled according to mode,
+// reassignTo is used by serviceDTO.ProjectDeleteReassign only
+func (s *ProjectService) DeleteByID(
+	ctx context.Context,
+	projectID uint64,
+	ownerID uint64,
+	mode serviceDTO.ProjectDeleteMode,
+	reassignTo uint64,
+) error {
+	var storageMode storageDTO.ProjectDeleteMode
+	switch mode {
+	case serviceDTO.ProjectDeleteUnassign:
+		storageMode = storageDTO.ProjectDeleteUnassign
+	case serviceDTO.ProjectDeleteReassign:
+		if reassignTo == 0 || reassignTo == projectID {
+			return ErrInvalidReassign
+		}
+		storageMode = storageDTO.ProjectDeleteReassign
+	case serviceDTO.ProjectDeleteCascade:
+		storageMode = storageDTO.ProjectDeleteCascade
+	default:
+		return errors.Join(ErrInternal, errors.New("unknown delete mode"))
+	}
+
+	err := s.projectsDeleter.StorageProjectDeleteByID(ctx, projectID, ownerID, storageMode, reassignTo)
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
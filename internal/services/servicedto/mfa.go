@@ -0,0 +1,23 @@
+package servicedto
+
+import "time"
+
+// LoginResult tokens of started session, or MFA challenge if account requires second factor
+type LoginResult struct {
+	Tokens       *TokenPair
+	MfaChallenge *MfaChallenge
+}
+
+// MfaChallenge token exchanged with MFA code for session tokens
+type MfaChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// TOTPEnrollment secret to add into authenticator app
+type TOTPEnrollment struct {
+	// Secret base32 encoded secret for manual entry
+	Secret string
+	// URI otpauth URI, usually shown as QR code
+	URI string
+}
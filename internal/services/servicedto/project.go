@@ -0,0 +1,41 @@
+package servicedto
+
+import "time"
+
+// Project service DTO
+type Project struct {
+	ID         uint64
+	OwnerID    uint64
+	Name       *string
+	IsArchived *bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// GetName returns name or empty string
+func (p Project) GetName() string {
+	if p.Name == nil {
+		return ""
+	}
+	return *p.Name
+}
+
+// GetIsArchived returns archived flag or false
+func (p Project) GetIsArchived() bool {
+	if p.IsArchived == nil {
+		return false
+	}
+	return *p.IsArchived
+}
+
+// ProjectDeleteMode what happens to project items on project delete
+type ProjectDeleteMode int
+
+const (
+	// ProjectDeleteUnassign keeps items without project
+	ProjectDeleteUnassign ProjectDeleteMode = iota
+	// ProjectDeleteReassign moves items to another project
+	ProjectDeleteReassign
+	// ProjectDeleteCascade deletes items with project
+	ProjectDeleteCascade
+)
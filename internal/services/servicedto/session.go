@@ -0,0 +1,21 @@
+package servicedto
+
+import "time"
+
+// Client describes device which logs in
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// Session login of user
+type Session struct {
+	ID         uint64
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	// Current session of the caller
+	Current bool
+}
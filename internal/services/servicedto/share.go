@@ -0,0 +1,49 @@
+package servicedto
+
+import "time"
+
+// ShareResourceType kind of shared resource
+type ShareResourceType int
+
+const (
+	ShareResourceTask ShareResourceType = iota + 1
+	ShareResourceProject
+)
+
+// ShareRole access level of user. Greater role includes lesser ones
+type ShareRole int
+
+const (
+	ShareRoleNone ShareRole = iota
+	ShareRoleViewer
+	ShareRoleEditor
+	ShareRoleOwner
+)
+
+// ShareResource shared task or project
+type ShareResource struct {
+	Type ShareResourceType
+	ID   uint64
+}
+
+// ShareMember service DTO
+type ShareMember struct {
+	UserID    uint64
+	Username  *string
+	Role      ShareRole
+	CreatedAt time.Time
+}
+
+// GetUsername returns username or empty string
+func (m ShareMember) GetUsername() string {
+	if m.Username == nil {
+		return ""
+	}
+	return *m.Username
+}
+
+// ShareMemberList members of shared resource
+type ShareMemberList struct {
+	OwnerID uint64
+	Members []ShareMember
+}
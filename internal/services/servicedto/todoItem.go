@@ -1,10 +1,208 @@
 // Package servicedto contains serviceDTO models
 package servicedto
 
-//ToDoItem service DTO
+import "time"
+
+// ToDoItemPriority service item priority
+type ToDoItemPriority int
+
+const (
+	ToDoItemPriorityLow ToDoItemPriority = iota + 1
+	ToDoItemPriorityNormal
+	ToDoItemPriorityHigh
+	ToDoItemPriorityUrgent
+)
+
+// ToDoItem service DTO
 type ToDoItem struct {
 	ID         uint64
 	Title      *string
 	IsComplete *bool
 	OwnerID    uint64
+	// ProjectID pointer to zero means item without project
+	ProjectID *uint64
+	// ParentID nil means top level item
+	ParentID     *uint64
+	Depth        int
+	Position     int64
+	AutoComplete *bool
+	// DoneCount and TotalCount count direct children of item
+	DoneCount  int64
+	TotalCount int64
+	Notes      *string
+	Priority   *ToDoItemPriority
+	// DueAt pointer to zero time clears due date on update
+	DueAt       *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+	// Version incremented on every change. Non-zero version on update must match the stored one
+	Version int64
+	// DeletedAt time item was moved to trash, nil for active items
+	DeletedAt *time.Time
+	// Recurrence pointer to empty rule clears recurrence on update
+	Recurrence *ToDoItemRecurrence
+}
+
+// ToDoItemRecurrence repeats item, completing recurring item creates its next occurrence
+type ToDoItemRecurrence struct {
+	// Rule RRULE value like FREQ=WEEKLY;BYDAY=MO
+	Rule string
+	// TimeZone IANA name of location keeping wall clock time of due date, UTC if empty
+	TimeZone string
+}
+
+// GetTitle returns title or empty string
+func (i ToDoItem) GetTitle() string {
+	if i.Title == nil {
+		return ""
+	}
+	return *i.Title
+}
+
+// GetIsComplete returns completion flag or false
+func (i ToDoItem) GetIsComplete() bool {
+	if i.IsComplete == nil {
+		return false
+	}
+	return *i.IsComplete
+}
+
+// GetNotes returns notes or empty string
+func (i ToDoItem) GetNotes() string {
+	if i.Notes == nil {
+		return ""
+	}
+	return *i.Notes
+}
+
+// GetAutoComplete returns auto complete flag or false
+func (i ToDoItem) GetAutoComplete() bool {
+	if i.AutoComplete == nil {
+		return false
+	}
+	return *i.AutoComplete
+}
+
+// GetParentID returns parent id or zero
+func (i ToDoItem) GetParentID() uint64 {
+	if i.ParentID == nil {
+		return 0
+	}
+	return *i.ParentID
+}
+
+// GetProjectID returns project id or zero
+func (i ToDoItem) GetProjectID() uint64 {
+	if i.ProjectID == nil {
+		return 0
+	}
+	return *i.ProjectID
+}
+
+// ToDoItemOccurrencesQuery selects occurrences of item recurrence or of recurrence started at Start
+type ToDoItemOccurrencesQuery struct {
+	// ItemID selects recurrence of item started at its due date, Recurrence and Start are ignored then
+	ItemID     uint64
+	Recurrence ToDoItemRecurrence
+	Start      time.Time
+	Count      int
+}
+
+// ToDoItemSortOrder service list order
+type ToDoItemSortOrder int
+
+const (
+	ToDoItemSortByIDAsc ToDoItemSortOrder = iota
+	ToDoItemSortByIDDesc
+	ToDoItemSortByTitleAsc
+	ToDoItemSortByTitleDesc
+	ToDoItemSortByDueAtAsc
+	ToDoItemSortByDueAtDesc
+	ToDoItemSortByPriorityAsc
+	ToDoItemSortByPriorityDesc
+	ToDoItemSortByCreatedAtAsc
+	ToDoItemSortByCreatedAtDesc
+	ToDoItemSortByUpdatedAtAsc
+	ToDoItemSortByUpdatedAtDesc
+	ToDoItemSortByPositionAsc
+)
+
+// ToDoItemListQuery service list query
+type ToDoItemListQuery struct {
+	// ProjectID pointer to zero selects items without project
+	ProjectID *uint64
+	// ParentID selects children of item. Nil selects top level items
+	ParentID *uint64
+	// IncludeShared adds items shared with caller
+	IncludeShared bool
+	IsComplete    *bool
+	TitleContains *string
+	Priorities    []ToDoItemPriority
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	// Overdue selects not completed items due before now
+	Overdue   bool
+	SortOrder ToDoItemSortOrder
+	// PageSize zero selects DefaultPageSize
+	PageSize int
+	// Unbounded returns all items when PageSize is zero
+	Unbounded bool
+	// PageToken must be issued for the same filters and sort order
+	PageToken string
+}
+
+// SubtaskDeleteMode what happens to children of deleted item
+type SubtaskDeleteMode int
+
+const (
+	// SubtaskDeleteReparent moves children to parent of deleted item
+	SubtaskDeleteReparent SubtaskDeleteMode = iota
+	// SubtaskDeleteCascade deletes children with item
+	SubtaskDeleteCascade
+)
+
+// ToDoItemPage one page of items
+type ToDoItemPage struct {
+	Items         []ToDoItem
+	NextPageToken string
+}
+
+// ToDoItemEventType kind of item change
+type ToDoItemEventType int
+
+const (
+	ToDoItemCreated ToDoItemEventType = iota + 1
+	ToDoItemUpdated
+	ToDoItemDeleted
+)
+
+// ToDoItemEvent item change notification
+type ToDoItemEvent struct {
+	Type ToDoItemEventType
+	Item ToDoItem
+}
+
+// ToDoItemMutationType kind of batch operation
+type ToDoItemMutationType int
+
+const (
+	ToDoItemMutationCreate ToDoItemMutationType = iota
+	ToDoItemMutationUpdate
+	ToDoItemMutationDelete
+)
+
+// ToDoItemMutation batch operation on item
+type ToDoItemMutation struct {
+	Type ToDoItemMutationType
+	Item ToDoItem
+	// DeleteMode mode of delete operation
+	DeleteMode SubtaskDeleteMode
+}
+
+// ToDoItemMutationResult outcome of batch operation
+type ToDoItemMutationResult struct {
+	// ID created, changed or deleted item
+	ID  uint64
+	Err error
 }
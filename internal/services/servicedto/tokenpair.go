@@ -0,0 +1,11 @@
+package servicedto
+
+import "time"
+
+// TokenPair access token with refresh token renewing it
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
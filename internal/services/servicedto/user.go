@@ -1,7 +1,8 @@
 package servicedto
 
 type User struct {
-	UserID   *uint64
-	Username *string
-	Password string
+	UserID    *uint64
+	Username  *string
+	Password  string
+	SessionID *uint64
 }
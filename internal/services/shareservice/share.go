@@ -0,0 +1,300 @@
+// Package shareservice implements sharing of tasks and projects with other users
+package shareservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+type IShareMemberCreator interface {
+	StorageShareMemberCreate(ctx context.Context, member storageDTO.ShareMember) error
+}
+type IShareMemberUpdater interface {
+	StorageShareMemberUpdate(ctx context.Context, member storageDTO.ShareMember) error
+}
+type IShareMemberGetter interface {
+	StorageShareMemberGetList(ctx context.Context, resource storageDTO.ShareResource) ([]storageDTO.ShareMember, error)
+}
+type IShareMemberDeleter interface {
+	StorageShareMemberDelete(ctx context.Context, resource storageDTO.ShareResource, userID uint64) error
+}
+
+// IAccessResolver resolves roles of users on shared resources
+type IAccessResolver interface {
+	StorageToDoItemAccess(ctx context.Context, itemID uint64, userID uint64) (*storageDTO.Access, error)
+	StorageProjectAccess(ctx context.Context, projectID uint64, userID uint64) (*storageDTO.Access, error)
+}
+
+type IAccountGetter interface {
+	GetAccountByUsername(ctx context.Context, username string) (*storageDTO.User, error)
+}
+
+type ShareService struct {
+	logger         *slog.Logger
+	membersCreator IShareMemberCreator
+	membersUpdater IShareMemberUpdater
+	membersGetter  IShareMemberGetter
+	membersDeleter IShareMemberDeleter
+	accessResolver IAccessResolver
+	accountGetter  IAccountGetter
+}
+
+var (
+	ErrAccessDenied     = errors.New("share service: access denied")
+	ErrResourceNotFound = errors.New("share service: resource not found")
+	ErrUserNotFound     = errors.New("share service: user not found")
+	ErrMemberNotFound   = errors.New("share service: member not found")
+	ErrAlreadyMember    = errors.New("share service: user is already a member")
+	ErrInvalidRole      = errors.New("share service: invalid role")
+	ErrInvalidResource  = errors.New("share service: invalid resource type")
+	ErrInvalidMember    = errors.New("share service: owner can not be a member")
+	ErrInternal         = errors.New("share service: internal error")
+)
+
+func New(
+	log *slog.Logger,
+	membersCreator IShareMemberCreator,
+	membersUpdater IShareMemberUpdater,
+	membersGetter IShareMemberGetter,
+	membersDeleter IShareMemberDeleter,
+	accessResolver IAccessResolver,
+	accountGetter IAccountGetter,
+) *ShareService {
+	return &ShareService{
+		logger:         log.With(slog.String("module", "shareService")),
+		membersCreator: membersCreator,
+		membersUpdater: membersUpdater,
+		membersGetter:  membersGetter,
+		membersDeleter: membersDeleter,
+		accessResolver: accessResolver,
+		accountGetter:  accountGetter,
+	}
+}
+
+func storageResource(resource serviceDTO.ShareResource) (storageDTO.ShareResource, error) {
+	switch resource.Type {
+	case serviceDTO.ShareResourceTask:
+		return storageDTO.ShareResource{Type: storageDTO.ShareResourceTask, ID: resource.ID}, nil
+	case serviceDTO.ShareResourceProject:
+		return storageDTO.ShareResource{Type: storageDTO.ShareResourceProject, ID: resource.ID}, nil
+	default:
+		return storageDTO.ShareResource{}, ErrInvalidResource
+	}
+}
+
+func storageRole(role serviceDTO.ShareRole) (storageDTO.ShareRole, error) {
+	switch role {
+	case serviceDTO.ShareRoleViewer:
+		return storageDTO.ShareRoleViewer, nil
+	case serviceDTO.ShareRoleEditor:
+		return storageDTO.ShareRoleEditor, nil
+	case serviceDTO.ShareRoleOwner:
+		return storageDTO.ShareRoleOwner, nil
+	default:
+		return storageDTO.ShareRoleNone, ErrInvalidRole
+	}
+}
+
+// access returns role of user on resource. Resources unknown to user are reported as not found
+func (s *ShareService) access(
+	ctx context.Context,
+	resource storageDTO.ShareResource,
+	userID uint64,
+) (*storageDTO.Access, error) {
+	var access *storageDTO.Access
+	var err error
+	if resource.Type == storageDTO.ShareResourceTask {
+		access, err = s.accessResolver.StorageToDoItemAccess(ctx, resource.ID, userID)
+	} else {
+		access, err = s.accessResolver.StorageProjectAccess(ctx, resource.ID, userID)
+	}
+
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	if access.Role == storageDTO.ShareRoleNone {
+		return nil, ErrResourceNotFound
+	}
+
+	return access, nil
+}
+
+// manage checks caller may manage resource members and returns resource owner
+func (s *ShareService) manage(
+	ctx context.Context,
+	resource serviceDTO.ShareResource,
+	callerID uint64,
+) (storageDTO.ShareResource, uint64, error) {
+	storageRes, err := storageResource(resource)
+	if err != nil {
+		return storageRes, 0, err
+	}
+
+	access, err := s.access(ctx, storageRes, callerID)
+	if err != nil {
+		return storageRes, 0, err
+	}
+
+	if access.Role < storageDTO.ShareRoleOwner {
+		return storageRes, 0, ErrAccessDenied
+	}
+
+	return storageRes, access.OwnerID, nil
+}
+
+// Invite grants user with username a role on resource. Caller must be resource owner
+func (s *ShareService) Invite(
+	ctx context.Context,
+	resource serviceDTO.ShareResource,
+	username string,
+	role serviceDTO.ShareRole,
+	callerID uint64,
+) (*serviceDTO.ShareMember, error) {
+	memberRole, err := storageRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	storageRes, ownerID, err := s.manage(ctx, resource, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.accountGetter.GetAccountByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	if user.Id == ownerID {
+		return nil, ErrInvalidMember
+	}
+
+	err = s.membersCreator.StorageShareMemberCreate(ctx, storageDTO.ShareMember{
+		Resource: storageRes,
+		UserID:   user.Id,
+		Role:     memberRole,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	return &serviceDTO.ShareMember{
+		UserID:   user.Id,
+		Username: &user.Username,
+		Role:     role,
+	}, nil
+}
+
+// ChangeRole changes role of resource member. Caller must be resource owner
+func (s *ShareService) ChangeRole(
+	ctx context.Context,
+	resource serviceDTO.ShareResource,
+	userID uint64,
+	role serviceDTO.ShareRole,
+	callerID uint64,
+) error {
+	memberRole, err := storageRole(role)
+	if err != nil {
+		return err
+	}
+
+	storageRes, _, err := s.manage(ctx, resource, callerID)
+	if err != nil {
+		return err
+	}
+
+	err = s.membersUpdater.StorageShareMemberUpdate(ctx, storageDTO.ShareMember{
+		Resource: storageRes,
+		UserID:   userID,
+		Role:     memberRole,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrMemberNotFound
+		}
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Revoke removes member of resource. Caller must be resource owner or the member itself
+func (s *ShareService) Revoke(
+	ctx context.Context,
+	resource serviceDTO.ShareResource,
+	userID uint64,
+	callerID uint64,
+) error {
+	var storageRes storageDTO.ShareResource
+	var err error
+	if userID == callerID {
+		storageRes, err = storageResource(resource)
+	} else {
+		storageRes, _, err = s.manage(ctx, resource, callerID)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.membersDeleter.StorageShareMemberDelete(ctx, storageRes, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrMemberNotFound
+		}
+		return errors.Join(ErrInternal, err)
+	}
+
+	return nil
+}
+
+// ListMembers returns owner and members of resource. Caller must have any role on resource
+func (s *ShareService) ListMembers(
+	ctx context.Context,
+	resource serviceDTO.ShareResource,
+	callerID uint64,
+) (*serviceDTO.ShareMemberList, error) {
+	storageRes, err := storageResource(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.access(ctx, storageRes, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.membersGetter.StorageShareMemberGetList(ctx, storageRes)
+	if err != nil {
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	result := &serviceDTO.ShareMemberList{
+		OwnerID: access.OwnerID,
+		Members: make([]serviceDTO.ShareMember, 0, len(members)),
+	}
+	for _, member := range members {
+		result.Members = append(result.Members, serviceDTO.ShareMember{
+			UserID:    member.UserID,
+			Username:  member.Username,
+			Role:      serviceDTO.ShareRole(member.Role),
+			CreatedAt: member.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
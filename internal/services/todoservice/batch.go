@@ -0,0 +1,169 @@
+package todoservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+// preparedMutation mutation checked against caller roles
+type preparedMutation struct {
+	index    int
+	mutation storageDTO.ToDoItemMutation
+	deleted  *deletedItem
+}
+
+// Batch applies mutations of caller in one transaction and returns result of each mutation.
+// Atomic batch is applied only if every mutation succeeds, otherwise failed mutations are skipped
+func (s *TodoService) Batch(
+	ctx context.Context,
+	mutations []serviceDTO.ToDoItemMutation,
+	callerID uint64,
+	atomic bool,
+) ([]serviceDTO.ToDoItemMutationResult, error) {
+	if len(mutations) == 0 || len(mutations) > s.batchMaxSize {
+		return nil, fmt.Errorf("%w: batch size must be from 1 to %d", ErrInvalidBatch, s.batchMaxSize)
+	}
+
+	results := make([]serviceDTO.ToDoItemMutationResult, len(mutations))
+	prepared := make([]preparedMutation, 0, len(mutations))
+
+	for i, mutation := range mutations {
+		op, err := s.prepareMutation(ctx, mutation, callerID)
+		if err != nil {
+			results[i].Err = err
+			if atomic {
+				return abortBatch(results, i), nil
+			}
+			continue
+		}
+
+		op.index = i
+		prepared = append(prepared, *op)
+	}
+
+	if len(prepared) == 0 {
+		return results, nil
+	}
+
+	storageMutations := make([]storageDTO.ToDoItemMutation, 0, len(prepared))
+	for _, op := range prepared {
+		storageMutations = append(storageMutations, op.mutation)
+	}
+
+	storageResults, err := s.todoItemsBatcher.StorageToDoItemBatch(ctx, storageMutations, atomic)
+	if err != nil {
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	for i, op := range prepared {
+		result := storageResults[i]
+		if result.Err != nil {
+			if op.mutation.Type == storageDTO.ToDoItemMutationCreate {
+				results[op.index].Err = createError(op.mutation.Item, result.Err)
+			} else {
+				results[op.index].Err = storageError(result.Err)
+			}
+			continue
+		}
+
+		results[op.index].ID = result.ID
+		s.publishMutation(ctx, op, mutations[op.index], result)
+	}
+
+	return results, nil
+}
+
+// abortBatch marks every mutation except failed one as aborted
+func abortBatch(results []serviceDTO.ToDoItemMutationResult, failed int) []serviceDTO.ToDoItemMutationResult {
+	for i := range results {
+		if i != failed {
+			results[i] = serviceDTO.ToDoItemMutationResult{Err: ErrBatchAborted}
+		}
+	}
+	return results
+}
+
+// prepareMutation checks caller role required by mutation and converts it to storage
+func (s *TodoService) prepareMutation(
+	ctx context.Context,
+	mutation serviceDTO.ToDoItemMutation,
+	callerID uint64,
+) (*preparedMutation, error) {
+	switch mutation.Type {
+	case serviceDTO.ToDoItemMutationCreate:
+		err := checkNewRecurrence(&mutation.Item)
+		if err != nil {
+			return nil, err
+		}
+
+		ownerID, err := s.createOwner(ctx, mutation.Item, callerID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &preparedMutation{mutation: storageDTO.ToDoItemMutation{
+			Type:    storageDTO.ToDoItemMutationCreate,
+			Item:    storageNewItem(mutation.Item),
+			OwnerID: ownerID,
+		}}, nil
+	case serviceDTO.ToDoItemMutationUpdate:
+		err := checkRecurrence(&mutation.Item)
+		if err != nil {
+			return nil, err
+		}
+
+		ownerID, err := s.updateOwner(ctx, mutation.Item, callerID)
+		if err != nil {
+			return nil, err
+		}
+
+		update, err := s.updateMutation(ctx, mutation.Item, ownerID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &preparedMutation{mutation: update}, nil
+	case serviceDTO.ToDoItemMutationDelete:
+		deleted, err := s.prepareDelete(ctx, mutation.Item.ID, callerID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &preparedMutation{
+			mutation: storageDTO.ToDoItemMutation{
+				Type:       storageDTO.ToDoItemMutationDelete,
+				Item:       storageDTO.ToDoItem{Id: mutation.Item.ID},
+				OwnerID:    deleted.ownerID,
+				DeleteMode: storageDTO.SubtaskDeleteMode(mutation.DeleteMode),
+			},
+			deleted: deleted,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown mutation type %d", ErrInvalidBatch, mutation.Type)
+	}
+}
+
+// publishMutation notifies about applied mutation
+func (s *TodoService) publishMutation(
+	ctx context.Context,
+	op preparedMutation,
+	mutation serviceDTO.ToDoItemMutation,
+	result storageDTO.ToDoItemMutationResult,
+) {
+	switch op.mutation.Type {
+	case storageDTO.ToDoItemMutationCreate:
+		mutation.Item.ID = result.ID
+		s.publish(ctx, serviceDTO.ToDoItemCreated, mutation.Item, op.mutation.OwnerID)
+	case storageDTO.ToDoItemMutationUpdate:
+		s.publish(ctx, serviceDTO.ToDoItemUpdated, mutation.Item, op.mutation.OwnerID)
+		if op.mutation.Next != nil {
+			s.publish(ctx, serviceDTO.ToDoItemCreated, serviceDTO.ToDoItem{ID: result.NextID}, op.mutation.OwnerID)
+		}
+	case storageDTO.ToDoItemMutationDelete:
+		s.publishDeleted(ctx, result.ID, op.deleted)
+	}
+}
@@ -0,0 +1,120 @@
+package todoservice
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+// pageToken opaque keyset cursor passed to clients
+type pageToken struct {
+	SortOrder serviceDTO.ToDoItemSortOrder `json:"s"`
+	ID        uint64                       `json:"i"`
+	Key       *string                      `json:"k,omitempty"`
+	// Filter fingerprint of query filters the token was issued for
+	Filter string `json:"f,omitempty"`
+}
+
+// pageFilter filters of list query which must not change between pages
+type pageFilter struct {
+	ProjectID     *uint64                       `json:"p,omitempty"`
+	ParentID      *uint64                       `json:"pa,omitempty"`
+	IncludeShared bool                          `json:"sh,omitempty"`
+	IsComplete    *bool                         `json:"c,omitempty"`
+	TitleContains *string                       `json:"t,omitempty"`
+	Priorities    []serviceDTO.ToDoItemPriority `json:"pr,omitempty"`
+	DueAfter      *time.Time                    `json:"da,omitempty"`
+	DueBefore     *time.Time                    `json:"db,omitempty"`
+	Overdue       bool                          `json:"o,omitempty"`
+}
+
+// filterFingerprint returns short hash of query filters
+func filterFingerprint(query serviceDTO.ToDoItemListQuery) string {
+	data, err := json.Marshal(pageFilter{
+		ProjectID:     query.ProjectID,
+		ParentID:      query.ParentID,
+		IncludeShared: query.IncludeShared,
+		IsComplete:    query.IsComplete,
+		TitleContains: query.TitleContains,
+		Priorities:    query.Priorities,
+		DueAfter:      query.DueAfter,
+		DueBefore:     query.DueBefore,
+		Overdue:       query.Overdue,
+	})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func encodePageToken(query serviceDTO.ToDoItemListQuery, cursor *storageDTO.ToDoItemCursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(pageToken{
+		SortOrder: query.SortOrder,
+		ID:        cursor.ID,
+		Key:       cursor.Key,
+		Filter:    filterFingerprint(query),
+	})
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken returns storage cursor of query page token.
+// Token must be issued for the same sort order and filters
+func decodePageToken(query serviceDTO.ToDoItemListQuery) (*storageDTO.ToDoItemCursor, error) {
+	token := query.PageToken
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	var decoded pageToken
+	err = json.Unmarshal(data, &decoded)
+	if err != nil || decoded.SortOrder != query.SortOrder || decoded.Filter != filterFingerprint(query) {
+		return nil, ErrInvalidPageToken
+	}
+
+	return &storageDTO.ToDoItemCursor{
+		ID:  decoded.ID,
+		Key: decoded.Key,
+	}, nil
+}
+
+var storageSortOrders = map[serviceDTO.ToDoItemSortOrder]storageDTO.ToDoItemSortOrder{
+	serviceDTO.ToDoItemSortByIDAsc:         storageDTO.ToDoItemSortByIDAsc,
+	serviceDTO.ToDoItemSortByIDDesc:        storageDTO.ToDoItemSortByIDDesc,
+	serviceDTO.ToDoItemSortByTitleAsc:      storageDTO.ToDoItemSortByTitleAsc,
+	serviceDTO.ToDoItemSortByTitleDesc:     storageDTO.ToDoItemSortByTitleDesc,
+	serviceDTO.ToDoItemSortByDueAtAsc:      storageDTO.ToDoItemSortByDueAtAsc,
+	serviceDTO.ToDoItemSortByDueAtDesc:     storageDTO.ToDoItemSortByDueAtDesc,
+	serviceDTO.ToDoItemSortByPriorityAsc:   storageDTO.ToDoItemSortByPriorityAsc,
+	serviceDTO.ToDoItemSortByPriorityDesc:  storageDTO.ToDoItemSortByPriorityDesc,
+	serviceDTO.ToDoItemSortByCreatedAtAsc:  storageDTO.ToDoItemSortByCreatedAtAsc,
+	serviceDTO.ToDoItemSortByCreatedAtDesc: storageDTO.ToDoItemSortByCreatedAtDesc,
+	serviceDTO.ToDoItemSortByUpdatedAtAsc:  storageDTO.ToDoItemSortByUpdatedAtAsc,
+	serviceDTO.ToDoItemSortByUpdatedAtDesc: storageDTO.ToDoItemSortByUpdatedAtDesc,
+	serviceDTO.ToDoItemSortByPositionAsc:   storageDTO.ToDoItemSortByPositionAsc,
+}
+
+func storageSortOrder(order serviceDTO.ToDoItemSortOrder) storageDTO.ToDoItemSortOrder {
+	if storageOrder, ok := storageSortOrders[order]; ok {
+		return storageOrder
+	}
+	return storageDTO.ToDoItemSortByIDAsc
+}
@@ -0,0 +1,90 @@
+package todoservice
+
+import (
+	"encoding/base64"
+	"testing"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageToken_RoundTrip(t *testing.T) {
+	key := "title"
+	cursor := &storageDTO.ToDoItemCursor{ID: 42, Key: &key}
+
+	isComplete := false
+	query := serviceDTO.ToDoItemListQuery{IsComplete: &isComplete, SortOrder: serviceDTO.ToDoItemSortByTitleDesc}
+
+	query.PageToken = encodePageToken(query, cursor)
+	require.NotEmpty(t, query.PageToken)
+
+	decoded, err := decodePageToken(query)
+
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+}
+
+func TestPageToken_RoundTrip_NullKey(t *testing.T) {
+	cursor := &storageDTO.ToDoItemCursor{ID: 42}
+
+	query := serviceDTO.ToDoItemListQuery{SortOrder: serviceDTO.ToDoItemSortByDueAtAsc}
+
+	query.PageToken = encodePageToken(query, cursor)
+	decoded, err := decodePageToken(query)
+
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+}
+
+func TestPageToken_Empty(t *testing.T) {
+	require.Empty(t, encodePageToken(serviceDTO.ToDoItemListQuery{}, nil))
+
+	decoded, err := decodePageToken(serviceDTO.ToDoItemListQuery{})
+
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+}
+
+func TestPageToken_Invalid(t *testing.T) {
+	title := "title"
+	isComplete := true
+	cursor := &storageDTO.ToDoItemCursor{ID: 1}
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "not base64",
+			token: "???",
+		},
+		{
+			name:  "not json",
+			token: base64.RawURLEncoding.EncodeToString([]byte("cursor")),
+		},
+		{
+			name:  "another sort order",
+			token: encodePageToken(serviceDTO.ToDoItemListQuery{SortOrder: serviceDTO.ToDoItemSortByTitleAsc}, cursor),
+		},
+		{
+			name:  "another completion filter",
+			token: encodePageToken(serviceDTO.ToDoItemListQuery{IsComplete: &isComplete}, cursor),
+		},
+		{
+			name:  "another title filter",
+			token: encodePageToken(serviceDTO.ToDoItemListQuery{TitleContains: &title}, cursor),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := decodePageToken(serviceDTO.ToDoItemListQuery{
+				SortOrder: serviceDTO.ToDoItemSortByIDAsc,
+				PageToken: testCase.token,
+			})
+
+			require.ErrorIs(t, err, ErrInvalidPageToken)
+		})
+	}
+}
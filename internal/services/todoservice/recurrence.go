@@ -0,0 +1,248 @@
+package todoservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/rrule"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+)
+
+func storageRecurrence(recurrence *serviceDTO.ToDoItemRecurrence) *storageDTO.ToDoItemRecurrence {
+	if recurrence == nil {
+		return nil
+	}
+	return &storageDTO.ToDoItemRecurrence{
+		Rule:     recurrence.Rule,
+		TimeZone: recurrence.TimeZone,
+	}
+}
+
+func serviceRecurrence(recurrence *storageDTO.ToDoItemRecurrence) *serviceDTO.ToDoItemRecurrence {
+	if recurrence == nil {
+		return nil
+	}
+	return &serviceDTO.ToDoItemRecurrence{
+		Rule:     recurrence.Rule,
+		TimeZone: recurrence.TimeZone,
+	}
+}
+
+// parseRecurrence returns rule and location of recurrence
+func parseRecurrence(recurrence serviceDTO.ToDoItemRecurrence) (rrule.Rule, *time.Location, error) {
+	rule, err := rrule.Parse(recurrence.Rule)
+	if err != nil {
+		return rule, nil, errors.Join(ErrInvalidRecurrence, err)
+	}
+
+	loc, err := time.LoadLocation(recurrence.TimeZone)
+	if err != nil {
+		return rule, nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, recurrence.TimeZone)
+	}
+
+	return rule, loc, nil
+}
+
+// checkRecurrence checks recurrence set by item and brings its rule to canonical form.
+// Empty rule clears recurrence
+func checkRecurrence(item *serviceDTO.ToDoItem) error {
+	if item.Recurrence == nil {
+		return nil
+	}
+
+	if item.Recurrence.Rule == "" {
+		item.Recurrence = &serviceDTO.ToDoItemRecurrence{}
+		return nil
+	}
+
+	rule, _, err := parseRecurrence(*item.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	item.Recurrence = &serviceDTO.ToDoItemRecurrence{
+		Rule:     rule.String(),
+		TimeZone: item.Recurrence.TimeZone,
+	}
+	return nil
+}
+
+// checkNewRecurrence checks recurrence of new item, recurring item must have due date
+func checkNewRecurrence(item *serviceDTO.ToDoItem) error {
+	err := checkRecurrence(item)
+	if err != nil {
+		return err
+	}
+
+	if item.Recurrence != nil && item.Recurrence.Rule != "" && (item.DueAt == nil || item.DueAt.IsZero()) {
+		return fmt.Errorf("%w: recurring item requires due date", ErrInvalidRecurrence)
+	}
+	return nil
+}
+
+// nextOccurrence returns copy of item due at its next occurrence which continues the recurrence.
+// Returns nil if item does not recur or its recurrence ended
+func (s *TodoService) nextOccurrence(item storageDTO.ToDoItem) *storageDTO.ToDoItem {
+	if item.Recurrence == nil || item.Recurrence.Rule == "" || item.DueAt == nil || item.DueAt.IsZero() {
+		return nil
+	}
+
+	rule, loc, err := parseRecurrence(*serviceRecurrence(item.Recurrence))
+	if err != nil {
+		s.logger.Warn("stored recurrence is invalid",
+			slog.String("method", "nextOccurrence"),
+			slog.Uint64("itemID", item.Id),
+			slog.Any("err", err),
+		)
+		return nil
+	}
+
+	dueAt, rest, ok := rule.Next(item.DueAt.In(loc))
+	if !ok {
+		return nil
+	}
+
+	return &storageDTO.ToDoItem{
+		Title:        item.Title,
+		ProjectID:    item.ProjectID,
+		ParentID:     item.ParentID,
+		AutoComplete: item.AutoComplete,
+		Notes:        item.Notes,
+		Priority:     item.Priority,
+		DueAt:        &dueAt,
+		Recurrence: &storageDTO.ToDoItemRecurrence{
+			Rule:     rest.String(),
+			TimeZone: item.Recurrence.TimeZone,
+		},
+	}
+}
+
+// applyUpdate returns item with fields set by update
+func applyUpdate(item storageDTO.ToDoItem, update storageDTO.ToDoItem) storageDTO.ToDoItem {
+	if update.Title != nil {
+		item.Title = update.Title
+	}
+	if update.ProjectID != nil {
+		item.ProjectID = update.ProjectID
+	}
+	if update.AutoComplete != nil {
+		item.AutoComplete = update.AutoComplete
+	}
+	if update.Notes != nil {
+		item.Notes = update.Notes
+	}
+	if update.Priority != nil {
+		item.Priority = update.Priority
+	}
+	if update.DueAt != nil {
+		item.DueAt = update.DueAt
+	}
+	if update.Recurrence != nil {
+		item.Recurrence = update.Recurrence
+	}
+	return item
+}
+
+// updateMutation returns storage mutation updating item of owner.
+// Update completing recurring item also creates its next occurrence in the same mutation.
+// The recurrence moves to the next occurrence, so completing item again does not repeat it.
+// Such update is applied only if item was not changed since it was read, ErrVersionConflict is returned otherwise
+func (s *TodoService) updateMutation(
+	ctx context.Context,
+	item serviceDTO.ToDoItem,
+	ownerID uint64,
+) (storageDTO.ToDoItemMutation, error) {
+	mutation := storageDTO.ToDoItemMutation{
+		Type:    storageDTO.ToDoItemMutationUpdate,
+		Item:    storageUpdatedItem(item, ownerID),
+		OwnerID: ownerID,
+	}
+	if !item.GetIsComplete() {
+		return mutation, nil
+	}
+
+	current, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, item.ID, ownerID)
+	if err != nil {
+		return mutation, storageError(err)
+	}
+
+	if current.IsComplete != nil && *current.IsComplete {
+		return mutation, nil
+	}
+
+	mutation.Next = s.nextOccurrence(applyUpdate(*current, mutation.Item))
+	if mutation.Next == nil {
+		return mutation, nil
+	}
+
+	mutation.Item.Recurrence = &storageDTO.ToDoItemRecurrence{}
+	if mutation.Item.Version == 0 {
+		mutation.Item.Version = current.Version
+	}
+	return mutation, nil
+}
+
+// completeRecurring applies update mutation completing recurring item and creating its next occurrence
+func (s *TodoService) completeRecurring(
+	ctx context.Context,
+	item serviceDTO.ToDoItem,
+	mutation storageDTO.ToDoItemMutation,
+) error {
+	results, err := s.todoItemsBatcher.StorageToDoItemBatch(ctx, []storageDTO.ToDoItemMutation{mutation}, true)
+	if err != nil {
+		return errors.Join(ErrInternal, err)
+	}
+
+	if results[0].Err != nil {
+		return storageError(results[0].Err)
+	}
+
+	s.publish(ctx, serviceDTO.ToDoItemUpdated, item, mutation.OwnerID)
+	s.publish(ctx, serviceDTO.ToDoItemCreated, serviceDTO.ToDoItem{ID: results[0].NextID}, mutation.OwnerID)
+
+	return nil
+}
+
+// Occurrences returns upcoming occurrences of recurrence of item if caller is at least its viewer,
+// or of recurrence of query started at Start. The series start is the first occurrence
+func (s *TodoService) Occurrences(
+	ctx context.Context,
+	query serviceDTO.ToDoItemOccurrencesQuery,
+	callerID uint64,
+) ([]time.Time, error) {
+	recurrence := query.Recurrence
+	start := query.Start
+
+	if query.ItemID != 0 {
+		item, err := s.GetByID(ctx, query.ItemID, callerID)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Recurrence == nil || item.DueAt == nil {
+			return []time.Time{}, nil
+		}
+		recurrence = *item.Recurrence
+		start = *item.DueAt
+	}
+
+	rule, loc, err := parseRecurrence(recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	count := query.Count
+	if count <= 0 {
+		count = DefaultOccurrencesCount
+	}
+	if count > MaxOccurrencesCount {
+		count = MaxOccurrencesCount
+	}
+
+	start = start.In(loc)
+	return rule.Occurrences(start, start.Add(-time.Nanosecond), count), nil
+}
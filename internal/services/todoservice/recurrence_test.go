@@ -0,0 +1,215 @@
+package todoservice
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoService_Update_CompletesRecurring(t *testing.T) {
+	ctx := context.Background()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	dueAt := time.Date(2026, 3, 22, 2, 30, 0, 0, berlin).UTC()
+	done := true
+
+	t.Run("creates next occurrence", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=WEEKLY;COUNT=3", TimeZone: "Europe/Berlin"}
+
+		sub, err := service.Watch(ctx, viewerID, "")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		err = service.Update(ctx, serviceDTO.ToDoItem{ID: 10, IsComplete: &done}, editorID)
+		require.NoError(t, err)
+
+		require.Len(t, itemStorage.batches, 1)
+		mutations := itemStorage.batches[0]
+		require.Len(t, mutations, 1)
+
+		require.Equal(t, storageDTO.ToDoItemMutationUpdate, mutations[0].Type)
+		require.Equal(t, ownerID, mutations[0].OwnerID)
+		require.Equal(t, int64(2), mutations[0].Item.Version)
+		require.Equal(t, &storageDTO.ToDoItemRecurrence{}, mutations[0].Item.Recurrence)
+
+		next := mutations[0].Next
+		require.NotNil(t, next)
+		require.Equal(t, "2026-03-29T03:30:00+02:00", next.DueAt.Format(time.RFC3339))
+		require.Equal(t, &storageDTO.ToDoItemRecurrence{Rule: "FREQ=WEEKLY;COUNT=2", TimeZone: "Europe/Berlin"}, next.Recurrence)
+		require.Equal(t, "shared", *next.Title)
+
+		require.Equal(t, serviceDTO.ToDoItemUpdated, (<-sub.C).Payload.Type)
+		require.Equal(t, serviceDTO.ToDoItemCreated, (<-sub.C).Payload.Type)
+	})
+
+	t.Run("last occurrence", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=WEEKLY;COUNT=1"}
+
+		err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, IsComplete: &done}, ownerID)
+
+		require.NoError(t, err)
+		require.Empty(t, itemStorage.batches)
+		require.Equal(t, []uint64{ownerID}, itemStorage.scopedOwners)
+	})
+
+	t.Run("already complete", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.IsComplete = &done
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}
+
+		err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, IsComplete: &done}, ownerID)
+
+		require.NoError(t, err)
+		require.Empty(t, itemStorage.batches)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}
+
+		err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, IsComplete: &done, Version: 1}, ownerID)
+
+		require.ErrorIs(t, err, ErrVersionConflict)
+	})
+}
+
+func TestTodoService_Batch_CompletesRecurring(t *testing.T) {
+	ctx := context.Background()
+	dueAt := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	done := true
+
+	for _, atomic := range []bool{true, false} {
+		t.Run(fmt.Sprintf("atomic %t", atomic), func(t *testing.T) {
+			itemStorage, service := createSharedTodoService()
+			itemStorage.item.DueAt = &dueAt
+			itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}
+
+			sub, err := service.Watch(ctx, ownerID, "")
+			require.NoError(t, err)
+			defer sub.Close()
+
+			results, err := service.Batch(ctx, []serviceDTO.ToDoItemMutation{
+				{Type: serviceDTO.ToDoItemMutationUpdate, Item: serviceDTO.ToDoItem{ID: 10, IsComplete: &done}},
+			}, editorID, atomic)
+
+			require.NoError(t, err)
+			require.NoError(t, results[0].Err)
+			require.Len(t, itemStorage.batches, 1)
+
+			mutation := itemStorage.batches[0][0]
+			require.Equal(t, int64(2), mutation.Item.Version)
+			require.Equal(t, &storageDTO.ToDoItemRecurrence{}, mutation.Item.Recurrence)
+			require.NotNil(t, mutation.Next)
+			require.Equal(t, time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), *mutation.Next.DueAt)
+			require.Equal(t, &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}, mutation.Next.Recurrence)
+
+			require.Equal(t, serviceDTO.ToDoItemUpdated, (<-sub.C).Payload.Type)
+			require.Equal(t, serviceDTO.ToDoItemCreated, (<-sub.C).Payload.Type)
+		})
+	}
+
+	t.Run("stale version creates nothing", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}
+
+		results, err := service.Batch(ctx, []serviceDTO.ToDoItemMutation{
+			{Type: serviceDTO.ToDoItemMutationUpdate, Item: serviceDTO.ToDoItem{ID: 10, IsComplete: &done, Version: 1}},
+		}, ownerID, false)
+
+		require.NoError(t, err)
+		require.ErrorIs(t, results[0].Err, ErrVersionConflict)
+		require.Equal(t, []uint64{ownerID}, itemStorage.scopedOwners)
+	})
+}
+
+func TestTodoService_Recurrence_Invalid(t *testing.T) {
+	ctx := context.Background()
+	_, service := createSharedTodoService()
+
+	title := "rent"
+	dueAt := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		recurrence serviceDTO.ToDoItemRecurrence
+		dueAt      *time.Time
+	}{
+		{
+			name:       "unsupported rule",
+			recurrence: serviceDTO.ToDoItemRecurrence{Rule: "FREQ=HOURLY"},
+			dueAt:      &dueAt,
+		},
+		{
+			name:       "unknown time zone",
+			recurrence: serviceDTO.ToDoItemRecurrence{Rule: "FREQ=MONTHLY", TimeZone: "Mars/Olympus"},
+			dueAt:      &dueAt,
+		},
+		{
+			name:       "no due date",
+			recurrence: serviceDTO.ToDoItemRecurrence{Rule: "FREQ=MONTHLY"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			recurrence := testCase.recurrence
+			_, err := service.Create(ctx, serviceDTO.ToDoItem{Title: &title, DueAt: testCase.dueAt, Recurrence: &recurrence}, ownerID)
+			require.ErrorIs(t, err, ErrInvalidRecurrence)
+		})
+	}
+
+	err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, Recurrence: &serviceDTO.ToDoItemRecurrence{Rule: "BYDAY=MO"}}, ownerID)
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+}
+
+func TestTodoService_Occurrences(t *testing.T) {
+	ctx := context.Background()
+	dueAt := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+
+	t.Run("of recurrence", func(t *testing.T) {
+		_, service := createSharedTodoService()
+
+		occurrences, err := service.Occurrences(ctx, serviceDTO.ToDoItemOccurrencesQuery{
+			Recurrence: serviceDTO.ToDoItemRecurrence{Rule: "FREQ=MONTHLY;BYDAY=-1FR"},
+			Start:      dueAt,
+			Count:      2,
+		}, ownerID)
+
+		require.NoError(t, err)
+		require.Equal(t, []time.Time{dueAt, time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)}, occurrences)
+	})
+
+	t.Run("of item", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.item.DueAt = &dueAt
+		itemStorage.item.Recurrence = &storageDTO.ToDoItemRecurrence{Rule: "FREQ=DAILY"}
+
+		occurrences, err := service.Occurrences(ctx, serviceDTO.ToDoItemOccurrencesQuery{ItemID: 10, Count: 1000}, viewerID)
+
+		require.NoError(t, err)
+		require.Len(t, occurrences, MaxOccurrencesCount)
+		require.Equal(t, dueAt, occurrences[0])
+	})
+
+	t.Run("of item without recurrence", func(t *testing.T) {
+		_, service := createSharedTodoService()
+
+		occurrences, err := service.Occurrences(ctx, serviceDTO.ToDoItemOccurrencesQuery{ItemID: 10}, viewerID)
+
+		require.NoError(t, err)
+		require.Empty(t, occurrences)
+	})
+}
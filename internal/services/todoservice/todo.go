@@ -5,24 +5,60 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/eventhub"
 	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage"
 	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
 )
 
 type IToDoItemCreator interface {
-	StorageToDoItemCreate(ctx context.Context, title string, ownerID uint64) (uint64, error)
+	StorageToDoItemCreate(ctx context.Context, item storageDTO.ToDoItem, ownerID uint64) (uint64, error)
 }
 type IToDoItemUpdater interface {
 	StorageToDoItemUpdate(ctx context.Context, item storageDTO.ToDoItem, ownerID uint64) error
+	StorageToDoItemReorder(ctx context.Context, parentID uint64, ownerID uint64, childIDs []uint64) error
+	StorageToDoItemRestore(ctx context.Context, itemID uint64, ownerID uint64) error
 }
 type IToDoItemGetter interface {
 	StorageToDoItemGetByID(ctx context.Context, itemID uint64, ownerID uint64) (*storageDTO.ToDoItem, error)
-	StorageToDoItemGetList(ctx context.Context, ownerID uint64) ([]storageDTO.ToDoItem, error)
+	StorageToDoItemGetList(
+		ctx context.Context,
+		ownerID uint64,
+		query storageDTO.ToDoItemListQuery,
+	) ([]storageDTO.ToDoItem, *storageDTO.ToDoItemCursor, error)
+	StorageToDoItemGetDeleted(ctx context.Context, ownerID uint64) ([]storageDTO.ToDoItem, error)
 }
 type IToDoItemDeleter interface {
-	StorageToDoItemDeleteByID(ctx context.Context, itemID uint64, ownerID uint64) error
+	StorageToDoItemDeleteByID(
+		ctx context.Context,
+		itemID uint64,
+		ownerID uint64,
+		mode storageDTO.SubtaskDeleteMode,
+	) error
+	StorageToDoItemPurge(ctx context.Context, itemID uint64, ownerID uint64) error
+}
+
+// IToDoItemBatcher applies several mutations of items in one transaction
+type IToDoItemBatcher interface {
+	StorageToDoItemBatch(
+		ctx context.Context,
+		mutations []storageDTO.ToDoItemMutation,
+		atomic bool,
+	) ([]storageDTO.ToDoItemMutationResult, error)
+}
+
+// IToDoItemAccessResolver resolves roles of users on shared items
+type IToDoItemAccessResolver interface {
+	StorageToDoItemAccess(ctx context.Context, itemID uint64, userID uint64) (*storageDTO.Access, error)
+	StorageProjectAccess(ctx context.Context, projectID uint64, userID uint64) (*storageDTO.Access, error)
+	StorageToDoItemMemberIDs(ctx context.Context, itemID uint64) ([]uint64, error)
+}
+
+type IToDoItemEventHub interface {
+	Publish(ownerID uint64, event serviceDTO.ToDoItemEvent)
+	Subscribe(ownerID uint64, resumeToken string) (*eventhub.Subscription[serviceDTO.ToDoItemEvent], error)
 }
 
 type TodoService struct {
@@ -31,12 +67,39 @@ type TodoService struct {
 	todoItemsUpdater IToDoItemUpdater
 	todoItemsGetter  IToDoItemGetter
 	todoItemsDeleter IToDoItemDeleter
+	todoItemsBatcher IToDoItemBatcher
+	accessResolver   IToDoItemAccessResolver
+	eventHub         IToDoItemEventHub
+	subtaskMaxDepth  int
+	batchMaxSize     int
 }
 
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+
+	DefaultOccurrencesCount = 10
+	MaxOccurrencesCount     = 100
+)
+
 var (
-	ErrAccessDenied = errors.New("todo service: access denied")
-	ErrItemNotFound = errors.New("todo service: item not found")
-	ErrInternal     = errors.New("todo service: internal error")
+	ErrAccessDenied      = errors.New("todo service: access denied")
+	ErrItemNotFound      = errors.New("todo service: item not found")
+	ErrProjectNotFound   = errors.New("todo service: project not found")
+	ErrParentNotFound    = errors.New("todo service: parent item not found")
+	ErrParentDeleted     = errors.New("todo service: parent item is in trash")
+	ErrInvalidParent     = errors.New("todo service: child item can not belong to project")
+	ErrDepthExceeded     = errors.New("todo service: subtask depth exceeded")
+	ErrInvalidOrder      = errors.New("todo service: order must list every child once")
+	ErrVersionConflict   = errors.New("todo service: item was changed since given version")
+	ErrInvalidBatch      = errors.New("todo service: batch is empty or too large")
+	ErrInvalidRecurrence = errors.New("todo service: invalid recurrence")
+	ErrBatchAborted      = errors.New("todo service: batch rolled back")
+	ErrInvalidPageToken  = errors.New("todo service: invalid page token")
+	ErrInvalidQuery      = errors.New("todo service: invalid list query")
+	ErrInvalidResume     = errors.New("todo service: invalid resume token")
+	ErrResumeExpired     = errors.New("todo service: resume token expired")
+	ErrInternal          = errors.New("todo service: internal error")
 )
 
 func New(
@@ -45,6 +108,11 @@ func New(
 	todoItemsUpdater IToDoItemUpdater,
 	todoItemsGetter IToDoItemGetter,
 	todoItemsDeleter IToDoItemDeleter,
+	todoItemsBatcher IToDoItemBatcher,
+	accessResolver IToDoItemAccessResolver,
+	eventHub IToDoItemEventHub,
+	subtaskMaxDepth int,
+	batchMaxSize int,
 ) *TodoService {
 	return &TodoService{
 		logger:           log.With(slog.String("module", "todoService")),
@@ -52,85 +120,544 @@ func New(
 		todoItemsUpdater: todoItemsUpdater,
 		todoItemsGetter:  todoItemsGetter,
 		todoItemsDeleter: todoItemsDeleter,
+		todoItemsBatcher: todoItemsBatcher,
+		accessResolver:   accessResolver,
+		eventHub:         eventHub,
+		subtaskMaxDepth:  subtaskMaxDepth,
+		batchMaxSize:     batchMaxSize,
+	}
+}
+
+// storageError maps storage layer errors to service errors
+func storageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return ErrItemNotFound
+	case errors.Is(err, storage.ErrAccessDenied):
+		return ErrAccessDenied
+	case errors.Is(err, storage.ErrReferenceNotFound):
+		return ErrProjectNotFound
+	case errors.Is(err, storage.ErrInvalidOrder):
+		return ErrInvalidOrder
+	case errors.Is(err, storage.ErrVersionConflict):
+		return ErrVersionConflict
+	case errors.Is(err, storage.ErrBatchAborted):
+		return ErrBatchAborted
+	default:
+		return errors.Join(ErrInternal, err)
+	}
+}
+
+func storagePriority(priority *serviceDTO.ToDoItemPriority) *storageDTO.ToDoItemPriority {
+	if priority == nil {
+		return nil
+	}
+	storagePriority := storageDTO.ToDoItemPriority(*priority)
+	return &storagePriority
+}
+
+func serviceItem(item storageDTO.ToDoItem) serviceDTO.ToDoItem {
+	var priority *serviceDTO.ToDoItemPriority
+	if item.Priority != nil {
+		servicePriority := serviceDTO.ToDoItemPriority(*item.Priority)
+		priority = &servicePriority
+	}
+
+	return serviceDTO.ToDoItem{
+		ID:           item.Id,
+		OwnerID:      item.OwnerId,
+		ProjectID:    item.ProjectID,
+		ParentID:     item.ParentID,
+		Depth:        item.Depth,
+		Position:     item.Position,
+		AutoComplete: item.AutoComplete,
+		DoneCount:    item.DoneCount,
+		TotalCount:   item.TotalCount,
+		Title:        item.Title,
+		IsComplete:   item.IsComplete,
+		Notes:        item.Notes,
+		Priority:     priority,
+		DueAt:        item.DueAt,
+		CreatedAt:    item.CreatedAt,
+		UpdatedAt:    item.UpdatedAt,
+		CompletedAt:  item.CompletedAt,
+		Version:      item.Version,
+		DeletedAt:    item.DeletedAt,
+		Recurrence:   serviceRecurrence(item.Recurrence),
 	}
 }
 
-func (s *TodoService) Create(ctx context.Context, title string, ownerID uint64) (uint64, error) {
-	id, err := s.todoItemsCreator.StorageToDoItemCreate(ctx, title, ownerID)
+// itemOwner returns owner of item if user has at least required role on it
+func (s *TodoService) itemOwner(
+	ctx context.Context,
+	itemID uint64,
+	userID uint64,
+	required storageDTO.ShareRole,
+) (uint64, error) {
+	access, err := s.accessResolver.StorageToDoItemAccess(ctx, itemID, userID)
 	if err != nil {
+		return 0, storageError(err)
+	}
+
+	if access.Role < required {
+		return 0, ErrAccessDenied
+	}
+
+	return access.OwnerID, nil
+}
+
+// projectOwner returns owner of project if user may add items to it
+func (s *TodoService) projectOwner(ctx context.Context, projectID uint64, userID uint64) (uint64, error) {
+	access, err := s.accessResolver.StorageProjectAccess(ctx, projectID, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, ErrProjectNotFound
+		}
 		return 0, errors.Join(ErrInternal, err)
 	}
+
+	switch {
+	case access.Role == storageDTO.ShareRoleNone:
+		return 0, ErrProjectNotFound
+	case access.Role < storageDTO.ShareRoleEditor:
+		return 0, ErrAccessDenied
+	}
+
+	return access.OwnerID, nil
+}
+
+// parentOwner returns owner of parent item if caller may add children to it
+// and the child does not exceed max depth
+func (s *TodoService) parentOwner(ctx context.Context, parentID uint64, callerID uint64) (uint64, error) {
+	ownerID, err := s.itemOwner(ctx, parentID, callerID, storageDTO.ShareRoleEditor)
+	if err != nil {
+		if errors.Is(err, ErrItemNotFound) {
+			return 0, ErrParentNotFound
+		}
+		return 0, err
+	}
+
+	parent, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, parentID, ownerID)
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	if parent.Depth+1 > s.subtaskMaxDepth {
+		return 0, ErrDepthExceeded
+	}
+
+	return ownerID, nil
+}
+
+// createOwner returns owner of new item. Items of shared project are owned by project owner,
+// children of shared item are owned by parent owner
+func (s *TodoService) createOwner(ctx context.Context, item serviceDTO.ToDoItem, callerID uint64) (uint64, error) {
+	hasProject := item.ProjectID != nil && *item.ProjectID != 0
+
+	if item.GetParentID() != 0 {
+		if hasProject {
+			return 0, ErrInvalidParent
+		}
+		return s.parentOwner(ctx, *item.ParentID, callerID)
+	}
+
+	if hasProject {
+		return s.projectOwner(ctx, *item.ProjectID, callerID)
+	}
+
+	return callerID, nil
+}
+
+// storageNewItem converts new item to storage
+func storageNewItem(item serviceDTO.ToDoItem) storageDTO.ToDoItem {
+	var parentID *uint64
+	if item.GetParentID() != 0 {
+		parentID = item.ParentID
+	}
+
+	return storageDTO.ToDoItem{
+		Title:        item.Title,
+		ProjectID:    item.ProjectID,
+		ParentID:     parentID,
+		AutoComplete: item.AutoComplete,
+		Notes:        item.Notes,
+		Priority:     storagePriority(item.Priority),
+		DueAt:        item.DueAt,
+		Recurrence:   storageRecurrence(item.Recurrence),
+	}
+}
+
+// createError maps storage errors of item creation to service errors
+func createError(item storageDTO.ToDoItem, err error) error {
+	if item.ParentID != nil && errors.Is(err, storage.ErrReferenceNotFound) {
+		return ErrParentNotFound
+	}
+	return storageError(err)
+}
+
+// Create adds item owned by caller. Items of shared project are owned by project owner,
+// children of shared item are owned by parent owner
+func (s *TodoService) Create(ctx context.Context, item serviceDTO.ToDoItem, callerID uint64) (uint64, error) {
+	err := checkNewRecurrence(&item)
+	if err != nil {
+		return 0, err
+	}
+
+	ownerID, err := s.createOwner(ctx, item, callerID)
+	if err != nil {
+		return 0, err
+	}
+
+	storageItem := storageNewItem(item)
+	id, err := s.todoItemsCreator.StorageToDoItemCreate(ctx, storageItem, ownerID)
+	if err != nil {
+		return 0, createError(storageItem, err)
+	}
+
+	item.ID = id
+	s.publish(ctx, serviceDTO.ToDoItemCreated, item, ownerID)
+
 	return id, nil
 }
 
-func (s *TodoService) GetByID(ctx context.Context, itemID uint64, ownerID uint64) (*serviceDTO.ToDoItem, error) {
+// GetByID returns item if caller is at least its viewer
+func (s *TodoService) GetByID(ctx context.Context, itemID uint64, callerID uint64) (*serviceDTO.ToDoItem, error) {
+	ownerID, err := s.itemOwner(ctx, itemID, callerID, storageDTO.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
 	item, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, itemID, ownerID)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return nil, ErrItemNotFound
+		return nil, storageError(err)
+	}
+
+	result := serviceItem(*item)
+	return &result, nil
+}
+
+// GetList returns top level items of caller or children of item if caller is at least viewer of it
+func (s *TodoService) GetList(
+	ctx context.Context,
+	ownerID uint64,
+	query serviceDTO.ToDoItemListQuery,
+) (*serviceDTO.ToDoItemPage, error) {
+	after, err := decodePageToken(query)
+	if err != nil {
+		return nil, err
+	}
+
+	includeShared := query.IncludeShared
+	if query.ParentID != nil {
+		ownerID, err = s.itemOwner(ctx, *query.ParentID, ownerID, storageDTO.ShareRoleViewer)
+		if err != nil {
+			return nil, err
 		}
-		return nil, errors.Join(ErrInternal, err)
+		includeShared = false
 	}
 
-	if item.OwnerId != ownerID {
-		return nil, ErrAccessDenied
+	dueBefore := query.DueBefore
+	isComplete := query.IsComplete
+	if query.Overdue {
+		if isComplete != nil && *isComplete {
+			return nil, ErrInvalidQuery
+		}
+
+		now := time.Now()
+		if dueBefore == nil || dueBefore.After(now) {
+			dueBefore = &now
+		}
+
+		notComplete := false
+		isComplete = &notComplete
 	}
 
-	return &serviceDTO.ToDoItem{
-		ID:         itemID,
-		OwnerID:    item.OwnerId,
-		Title:      item.Title,
-		IsComplete: item.IsComplete,
-	}, nil
-}
+	priorities := make([]storageDTO.ToDoItemPriority, 0, len(query.Priorities))
+	for _, priority := range query.Priorities {
+		priorities = append(priorities, storageDTO.ToDoItemPriority(priority))
+	}
 
-func (s *TodoService) GetList(ctx context.Context, ownerID uint64) ([]serviceDTO.ToDoItem, error) {
-	storageItems, err := s.todoItemsGetter.StorageToDoItemGetList(ctx, ownerID)
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+		if query.Unbounded {
+			pageSize = 0
+		}
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	storageItems, next, err := s.todoItemsGetter.StorageToDoItemGetList(ctx, ownerID, storageDTO.ToDoItemListQuery{
+		ProjectID:     query.ProjectID,
+		ParentID:      query.ParentID,
+		IncludeShared: includeShared,
+		IsComplete:    isComplete,
+		TitleContains: query.TitleContains,
+		Priorities:    priorities,
+		DueAfter:      query.DueAfter,
+		DueBefore:     dueBefore,
+		SortOrder:     storageSortOrder(query.SortOrder),
+		Limit:         pageSize,
+		After:         after,
+	})
 	if err != nil {
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			return nil, ErrInvalidPageToken
+		}
 		return nil, errors.Join(ErrInternal, err)
 	}
 	result := make([]serviceDTO.ToDoItem, 0, len(storageItems))
 
 	for _, todoItem := range storageItems {
-		result = append(result, serviceDTO.ToDoItem{
-			ID:         todoItem.Id,
-			OwnerID:    todoItem.OwnerId,
-			Title:      todoItem.Title,
-			IsComplete: todoItem.IsComplete,
-		})
+		result = append(result, serviceItem(todoItem))
 	}
 
-	return result, nil
+	return &serviceDTO.ToDoItemPage{
+		Items:         result,
+		NextPageToken: encodePageToken(query, next),
+	}, nil
 }
 
-func (s *TodoService) DeleteByID(ctx context.Context, itemID uint64, ownerID uint64) error {
-	err := s.todoItemsDeleter.StorageToDoItemDeleteByID(ctx, itemID, ownerID)
+// DeleteByID deletes item if caller is its owner.
+// Children are deleted with item or moved to its parent depending on mode
+func (s *TodoService) DeleteByID(
+	ctx context.Context,
+	itemID uint64,
+	callerID uint64,
+	mode serviceDTO.SubtaskDeleteMode,
+) error {
+	deleted, err := s.prepareDelete(ctx, itemID, callerID)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return ErrItemNotFound
-		}
-		return errors.Join(ErrInternal, err)
+		return err
+	}
+
+	err = s.todoItemsDeleter.StorageToDoItemDeleteByID(ctx, itemID, deleted.ownerID, storageDTO.SubtaskDeleteMode(mode))
+	if err != nil {
+		return storageError(err)
 	}
 
+	s.publishDeleted(ctx, itemID, deleted)
+
 	return nil
 }
 
-func (s *TodoService) Update(ctx context.Context, item serviceDTO.ToDoItem, ownerID uint64) error {
+// deletedItem state of item needed to notify about its delete
+type deletedItem struct {
+	ownerID  uint64
+	parentID *uint64
+	audience []uint64
+}
 
-	storageItem := storageDTO.ToDoItem{
-		Id:         item.ID,
-		OwnerId:    item.ID,
-		Title:      item.Title,
-		IsComplete: item.IsComplete,
+// prepareDelete checks caller is owner of item and collects users to notify before item is deleted
+func (s *TodoService) prepareDelete(ctx context.Context, itemID uint64, callerID uint64) (*deletedItem, error) {
+	ownerID, err := s.itemOwner(ctx, itemID, callerID, storageDTO.ShareRoleOwner)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.todoItemsUpdater.StorageToDoItemUpdate(ctx, storageItem, ownerID)
+	item, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, itemID, ownerID)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotFound) {
-			return ErrItemNotFound
+		return nil, storageError(err)
+	}
+
+	return &deletedItem{
+		ownerID:  ownerID,
+		parentID: item.ParentID,
+		audience: s.audience(ctx, itemID, ownerID),
+	}, nil
+}
+
+// publishDeleted notifies former audience of item and updates its ancestors
+func (s *TodoService) publishDeleted(ctx context.Context, itemID uint64, deleted *deletedItem) {
+	event := serviceDTO.ToDoItemEvent{
+		Type: serviceDTO.ToDoItemDeleted,
+		Item: serviceDTO.ToDoItem{
+			ID:      itemID,
+			OwnerID: deleted.ownerID,
+		},
+	}
+	for _, userID := range deleted.audience {
+		s.eventHub.Publish(userID, event)
+	}
+
+	s.publishAncestors(ctx, deleted.parentID, deleted.ownerID)
+}
+
+// Reorder sets order of children of item if caller is at least its editor.
+// childIDs must list every child of item exactly once
+func (s *TodoService) Reorder(ctx context.Context, parentID uint64, childIDs []uint64, callerID uint64) error {
+	ownerID, err := s.itemOwner(ctx, parentID, callerID, storageDTO.ShareRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = s.todoItemsUpdater.StorageToDoItemReorder(ctx, parentID, ownerID, childIDs)
+	if err != nil {
+		return storageError(err)
+	}
+
+	for _, childID := range childIDs {
+		s.publish(ctx, serviceDTO.ToDoItemUpdated, serviceDTO.ToDoItem{ID: childID}, ownerID)
+	}
+
+	return nil
+}
+
+// updateOwner returns owner of item if caller may apply update to it.
+// Moving item to another project requires owner role, child items can not be moved
+func (s *TodoService) updateOwner(ctx context.Context, item serviceDTO.ToDoItem, callerID uint64) (uint64, error) {
+	required := storageDTO.ShareRoleEditor
+	if item.ProjectID != nil {
+		required = storageDTO.ShareRoleOwner
+	}
+
+	ownerID, err := s.itemOwner(ctx, item.ID, callerID, required)
+	if err != nil {
+		return 0, err
+	}
+
+	if item.ProjectID != nil {
+		current, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, item.ID, ownerID)
+		if err != nil {
+			return 0, storageError(err)
 		}
-		return errors.Join(ErrInternal, err)
+
+		if current.ParentID != nil {
+			return 0, ErrInvalidParent
+		}
+	}
+
+	return ownerID, nil
+}
+
+// storageUpdatedItem converts item update to storage
+func storageUpdatedItem(item serviceDTO.ToDoItem, ownerID uint64) storageDTO.ToDoItem {
+	return storageDTO.ToDoItem{
+		Id:           item.ID,
+		OwnerId:      ownerID,
+		ProjectID:    item.ProjectID,
+		AutoComplete: item.AutoComplete,
+		Title:        item.Title,
+		IsComplete:   item.IsComplete,
+		Notes:        item.Notes,
+		Priority:     storagePriority(item.Priority),
+		DueAt:        item.DueAt,
+		Recurrence:   storageRecurrence(item.Recurrence),
+		Version:      item.Version,
+	}
+}
+
+// Update changes item if caller is at least its editor.
+// Moving item to another project requires owner role, child items can not be moved.
+// Completing recurring item creates its next occurrence
+func (s *TodoService) Update(ctx context.Context, item serviceDTO.ToDoItem, callerID uint64) error {
+	err := checkRecurrence(&item)
+	if err != nil {
+		return err
+	}
+
+	ownerID, err := s.updateOwner(ctx, item, callerID)
+	if err != nil {
+		return err
+	}
+
+	mutation, err := s.updateMutation(ctx, item, ownerID)
+	if err != nil {
+		return err
 	}
 
+	if mutation.Next != nil {
+		return s.completeRecurring(ctx, item, mutation)
+	}
+
+	err = s.todoItemsUpdater.StorageToDoItemUpdate(ctx, mutation.Item, ownerID)
+	if err != nil {
+		return storageError(err)
+	}
+
+	s.publish(ctx, serviceDTO.ToDoItemUpdated, item, ownerID)
+
 	return nil
 }
+
+// audience returns owner and members of item
+func (s *TodoService) audience(ctx context.Context, itemID uint64, ownerID uint64) []uint64 {
+	members, err := s.accessResolver.StorageToDoItemMemberIDs(ctx, itemID)
+	if err != nil {
+		s.logger.Warn("get item members error", slog.String("method", "audience"), slog.Any("err", err))
+		return []uint64{ownerID}
+	}
+
+	return append([]uint64{ownerID}, members...)
+}
+
+// publish notifies owner and members of item with actual item state.
+// Ancestors of item are published as updated since their child counts may change
+func (s *TodoService) publish(
+	ctx context.Context,
+	eventType serviceDTO.ToDoItemEventType,
+	item serviceDTO.ToDoItem,
+	ownerID uint64,
+) {
+	log := s.logger.With(slog.String("method", "publish"))
+
+	event := serviceDTO.ToDoItemEvent{Type: eventType}
+
+	actual, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, item.ID, ownerID)
+	if err != nil {
+		log.Warn("get changed item error", slog.Any("err", err))
+		item.OwnerID = ownerID
+		event.Item = item
+	} else {
+		event.Item = serviceItem(*actual)
+	}
+
+	for _, userID := range s.audience(ctx, item.ID, ownerID) {
+		s.eventHub.Publish(userID, event)
+	}
+
+	if eventType != serviceDTO.ToDoItemUpdated || item.IsComplete != nil || item.AutoComplete != nil {
+		s.publishAncestors(ctx, event.Item.ParentID, ownerID)
+	}
+}
+
+// publishAncestors notifies about update of item with parentID and its ancestors
+func (s *TodoService) publishAncestors(ctx context.Context, parentID *uint64, ownerID uint64) {
+	for parentID != nil {
+		parent, err := s.todoItemsGetter.StorageToDoItemGetByID(ctx, *parentID, ownerID)
+		if err != nil {
+			s.logger.Warn("get parent item error", slog.String("method", "publishAncestors"), slog.Any("err", err))
+			return
+		}
+
+		event := serviceDTO.ToDoItemEvent{Type: serviceDTO.ToDoItemUpdated, Item: serviceItem(*parent)}
+		for _, userID := range s.audience(ctx, parent.Id, ownerID) {
+			s.eventHub.Publish(userID, event)
+		}
+
+		parentID = parent.ParentID
+	}
+}
+
+// Watch subscribes to changes of owner items and items shared with owner.
+// Events published after resumeToken are delivered first
+func (s *TodoService) Watch(
+	ctx context.Context,
+	ownerID uint64,
+	resumeToken string,
+) (*eventhub.Subscription[serviceDTO.ToDoItemEvent], error) {
+	sub, err := s.eventHub.Subscribe(ownerID, resumeToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, eventhub.ErrInvalidResumeToken):
+			return nil, ErrInvalidResume
+		case errors.Is(err, eventhub.ErrResumeTokenExpired):
+			return nil, ErrResumeExpired
+		default:
+			return nil, errors.Join(ErrInternal, err)
+		}
+	}
+
+	return sub, nil
+}
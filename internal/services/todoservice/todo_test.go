@@ -0,0 +1,473 @@
+package todoservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/eventhub"
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedItemStorage keeps one item of owner and roles of other users on it
+type sharedItemStorage struct {
+	item  storageDTO.ToDoItem
+	roles map[uint64]storageDTO.ShareRole
+	// scopedOwners owners passed to owner scoped storage calls
+	scopedOwners []uint64
+	// batches mutations passed to batch calls
+	batches [][]storageDTO.ToDoItemMutation
+	// restoreErr error returned by restore
+	restoreErr error
+	// listQueries queries passed to list calls
+	listQueries []storageDTO.ToDoItemListQuery
+}
+
+func (s *sharedItemStorage) StorageToDoItemCreate(_ context.Context, _ storageDTO.ToDoItem, ownerID uint64) (uint64, error) {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	return s.item.Id, nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemUpdate(_ context.Context, item storageDTO.ToDoItem, ownerID uint64) error {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	if item.Version != 0 && item.Version != s.item.Version {
+		return storage.ErrVersionConflict
+	}
+	return nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemReorder(_ context.Context, _ uint64, ownerID uint64, _ []uint64) error {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	return nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemRestore(_ context.Context, _ uint64, ownerID uint64) error {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	return s.restoreErr
+}
+
+func (s *sharedItemStorage) StorageToDoItemGetByID(_ context.Context, itemID uint64, ownerID uint64) (*storageDTO.ToDoItem, error) {
+	if itemID != s.item.Id || ownerID != s.item.OwnerId {
+		return nil, storage.ErrAccessDenied
+	}
+	item := s.item
+	return &item, nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemGetList(
+	_ context.Context,
+	_ uint64,
+	query storageDTO.ToDoItemListQuery,
+) ([]storageDTO.ToDoItem, *storageDTO.ToDoItemCursor, error) {
+	s.listQueries = append(s.listQueries, query)
+	return nil, nil, nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemGetDeleted(_ context.Context, _ uint64) ([]storageDTO.ToDoItem, error) {
+	return nil, nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemPurge(_ context.Context, _ uint64, ownerID uint64) error {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	return nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemDeleteByID(
+	_ context.Context,
+	_ uint64,
+	ownerID uint64,
+	_ storageDTO.SubtaskDeleteMode,
+) error {
+	s.scopedOwners = append(s.scopedOwners, ownerID)
+	return nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemBatch(
+	ctx context.Context,
+	mutations []storageDTO.ToDoItemMutation,
+	_ bool,
+) ([]storageDTO.ToDoItemMutationResult, error) {
+	s.batches = append(s.batches, mutations)
+	results := make([]storageDTO.ToDoItemMutationResult, len(mutations))
+	for i, mutation := range mutations {
+		switch mutation.Type {
+		case storageDTO.ToDoItemMutationCreate:
+			results[i].ID, results[i].Err = s.StorageToDoItemCreate(ctx, mutation.Item, mutation.OwnerID)
+		case storageDTO.ToDoItemMutationUpdate:
+			results[i].ID, results[i].Err = mutation.Item.Id, s.StorageToDoItemUpdate(ctx, mutation.Item, mutation.OwnerID)
+			if results[i].Err == nil && mutation.Next != nil {
+				results[i].NextID, results[i].Err = s.StorageToDoItemCreate(ctx, *mutation.Next, mutation.OwnerID)
+			}
+		case storageDTO.ToDoItemMutationDelete:
+			results[i].ID = mutation.Item.Id
+			results[i].Err = s.StorageToDoItemDeleteByID(ctx, mutation.Item.Id, mutation.OwnerID, mutation.DeleteMode)
+		}
+	}
+	return results, nil
+}
+
+func (s *sharedItemStorage) StorageToDoItemAccess(_ context.Context, itemID uint64, userID uint64) (*storageDTO.Access, error) {
+	if itemID != s.item.Id {
+		return nil, storage.ErrNotFound
+	}
+	if userID == s.item.OwnerId {
+		return &storageDTO.Access{OwnerID: s.item.OwnerId, Role: storageDTO.ShareRoleOwner}, nil
+	}
+	return &storageDTO.Access{OwnerID: s.item.OwnerId, Role: s.roles[userID]}, nil
+}
+
+func (s *sharedItemStorage) StorageProjectAccess(_ context.Context, _ uint64, _ uint64) (*storageDTO.Access, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (s *sharedItemStorage) StorageToDoItemMemberIDs(_ context.Context, _ uint64) ([]uint64, error) {
+	memberIDs := make([]uint64, 0, len(s.roles))
+	for userID := range s.roles {
+		memberIDs = append(memberIDs, userID)
+	}
+	return memberIDs, nil
+}
+
+const (
+	ownerID  = uint64(1)
+	viewerID = uint64(2)
+	editorID = uint64(3)
+	otherID  = uint64(4)
+)
+
+func createSharedTodoService() (*sharedItemStorage, *TodoService) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	title := "shared"
+	itemStorage := &sharedItemStorage{
+		item: storageDTO.ToDoItem{Id: 10, OwnerId: ownerID, Title: &title, Version: 2},
+		roles: map[uint64]storageDTO.ShareRole{
+			viewerID: storageDTO.ShareRoleViewer,
+			editorID: storageDTO.ShareRoleEditor,
+		},
+	}
+
+	service := New(
+		logger,
+		itemStorage,
+		itemStorage,
+		itemStorage,
+		itemStorage,
+		itemStorage,
+		itemStorage,
+		eventhub.New[serviceDTO.ToDoItemEvent](10, 10),
+		1,
+		2,
+	)
+
+	return itemStorage, service
+}
+
+func TestTodoService_SharedItemRoles(t *testing.T) {
+	title := "changed"
+
+	testCases := []struct {
+		name          string
+		callerID      uint64
+		getError      error
+		updateError   error
+		deleteError   error
+		expectedScope []uint64
+	}{
+		{
+			name:          "owner",
+			callerID:      ownerID,
+			expectedScope: []uint64{ownerID, ownerID},
+		},
+		{
+			name:          "editor",
+			callerID:      editorID,
+			deleteError:   ErrAccessDenied,
+			expectedScope: []uint64{ownerID},
+		},
+		{
+			name:        "viewer",
+			callerID:    viewerID,
+			updateError: ErrAccessDenied,
+			deleteError: ErrAccessDenied,
+		},
+		{
+			name:        "not a member",
+			callerID:    otherID,
+			getError:    ErrAccessDenied,
+			updateError: ErrAccessDenied,
+			deleteError: ErrAccessDenied,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			itemStorage, service := createSharedTodoService()
+
+			item, err := service.GetByID(ctx, 10, testCase.callerID)
+			if testCase.getError != nil {
+				require.ErrorIs(t, err, testCase.getError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, ownerID, item.OwnerID)
+			}
+
+			err = service.Update(ctx, serviceDTO.ToDoItem{ID: 10, Title: &title}, testCase.callerID)
+			if testCase.updateError != nil {
+				require.ErrorIs(t, err, testCase.updateError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = service.DeleteByID(ctx, 10, testCase.callerID, serviceDTO.SubtaskDeleteReparent)
+			if testCase.deleteError != nil {
+				require.ErrorIs(t, err, testCase.deleteError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, testCase.expectedScope, itemStorage.scopedOwners)
+		})
+	}
+}
+
+func TestTodoService_Update_MoveRequiresOwner(t *testing.T) {
+	ctx := context.Background()
+	_, service := createSharedTodoService()
+
+	projectID := uint64(0)
+	err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, ProjectID: &projectID}, editorID)
+
+	require.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestTodoService_Update_NotifiesMembers(t *testing.T) {
+	ctx := context.Background()
+	_, service := createSharedTodoService()
+
+	sub, err := service.Watch(ctx, viewerID, "")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	title := "changed"
+	err = service.Update(ctx, serviceDTO.ToDoItem{ID: 10, Title: &title}, editorID)
+	require.NoError(t, err)
+
+	event := <-sub.C
+	require.Equal(t, serviceDTO.ToDoItemUpdated, event.Payload.Type)
+	require.Equal(t, uint64(10), event.Payload.Item.ID)
+}
+
+func TestTodoService_Create_Subtask(t *testing.T) {
+	title := "subtask"
+	projectID := uint64(5)
+
+	testCases := []struct {
+		name          string
+		callerID      uint64
+		parentDepth   int
+		projectID     *uint64
+		expectedError error
+		expectedScope []uint64
+	}{
+		{
+			name:          "editor adds subtask owned by parent owner",
+			callerID:      editorID,
+			expectedScope: []uint64{ownerID},
+		},
+		{
+			name:          "viewer",
+			callerID:      viewerID,
+			expectedError: ErrAccessDenied,
+		},
+		{
+			name:          "depth exceeded",
+			callerID:      ownerID,
+			parentDepth:   1,
+			expectedError: ErrDepthExceeded,
+		},
+		{
+			name:          "subtask with project",
+			callerID:      ownerID,
+			projectID:     &projectID,
+			expectedError: ErrInvalidParent,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			itemStorage, service := createSharedTodoService()
+			itemStorage.item.Depth = testCase.parentDepth
+
+			parentID := itemStorage.item.Id
+			_, err := service.Create(ctx, serviceDTO.ToDoItem{
+				Title:     &title,
+				ParentID:  &parentID,
+				ProjectID: testCase.projectID,
+			}, testCase.callerID)
+
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, testCase.expectedScope, itemStorage.scopedOwners)
+		})
+	}
+}
+
+func TestTodoService_Reorder_RequiresEditor(t *testing.T) {
+	ctx := context.Background()
+	itemStorage, service := createSharedTodoService()
+
+	err := service.Reorder(ctx, 10, nil, viewerID)
+	require.ErrorIs(t, err, ErrAccessDenied)
+
+	err = service.Reorder(ctx, 10, nil, editorID)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{ownerID}, itemStorage.scopedOwners)
+}
+
+func TestTodoService_Update_VersionConflict(t *testing.T) {
+	ctx := context.Background()
+	_, service := createSharedTodoService()
+
+	title := "changed"
+	err := service.Update(ctx, serviceDTO.ToDoItem{ID: 10, Title: &title, Version: 1}, editorID)
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	err = service.Update(ctx, serviceDTO.ToDoItem{ID: 10, Title: &title, Version: 2}, editorID)
+	require.NoError(t, err)
+}
+
+func TestTodoService_Batch(t *testing.T) {
+	title := "changed"
+	mutations := []serviceDTO.ToDoItemMutation{
+		{Type: serviceDTO.ToDoItemMutationUpdate, Item: serviceDTO.ToDoItem{ID: 10, Title: &title}},
+		{Type: serviceDTO.ToDoItemMutationDelete, Item: serviceDTO.ToDoItem{ID: 10}},
+	}
+
+	t.Run("atomic aborts on denied mutation", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+
+		results, err := service.Batch(context.Background(), mutations, editorID, true)
+
+		require.NoError(t, err)
+		require.ErrorIs(t, results[0].Err, ErrBatchAborted)
+		require.ErrorIs(t, results[1].Err, ErrAccessDenied)
+		require.Empty(t, itemStorage.batches)
+	})
+
+	t.Run("best effort skips denied mutation", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+
+		results, err := service.Batch(context.Background(), mutations, editorID, false)
+
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, uint64(10), results[0].ID)
+		require.ErrorIs(t, results[1].Err, ErrAccessDenied)
+		require.Len(t, itemStorage.batches, 1)
+		require.Len(t, itemStorage.batches[0], 1)
+		require.Equal(t, ownerID, itemStorage.batches[0][0].OwnerID)
+	})
+
+	t.Run("storage errors per mutation", func(t *testing.T) {
+		_, service := createSharedTodoService()
+		stale := []serviceDTO.ToDoItemMutation{
+			{Type: serviceDTO.ToDoItemMutationUpdate, Item: serviceDTO.ToDoItem{ID: 10, Title: &title, Version: 1}},
+			{Type: serviceDTO.ToDoItemMutationDelete, Item: serviceDTO.ToDoItem{ID: 10}},
+		}
+
+		results, err := service.Batch(context.Background(), stale, ownerID, false)
+
+		require.NoError(t, err)
+		require.ErrorIs(t, results[0].Err, ErrVersionConflict)
+		require.NoError(t, results[1].Err)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		_, service := createSharedTodoService()
+
+		_, err := service.Batch(context.Background(), nil, ownerID, true)
+		require.ErrorIs(t, err, ErrInvalidBatch)
+
+		_, err = service.Batch(context.Background(), append(mutations, mutations[0]), ownerID, true)
+		require.ErrorIs(t, err, ErrInvalidBatch)
+	})
+}
+
+func TestTodoService_Restore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("notifies members", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+
+		sub, err := service.Watch(ctx, viewerID, "")
+		require.NoError(t, err)
+		defer sub.Close()
+
+		err = service.Restore(ctx, 10, ownerID)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{ownerID}, itemStorage.scopedOwners)
+
+		event := <-sub.C
+		require.Equal(t, serviceDTO.ToDoItemCreated, event.Payload.Type)
+		require.Equal(t, uint64(10), event.Payload.Item.ID)
+	})
+
+	t.Run("parent in trash", func(t *testing.T) {
+		itemStorage, service := createSharedTodoService()
+		itemStorage.restoreErr = storage.ErrReferenceNotFound
+
+		err := service.Restore(ctx, 10, ownerID)
+		require.ErrorIs(t, err, ErrParentDeleted)
+	})
+}
+
+func TestTodoService_GetList_PageSize(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         serviceDTO.ToDoItemListQuery
+		expectedLimit int
+	}{
+		{
+			name:          "default page size",
+			query:         serviceDTO.ToDoItemListQuery{},
+			expectedLimit: DefaultPageSize,
+		},
+		{
+			name:          "unbounded without page size",
+			query:         serviceDTO.ToDoItemListQuery{Unbounded: true},
+			expectedLimit: 0,
+		},
+		{
+			name:          "unbounded with page size",
+			query:         serviceDTO.ToDoItemListQuery{Unbounded: true, PageSize: 5},
+			expectedLimit: 5,
+		},
+		{
+			name:          "page size above max",
+			query:         serviceDTO.ToDoItemListQuery{PageSize: MaxPageSize + 1},
+			expectedLimit: MaxPageSize,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			itemStorage, service := createSharedTodoService()
+
+			_, err := service.GetList(context.Background(), ownerID, testCase.query)
+
+			require.NoError(t, err)
+			require.Len(t, itemStorage.listQueries, 1)
+			require.Equal(t, testCase.expectedLimit, itemStorage.listQueries[0].Limit)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package todoservice
+
+import (
+	"context"
+	"errors"
+
+	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+)
+
+// ListDeleted returns items of caller in trash. Children moved to trash with their parent are not listed
+func (s *TodoService) ListDeleted(ctx context.Context, callerID uint64) ([]serviceDTO.ToDoItem, error) {
+	storageItems, err := s.todoItemsGetter.StorageToDoItemGetDeleted(ctx, callerID)
+	if err != nil {
+		return nil, errors.Join(ErrInternal, err)
+	}
+
+	result := make([]serviceDTO.ToDoItem, 0, len(storageItems))
+	for _, item := range storageItems {
+		result = append(result, serviceItem(item))
+	}
+
+	return result, nil
+}
+
+// Restore moves item of caller out of trash with children moved to trash with it.
+// Item can not be restored while its parent is in trash
+func (s *TodoService) Restore(ctx context.Context, itemID uint64, callerID uint64) error {
+	err := s.todoItemsUpdater.StorageToDoItemRestore(ctx, itemID, callerID)
+	if err != nil {
+		if errors.Is(err, storage.ErrReferenceNotFound) {
+			return ErrParentDeleted
+		}
+		return storageError(err)
+	}
+
+	s.publish(ctx, serviceDTO.ToDoItemCreated, serviceDTO.ToDoItem{ID: itemID}, callerID)
+
+	return nil
+}
+
+// Purge permanently deletes item of caller in trash with its children
+func (s *TodoService) Purge(ctx context.Context, itemID uint64, callerID uint64) error {
+	err := s.todoItemsDeleter.StorageToDoItemPurge(ctx, itemID, callerID)
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
@@ -1,6 +0,0 @@
-package storageDTO
-
-type Credential struct {
-	Username  string
-	TokenHash []byte
-}
@@ -0,0 +1,18 @@
+package storageDTO
+
+import "time"
+
+// TOTP enrollment of user. Secret is encrypted
+type TOTP struct {
+	UserID       uint64
+	Secret       []byte
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+// MfaChallenge login waiting for MFA code
+type MfaChallenge struct {
+	ID        uint64
+	UserID    uint64
+	ExpiresAt time.Time
+}
@@ -0,0 +1,25 @@
+package storageDTO
+
+import "time"
+
+// Project storage DTO
+type Project struct {
+	Id         uint64
+	OwnerId    uint64
+	Name       *string
+	IsArchived *bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ProjectDeleteMode what happens to project items on project delete
+type ProjectDeleteMode int
+
+const (
+	// ProjectDeleteUnassign keeps items without project
+	ProjectDeleteUnassign ProjectDeleteMode = iota
+	// ProjectDeleteReassign moves items to another project
+	ProjectDeleteReassign
+	// ProjectDeleteCascade deletes items with project
+	ProjectDeleteCascade
+)
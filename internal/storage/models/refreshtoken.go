@@ -0,0 +1,13 @@
+package storageDTO
+
+import "time"
+
+// RefreshToken issued together with access token AccessTokenID
+type RefreshToken struct {
+	FamilyID        uint64
+	UserID          uint64
+	Username        string
+	AccessTokenID   uint64
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+}
@@ -0,0 +1,14 @@
+package storageDTO
+
+import "time"
+
+// Session login of user
+type Session struct {
+	ID         uint64
+	UserID     uint64
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
@@ -0,0 +1,42 @@
+package storageDTO
+
+import "time"
+
+// ShareResourceType kind of shared resource
+type ShareResourceType int8
+
+const (
+	ShareResourceTask ShareResourceType = iota + 1
+	ShareResourceProject
+)
+
+// ShareRole access level of user. Greater role includes lesser ones
+type ShareRole int8
+
+const (
+	ShareRoleNone ShareRole = iota
+	ShareRoleViewer
+	ShareRoleEditor
+	ShareRoleOwner
+)
+
+// ShareResource shared task or project
+type ShareResource struct {
+	Type ShareResourceType
+	ID   uint64
+}
+
+// ShareMember storage DTO
+type ShareMember struct {
+	Resource  ShareResource
+	UserID    uint64
+	Username  *string
+	Role      ShareRole
+	CreatedAt time.Time
+}
+
+// Access role of user on resource and the resource owner
+type Access struct {
+	OwnerID uint64
+	Role    ShareRole
+}
@@ -1,9 +1,135 @@
 package storageDTO
 
-//ToDoItem storage DTO
+import "time"
+
+// ToDoItemPriority storage item priority
+type ToDoItemPriority int8
+
+const (
+	ToDoItemPriorityLow ToDoItemPriority = iota + 1
+	ToDoItemPriorityNormal
+	ToDoItemPriorityHigh
+	ToDoItemPriorityUrgent
+)
+
+// ToDoItem storage DTO
 type ToDoItem struct {
 	Id         uint64
 	Title      *string
 	IsComplete *bool
 	OwnerId    uint64
+	// ProjectID pointer to zero means item without project
+	ProjectID *uint64
+	ParentID  *uint64
+	// Depth zero for top level items
+	Depth    int
+	Position int64
+	// AutoComplete completes item when all its children are done
+	AutoComplete *bool
+	// DoneCount and TotalCount count direct children
+	DoneCount  int64
+	TotalCount int64
+	Notes      *string
+	Priority   *ToDoItemPriority
+	// DueAt pointer to zero time clears due date on update
+	DueAt       *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+	// Version incremented on every change. Non-zero version on update must match the stored one
+	Version int64
+	// DeletedAt time item was moved to trash, nil for active items
+	DeletedAt *time.Time
+	// Recurrence pointer to empty rule clears recurrence on update
+	Recurrence *ToDoItemRecurrence
+}
+
+// ToDoItemRecurrence storage item recurrence
+type ToDoItemRecurrence struct {
+	Rule     string
+	TimeZone string
+}
+
+// ToDoItemSortOrder storage list order
+type ToDoItemSortOrder int
+
+const (
+	ToDoItemSortByIDAsc ToDoItemSortOrder = iota
+	ToDoItemSortByIDDesc
+	ToDoItemSortByTitleAsc
+	ToDoItemSortByTitleDesc
+	ToDoItemSortByDueAtAsc
+	ToDoItemSortByDueAtDesc
+	ToDoItemSortByPriorityAsc
+	ToDoItemSortByPriorityDesc
+	ToDoItemSortByCreatedAtAsc
+	ToDoItemSortByCreatedAtDesc
+	ToDoItemSortByUpdatedAtAsc
+	ToDoItemSortByUpdatedAtDesc
+	ToDoItemSortByPositionAsc
+)
+
+// ToDoItemCursor keyset position after the last returned item
+type ToDoItemCursor struct {
+	ID uint64
+	// Key sort column value of the last returned item. Nil if value is NULL
+	Key *string
+}
+
+// ToDoItemListQuery storage list query
+type ToDoItemListQuery struct {
+	// ProjectID pointer to zero selects items without project
+	ProjectID *uint64
+	// ParentID selects children of item. Nil selects top level items
+	ParentID *uint64
+	// IncludeShared adds items shared with owner directly or by project
+	IncludeShared bool
+	IsComplete    *bool
+	TitleContains *string
+	Priorities    []ToDoItemPriority
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	SortOrder     ToDoItemSortOrder
+	Limit         int
+	After         *ToDoItemCursor
+}
+
+// SubtaskDeleteMode what happens to children on item delete
+type SubtaskDeleteMode int
+
+const (
+	// SubtaskDeleteReparent moves children to the parent of deleted item
+	SubtaskDeleteReparent SubtaskDeleteMode = iota
+	// SubtaskDeleteCascade deletes all descendants
+	SubtaskDeleteCascade
+)
+
+// ToDoItemMutationType kind of batch operation
+type ToDoItemMutationType int
+
+const (
+	ToDoItemMutationCreate ToDoItemMutationType = iota
+	ToDoItemMutationUpdate
+	ToDoItemMutationDelete
+)
+
+// ToDoItemMutation batch operation on item of owner
+type ToDoItemMutation struct {
+	Type    ToDoItemMutationType
+	Item    ToDoItem
+	OwnerID uint64
+	// DeleteMode mode of delete operation
+	DeleteMode SubtaskDeleteMode
+	// Next item created by update mutation together with the update, nil if none.
+	// It is the next occurrence of completed recurring item
+	Next *ToDoItem
+}
+
+// ToDoItemMutationResult outcome of batch operation
+type ToDoItemMutationResult struct {
+	// ID created, changed or deleted item
+	ID uint64
+	// NextID created next item of update mutation
+	NextID uint64
+	Err    error
 }
@@ -0,0 +1,89 @@
+package postgresdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"gorm.io/gorm"
+)
+
+// StorageToDoItemBatch implements todoService.IToDoItemBatcher.
+// Mutations are applied in order within one transaction. Atomic batch is rolled back on the first
+// failed mutation and the other mutations get storage.ErrBatchAborted. Otherwise each mutation runs
+// in its own savepoint, failed mutations are rolled back and the rest is committed
+func (d *PostgresDataProvider) StorageToDoItemBatch(
+	ctx context.Context,
+	mutations []storageDTO.ToDoItemMutation,
+	atomic bool,
+) ([]storageDTO.ToDoItemMutationResult, error) {
+	results := make([]storageDTO.ToDoItemMutationResult, len(mutations))
+	failed := -1
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, mutation := range mutations {
+			var err error
+			if atomic {
+				results[i], err = d.applyMutation(tx, mutation)
+			} else {
+				err = tx.Transaction(func(tx *gorm.DB) error {
+					var err error
+					results[i], err = d.applyMutation(tx, mutation)
+					return err
+				})
+			}
+
+			if err != nil {
+				results[i] = storageDTO.ToDoItemMutationResult{Err: storageError(err)}
+				if atomic {
+					failed = i
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if failed < 0 {
+			return nil, storageError(err)
+		}
+
+		for i := range results {
+			if i != failed {
+				results[i] = storageDTO.ToDoItemMutationResult{Err: storage.ErrBatchAborted}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// applyMutation applies mutation within transaction tx and returns ids of its items.
+// Next item of update mutation is created only if update succeeds
+func (d *PostgresDataProvider) applyMutation(
+	tx *gorm.DB,
+	mutation storageDTO.ToDoItemMutation,
+) (storageDTO.ToDoItemMutationResult, error) {
+	result := storageDTO.ToDoItemMutationResult{ID: mutation.Item.Id}
+
+	var err error
+	switch mutation.Type {
+	case storageDTO.ToDoItemMutationCreate:
+		result.ID, err = d.createItem(tx, mutation.Item, mutation.OwnerID)
+	case storageDTO.ToDoItemMutationUpdate:
+		err = d.updateItem(tx, mutation.Item, mutation.OwnerID)
+		if err == nil && mutation.Next != nil {
+			result.NextID, err = d.createItem(tx, *mutation.Next, mutation.OwnerID)
+		}
+	case storageDTO.ToDoItemMutationDelete:
+		err = d.deleteItem(tx, mutation.Item.Id, mutation.OwnerID, mutation.DeleteMode)
+	default:
+		err = fmt.Errorf("unknown mutation type %d", mutation.Type)
+	}
+	if err != nil {
+		return storageDTO.ToDoItemMutationResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func batchMutations() []storageDTO.ToDoItemMutation {
+	title := "new title"
+	return []storageDTO.ToDoItemMutation{
+		{Type: storageDTO.ToDoItemMutationCreate, Item: storageDTO.ToDoItem{Title: &title}, OwnerID: 1},
+		{Type: storageDTO.ToDoItemMutationUpdate, Item: storageDTO.ToDoItem{Id: 10, Title: &title}, OwnerID: 1},
+		{Type: storageDTO.ToDoItemMutationUpdate, Item: storageDTO.ToDoItem{Id: 12, Title: &title}, OwnerID: 1},
+	}
+}
+
+func TestPostgresDataProvider_StorageToDoItemBatch_Atomic_RollsBack(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO "todoItems" (.+) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "title"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE \(id = \$3 AND owner_id = \$4\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs("new title", sqlmock.AnyArg(), 10, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE id = \$1`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	results, err := storageService.StorageToDoItemBatch(ctx, batchMutations(), true)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.ErrorIs(t, results[0].Err, storage.ErrBatchAborted)
+	require.Zero(t, results[0].ID)
+	require.ErrorIs(t, results[1].Err, storage.ErrNotFound)
+	require.ErrorIs(t, results[2].Err, storage.ErrBatchAborted)
+}
+
+func TestPostgresDataProvider_StorageToDoItemBatch_BestEffort(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`^INSERT INTO "todoItems" (.+) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectExec(`^SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^UPDATE "todoItems"`).
+		WithArgs("new title", sqlmock.AnyArg(), 10, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE id = \$1`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`^ROLLBACK TO SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^UPDATE "todoItems"`).
+		WithArgs("new title", sqlmock.AnyArg(), 12, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	results, err := storageService.StorageToDoItemBatch(ctx, batchMutations(), false)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, []storageDTO.ToDoItemMutationResult{
+		{ID: 11},
+		{Err: results[1].Err},
+		{ID: 12},
+	}, results)
+	require.ErrorIs(t, results[1].Err, storage.ErrAccessDenied)
+}
+
+func TestPostgresDataProvider_StorageToDoItemBatch_UpdateCreatesNext(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	title := "rent"
+	mutations := []storageDTO.ToDoItemMutation{
+		{
+			Type:    storageDTO.ToDoItemMutationUpdate,
+			Item:    storageDTO.ToDoItem{Id: 10, Title: &title},
+			OwnerID: 1,
+			Next:    &storageDTO.ToDoItem{Title: &title},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^UPDATE "todoItems"`).
+		WithArgs("rent", sqlmock.AnyArg(), 10, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^INSERT INTO "todoItems" (.+) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectCommit()
+
+	results, err := storageService.StorageToDoItemBatch(ctx, mutations, false)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, []storageDTO.ToDoItemMutationResult{{ID: 10, NextID: 11}}, results)
+}
@@ -0,0 +1,101 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/loginthrottle"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetLoginLock implements authService.ILoginAttemptCounter.
+// Returns zero time if key has no failures
+func (d *PostgresDataProvider) GetLoginLock(ctx context.Context, key string) (time.Time, error) {
+	var lockedUntil []time.Time
+	err := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.LoginAttemptPG{}).
+		Where("key = ?", key).
+		Pluck("locked_until", &lockedUntil).
+		Error
+	if err != nil {
+		return time.Time{}, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	if len(lockedUntil) == 0 {
+		return time.Time{}, nil
+	}
+
+	return lockedUntil[0], nil
+}
+
+// RegisterLoginFailure implements authService.ILoginAttemptCounter.
+// Stale counters are purged in the same transaction
+func (d *PostgresDataProvider) RegisterLoginFailure(
+	ctx context.Context,
+	key string,
+	policy loginthrottle.Policy,
+) (time.Time, error) {
+	var lockedUntil time.Time
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Where("locked_until < ? AND last_failure_at < ?", now, now.Add(-policy.LockoutDuration)).
+			Delete(&postgresStorageORM.LoginAttemptPG{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&postgresStorageORM.LoginAttemptPG{Key: key}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var record postgresStorageORM.LoginAttemptPG
+		err = tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Take(&record, "key = ?", key).
+			Error
+		if err != nil {
+			return err
+		}
+
+		attempts := policy.Fail(loginthrottle.Attempts{
+			Failures:      record.Failures,
+			LastFailureAt: record.LastFailureAt,
+			LockedUntil:   record.LockedUntil,
+		}, now)
+		lockedUntil = attempts.LockedUntil
+
+		return tx.Model(&postgresStorageORM.LoginAttemptPG{}).
+			Where("key = ?", key).
+			UpdateColumns(map[string]interface{}{
+				"failures":        attempts.Failures,
+				"last_failure_at": attempts.LastFailureAt,
+				"locked_until":    attempts.LockedUntil,
+			}).
+			Error
+	})
+
+	if err != nil {
+		return time.Time{}, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return lockedUntil, nil
+}
+
+// ResetLoginFailures implements authService.ILoginAttemptCounter
+func (d *PostgresDataProvider) ResetLoginFailures(ctx context.Context, key string) error {
+	err := d.db.WithContext(ctx).Where("key = ?", key).Delete(&postgresStorageORM.LoginAttemptPG{}).Error
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/lib/loginthrottle"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_RegisterLoginFailure_Lockout(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	policy := loginthrottle.Policy{
+		Threshold:       3,
+		BackoffBase:     time.Second,
+		LockoutDuration: time.Minute,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM "loginAttempts" WHERE locked_until < \$1 AND last_failure_at < \$2$`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^INSERT INTO "loginAttempts" \("key","failures","last_failure_at","locked_until"\) `+
+		`VALUES \(\$1,\$2,\$3,\$4\) ON CONFLICT DO NOTHING$`).
+		WithArgs("user:test", 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`^SELECT \* FROM "loginAttempts" WHERE key = \$1 LIMIT \$2 FOR UPDATE$`).
+		WithArgs("user:test", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "failures", "last_failure_at", "locked_until"}).
+			AddRow("user:test", 2, time.Now().Add(-time.Second), time.Now()))
+	mock.ExpectExec(`^UPDATE "loginAttempts" SET "failures"=\$1,"last_failure_at"=\$2,"locked_until"=\$3 WHERE key = \$4$`).
+		WithArgs(0, sqlmock.AnyArg(), sqlmock.AnyArg(), "user:test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	lockedUntil, err := storageService.RegisterLoginFailure(ctx, "user:test", policy)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), lockedUntil, time.Second)
+}
+
+func TestPostgresDataProvider_GetLoginLock_NoFailures(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`^SELECT "locked_until" FROM "loginAttempts" WHERE key = \$1$`).
+		WithArgs("ip:127.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"locked_until"}))
+
+	lockedUntil, err := storageService.GetLoginLock(ctx, "ip:127.0.0.1")
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.True(t, lockedUntil.IsZero())
+}
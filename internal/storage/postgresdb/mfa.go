@@ -0,0 +1,233 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetTOTPSecret implements authService.IMfaCreator.
+// Replaces unconfirmed enrollment. Returns storage.ErrAlreadyExists if enrollment is confirmed
+func (d *PostgresDataProvider) SetTOTPSecret(ctx context.Context, userID uint64, secret []byte) error {
+	result := d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "created_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: `"mfaTotp".confirmed_at IS NULL`},
+			}},
+		}).
+		Create(&postgresStorageORM.TOTPSecretPG{
+			UserID:    userID,
+			Secret:    secret,
+			CreatedAt: time.Now(),
+		})
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// GetTOTP implements authService.IMfaGetter
+func (d *PostgresDataProvider) GetTOTP(ctx context.Context, userID uint64) (*storageDTO.TOTP, error) {
+	var secret postgresStorageORM.TOTPSecretPG
+	err := d.db.WithContext(ctx).Take(&secret, "user_id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return &storageDTO.TOTP{
+		UserID:       secret.UserID,
+		Secret:       secret.Secret,
+		ConfirmedAt:  secret.ConfirmedAt,
+		LastUsedStep: secret.LastUsedStep,
+	}, nil
+}
+
+// ConfirmTOTP implements authService.IMfaUpdater.
+// Enables enrollment confirmed by code of step.
+// Returns storage.ErrNotFound if there is no unconfirmed enrollment or step is already used
+func (d *PostgresDataProvider) ConfirmTOTP(ctx context.Context, userID uint64, step int64) error {
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.TOTPSecretPG{}).
+		Where("user_id = ? AND confirmed_at IS NULL AND last_used_step < ?", userID, step).
+		UpdateColumns(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": step,
+		})
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// UseTOTPStep implements authService.IMfaUpdater.
+// Returns storage.ErrTokenReused if code of step or later one was already used
+func (d *PostgresDataProvider) UseTOTPStep(ctx context.Context, userID uint64, step int64) error {
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.TOTPSecretPG{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		UpdateColumn("last_used_step", step)
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrTokenReused
+	}
+
+	return nil
+}
+
+// DeleteTOTP implements authService.IMfaDeleter.
+// Removes enrollment with recovery codes. Returns storage.ErrNotFound if user has no enrollment
+func (d *PostgresDataProvider) DeleteTOTP(ctx context.Context, userID uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&postgresStorageORM.RecoveryCodePG{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("user_id = ?", userID).Delete(&postgresStorageORM.TOTPSecretPG{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return storage.ErrNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes implements authService.IMfaCreator.
+// Previous codes of user stop working
+func (d *PostgresDataProvider) ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes [][]byte) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&postgresStorageORM.RecoveryCodePG{}).Error
+		if err != nil {
+			return err
+		}
+
+		codes := make([]postgresStorageORM.RecoveryCodePG, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, postgresStorageORM.RecoveryCodePG{
+				UserID:   userID,
+				CodeHash: codeHash,
+			})
+		}
+
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codes).Error
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode implements authService.IMfaUpdater.
+// Returns storage.ErrNotFound for unknown and used codes
+func (d *PostgresDataProvider) UseRecoveryCode(ctx context.Context, userID uint64, codeHash []byte) error {
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.RecoveryCodePG{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateMfaChallenge implements authService.IMfaCreator.
+// Expired challenges are purged in the same transaction
+func (d *PostgresDataProvider) CreateMfaChallenge(
+	ctx context.Context,
+	challenge storageDTO.MfaChallenge,
+	tokenHash []byte,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("expires_at < ?", time.Now()).Delete(&postgresStorageORM.MfaChallengePG{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&postgresStorageORM.MfaChallengePG{
+			UserID:    challenge.UserID,
+			TokenHash: tokenHash,
+			ExpiresAt: challenge.ExpiresAt,
+		}).Error
+	})
+
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+// GetMfaChallenge implements authService.IMfaGetter.
+// Returns storage.ErrNotFound for unknown and expired challenges
+func (d *PostgresDataProvider) GetMfaChallenge(ctx context.Context, tokenHash []byte) (*storageDTO.MfaChallenge, error) {
+	var challenge postgresStorageORM.MfaChallengePG
+	err := d.db.WithContext(ctx).
+		Take(&challenge, "token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return &storageDTO.MfaChallenge{
+		ID:        challenge.ID,
+		UserID:    challenge.UserID,
+		ExpiresAt: challenge.ExpiresAt,
+	}, nil
+}
+
+// DeleteMfaChallenge implements authService.IMfaDeleter.
+// Returns storage.ErrNotFound if challenge is already deleted, so challenge completes once
+func (d *PostgresDataProvider) DeleteMfaChallenge(ctx context.Context, challengeID uint64) error {
+	result := d.db.WithContext(ctx).Delete(&postgresStorageORM.MfaChallengePG{}, challengeID)
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_SetTOTPSecret_Error_Confirmed(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO "mfaTotp" \("user_id","secret","confirmed_at","last_used_step","created_at"\) `+
+		`VALUES \(\$1,\$2,\$3,\$4,\$5\) ON CONFLICT \("user_id"\) DO UPDATE SET `+
+		`"secret"="excluded"."secret","last_used_step"="excluded"."last_used_step","created_at"="excluded"."created_at" `+
+		`WHERE "mfaTotp".confirmed_at IS NULL  RETURNING "created_at"$`).
+		WithArgs(1, []byte("encrypted"), nil, 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}))
+	mock.ExpectCommit()
+
+	err := storageService.SetTOTPSecret(ctx, 1, []byte("encrypted"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrAlreadyExists)
+}
+
+func TestPostgresDataProvider_UseTOTPStep_Error_Reused(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "mfaTotp" SET "last_used_step"=\$1 `+
+		`WHERE user_id = \$2 AND confirmed_at IS NOT NULL AND last_used_step < \$3$`).
+		WithArgs(100, 1, 100).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := storageService.UseTOTPStep(ctx, 1, 100)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrTokenReused)
+}
+
+func TestPostgresDataProvider_ReplaceRecoveryCodes_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM "mfaRecoveryCodes" WHERE user_id = \$1$`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`^INSERT INTO "mfaRecoveryCodes" \("user_id","code_hash","used_at"\) `+
+		`VALUES \(\$1,\$2,\$3\),\(\$4,\$5,\$6\) RETURNING "id","created_at"$`).
+		WithArgs(1, []byte("a"), nil, 1, []byte("b"), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectCommit()
+
+	err := storageService.ReplaceRecoveryCodes(ctx, 1, [][]byte{[]byte("a"), []byte("b")})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_DeleteMfaChallenge_Error_NotFound(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM "mfaChallenges" WHERE "mfaChallenges"."id" = \$1$`).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := storageService.DeleteMfaChallenge(ctx, 4)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -0,0 +1,86 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePasswordResetToken implements accountService.IPasswordResetCreator.
+// Previous tokens of user stop working. Expired tokens are purged in the same transaction
+func (d *PostgresDataProvider) CreatePasswordResetToken(
+	ctx context.Context,
+	userID uint64,
+	tokenHash []byte,
+	expiresAt time.Time,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? OR expires_at < ?", userID, time.Now()).
+			Delete(&postgresStorageORM.PasswordResetTokenPG{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&postgresStorageORM.PasswordResetTokenPG{
+			UserID:    userID,
+			TokenHash: tokenHash,
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+// ResetPassword implements accountService.IAccountUpdater.
+// Uses reset token and sets password hash of its user. Returns user id.
+// Returns storage.ErrNotFound for unknown, used and expired tokens
+func (d *PostgresDataProvider) ResetPassword(ctx context.Context, tokenHash []byte, passwordHash []byte) (uint64, error) {
+	var userID uint64
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var token postgresStorageORM.PasswordResetTokenPG
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Take(&token, "token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrNotFound
+			}
+			return err
+		}
+
+		err = tx.Model(&token).UpdateColumn("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&postgresStorageORM.UserPG{}).
+			Where("id = ?", token.UserID).
+			UpdateColumn("password_hash", passwordHash).
+			Error
+		if err != nil {
+			return err
+		}
+
+		userID = token.UserID
+		return nil
+	})
+
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,53 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_ResetPassword_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT \* FROM "passwordResetTokens" WHERE token_hash = \$1 AND used_at IS NULL `+
+		`AND expires_at > \$2 LIMIT \$3 FOR UPDATE$`).
+		WithArgs([]byte("hash"), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at"}).
+			AddRow(7, 3, []byte("hash"), time.Now().Add(time.Hour)))
+	mock.ExpectExec(`^UPDATE "passwordResetTokens" SET "used_at"=\$1 WHERE "id" = \$2$`).
+		WithArgs(sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE "users" SET "password_hash"=\$1 WHERE id = \$2$`).
+		WithArgs([]byte("password_hash"), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	userID, err := storageService.ResetPassword(ctx, []byte("hash"), []byte("password_hash"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), userID)
+}
+
+func TestPostgresDataProvider_ResetPassword_Error_NotFound(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT \* FROM "passwordResetTokens" WHERE token_hash = \$1 AND used_at IS NULL `+
+		`AND expires_at > \$2 LIMIT \$3 FOR UPDATE$`).
+		WithArgs([]byte("hash"), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err := storageService.ResetPassword(ctx, []byte("hash"), []byte("password_hash"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -5,8 +5,12 @@ import (
 	"context"
 	"errors"
 	"gorm.io/driver/postgres"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	serviceDTO "github.com/IldarGaleev/todo-backend-service/internal/services/servicedto"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage"
@@ -15,6 +19,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// jwtTokenIDSequence issues JWT identifiers which stay unique across restarts and replicas
+const jwtTokenIDSequence = "jwt_token_id_seq"
+
 type PostgresDataProvider struct {
 	log *slog.Logger
 	dsn string
@@ -54,13 +61,28 @@ func (d *PostgresDataProvider) runWithDialector(dialector gorm.Dialector, silent
 
 	err = db.AutoMigrate(
 		&postgresStorageORM.UserPG{},
+		&postgresStorageORM.ProjectPG{},
 		&postgresStorageORM.ToDoItemPG{},
+		&postgresStorageORM.RevokedTokenPG{},
+		&postgresStorageORM.ShareMemberPG{},
+		&postgresStorageORM.SessionPG{},
+		&postgresStorageORM.RefreshTokenPG{},
+		&postgresStorageORM.LoginAttemptPG{},
+		&postgresStorageORM.TOTPSecretPG{},
+		&postgresStorageORM.RecoveryCodePG{},
+		&postgresStorageORM.MfaChallengePG{},
+		&postgresStorageORM.PasswordResetTokenPG{},
 	)
 
 	if err != nil {
 		return errors.Join(storage.ErrDatabaseError, err)
 	}
 
+	err = db.Exec("CREATE SEQUENCE IF NOT EXISTS " + jwtTokenIDSequence).Error
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
 	return nil
 }
 
@@ -88,25 +110,212 @@ func (d *PostgresDataProvider) Stop() error {
 	return nil
 }
 
+// storageError joins database errors with storage.ErrDatabaseError, storage errors are returned as is
+func storageError(err error) error {
+	for _, known := range []error{
+		storage.ErrNotFound,
+		storage.ErrAccessDenied,
+		storage.ErrInvalidCursor,
+		storage.ErrAlreadyExists,
+		storage.ErrInvalidOrder,
+		storage.ErrReferenceNotFound,
+		storage.ErrTokenReused,
+		storage.ErrVersionConflict,
+		storage.ErrBatchAborted,
+		storage.ErrDatabaseError,
+	} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	return errors.Join(storage.ErrDatabaseError, err)
+}
+
+// toDoItemFromPG converts ORM model to storage DTO
+func toDoItemFromPG(item postgresStorageORM.ToDoItemPG) storageDTO.ToDoItem {
+	priority := storageDTO.ToDoItemPriority(item.Priority)
+	return storageDTO.ToDoItem{
+		Id:           item.ID,
+		Title:        &item.Title,
+		IsComplete:   &item.IsComplete,
+		OwnerId:      item.OwnerID,
+		ProjectID:    item.ProjectID,
+		ParentID:     item.ParentID,
+		Depth:        int(item.Depth),
+		Position:     item.Position,
+		AutoComplete: &item.AutoComplete,
+		Notes:        &item.Notes,
+		Priority:     &priority,
+		DueAt:        item.DueAt,
+		CreatedAt:    item.CreatedAt,
+		UpdatedAt:    item.UpdatedAt,
+		CompletedAt:  item.CompletedAt,
+		Version:      item.Version,
+		DeletedAt:    deletedAt(item.DeletedAt),
+		Recurrence:   recurrenceFromPG(item),
+	}
+}
+
+// recurrenceFromPG returns recurrence of item or nil
+func recurrenceFromPG(item postgresStorageORM.ToDoItemPG) *storageDTO.ToDoItemRecurrence {
+	if item.RecurrenceRule == "" {
+		return nil
+	}
+	return &storageDTO.ToDoItemRecurrence{
+		Rule:     item.RecurrenceRule,
+		TimeZone: item.RecurrenceTimeZone,
+	}
+}
+
+// deletedAt returns time item was moved to trash or nil
+func deletedAt(value gorm.DeletedAt) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
 // StorageToDoItem_Create implements todoService.IToDoItemCreator.
-func (d *PostgresDataProvider) StorageToDoItemCreate(ctx context.Context, title string, ownerID uint64) (uint64, error) {
+// Child items are appended after their siblings and complete state of ancestors is refreshed
+func (d *PostgresDataProvider) StorageToDoItemCreate(ctx context.Context, item storageDTO.ToDoItem, ownerID uint64) (uint64, error) {
+	var id uint64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		id, err = d.createItem(tx, item, ownerID)
+		return err
+	})
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	return id, nil
+}
+
+// createItem inserts item of owner within transaction tx
+func (d *PostgresDataProvider) createItem(tx *gorm.DB, item storageDTO.ToDoItem, ownerID uint64) (uint64, error) {
 	newItem := postgresStorageORM.ToDoItemPG{
-		OwnerID: ownerID,
-		Title:   title,
+		OwnerID:  ownerID,
+		Priority: int8(storageDTO.ToDoItemPriorityNormal),
 	}
 
-	result := d.db.WithContext(ctx).Create(&newItem)
-	if result.Error != nil {
-		return 0, errors.Join(storage.ErrDatabaseError, result.Error)
+	if item.DueAt != nil && !item.DueAt.IsZero() {
+		newItem.DueAt = item.DueAt
+	}
+
+	if item.ProjectID != nil && *item.ProjectID != 0 {
+		newItem.ProjectID = item.ProjectID
+	}
+
+	if item.Title != nil {
+		newItem.Title = *item.Title
+	}
+
+	if item.Notes != nil {
+		newItem.Notes = *item.Notes
+	}
+
+	if item.Priority != nil {
+		newItem.Priority = int8(*item.Priority)
+	}
+
+	if item.AutoComplete != nil {
+		newItem.AutoComplete = *item.AutoComplete
+	}
+
+	if item.Recurrence != nil {
+		newItem.RecurrenceRule = item.Recurrence.Rule
+		newItem.RecurrenceTimeZone = item.Recurrence.TimeZone
+	}
+
+	if newItem.ProjectID != nil {
+		err := referenceProject(tx, *newItem.ProjectID, ownerID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if item.ParentID != nil {
+		parent, err := lockParent(tx, *item.ParentID, ownerID)
+		if err != nil {
+			return 0, err
+		}
+
+		newItem.ParentID = &parent.ID
+		newItem.Depth = parent.Depth + 1
+
+		err = tx.Model(&postgresStorageORM.ToDoItemPG{}).
+			Select("COALESCE(MAX(position), 0) + 1").
+			Where("parent_id = ?", parent.ID).
+			Scan(&newItem.Position).
+			Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err := tx.Create(&newItem).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = refreshAncestors(tx, newItem.ParentID)
+	if err != nil {
+		return 0, err
 	}
 
 	return newItem.ID, nil
 }
 
+// itemAccessError returns storage.ErrAccessDenied if item exists but belongs to another owner
+// and storage.ErrNotFound if item does not exist
+func (d *PostgresDataProvider) itemAccessError(tx *gorm.DB, itemID uint64) error {
+	var count int64
+	result := tx.Model(&postgresStorageORM.ToDoItemPG{}).Where("id = ?", itemID).Count(&count)
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if count > 0 {
+		return storage.ErrAccessDenied
+	}
+
+	return storage.ErrNotFound
+}
+
+// itemVersionError returns storage.ErrVersionConflict if item of owner exists,
+// item access error otherwise
+func (d *PostgresDataProvider) itemVersionError(tx *gorm.DB, itemID uint64, ownerID uint64) error {
+	var count int64
+	result := tx.Model(&postgresStorageORM.ToDoItemPG{}).Where("id = ? AND owner_id = ?", itemID, ownerID).Count(&count)
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if count > 0 {
+		return storage.ErrVersionConflict
+	}
+
+	return d.itemAccessError(tx, itemID)
+}
+
 // StorageToDoItem_Update implements todoService.IToDoItemUpdater.
+// Complete state of ancestors is refreshed in the same transaction
 func (d *PostgresDataProvider) StorageToDoItemUpdate(ctx context.Context, item storageDTO.ToDoItem, ownerID uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return d.updateItem(tx, item, ownerID)
+	})
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
 
-	updatedFields := make(map[string]interface{}, 2)
+// updateItem changes set fields of item of owner within transaction tx
+func (d *PostgresDataProvider) updateItem(tx *gorm.DB, item storageDTO.ToDoItem, ownerID uint64) error {
+	updatedFields := make(map[string]interface{}, 8)
 
 	if item.Title != nil {
 		updatedFields["title"] = *item.Title
@@ -114,85 +323,376 @@ func (d *PostgresDataProvider) StorageToDoItemUpdate(ctx context.Context, item s
 
 	if item.IsComplete != nil {
 		updatedFields["is_complete"] = *item.IsComplete
+		if *item.IsComplete {
+			updatedFields["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", time.Now())
+		} else {
+			updatedFields["completed_at"] = nil
+		}
+	}
+
+	if item.Notes != nil {
+		updatedFields["notes"] = *item.Notes
+	}
+
+	if item.Priority != nil {
+		updatedFields["priority"] = int8(*item.Priority)
 	}
 
-	result := d.db.WithContext(ctx).Model(&postgresStorageORM.ToDoItemPG{ID: item.Id}).Updates(updatedFields)
+	if item.DueAt != nil {
+		if item.DueAt.IsZero() {
+			updatedFields["due_at"] = nil
+		} else {
+			updatedFields["due_at"] = *item.DueAt
+		}
+	}
+
+	if item.ProjectID != nil {
+		if *item.ProjectID == 0 {
+			updatedFields["project_id"] = nil
+		} else {
+			updatedFields["project_id"] = *item.ProjectID
+		}
+	}
+
+	if item.AutoComplete != nil {
+		updatedFields["auto_complete"] = *item.AutoComplete
+	}
+
+	if item.Recurrence != nil {
+		updatedFields["recurrence_rule"] = item.Recurrence.Rule
+		updatedFields["recurrence_time_zone"] = item.Recurrence.TimeZone
+	}
+
+	if len(updatedFields) > 0 {
+		updatedFields["version"] = gorm.Expr("version + 1")
+	}
+
+	if item.ProjectID != nil && *item.ProjectID != 0 {
+		err := referenceProject(tx, *item.ProjectID, ownerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	scope := tx.Model(&postgresStorageORM.ToDoItemPG{}).Where("id = ? AND owner_id = ?", item.Id, ownerID)
+	if item.Version != 0 {
+		scope = scope.Where("version = ?", item.Version)
+	}
+
+	var result *gorm.DB
+	var affected int64
+	if len(updatedFields) == 0 {
+		result = scope.Count(&affected)
+	} else {
+		result = scope.Updates(updatedFields)
+		affected = result.RowsAffected
+	}
 
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return storage.ErrNotFound
+		return result.Error
+	}
+
+	if affected == 0 {
+		if item.Version != 0 {
+			return d.itemVersionError(tx, item.Id, ownerID)
 		}
-		errors.Join(storage.ErrDatabaseError, result.Error)
+		return d.itemAccessError(tx, item.Id)
 	}
 
-	return nil
+	switch {
+	case item.AutoComplete != nil && *item.AutoComplete:
+		return refreshAncestors(tx, &item.Id)
+	case item.IsComplete != nil:
+		var parentID *uint64
+		err := tx.Model(&postgresStorageORM.ToDoItemPG{}).
+			Select("parent_id").
+			Where("id = ?", item.Id).
+			Scan(&parentID).
+			Error
+		if err != nil {
+			return err
+		}
+		return refreshAncestors(tx, parentID)
+	default:
+		return nil
+	}
 }
 
 // StorageToDoItem_GetById implements todoService.IToDoItemGetter.
 func (d *PostgresDataProvider) StorageToDoItemGetByID(ctx context.Context, itemID uint64, ownerID uint64) (*storageDTO.ToDoItem, error) {
 	var item postgresStorageORM.ToDoItemPG
-	result := d.db.WithContext(ctx).First(&item, itemID)
+	db := d.db.WithContext(ctx)
+	result := db.First(&item, "id = ? AND owner_id = ?", itemID, ownerID)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, storage.ErrNotFound
+			return nil, d.itemAccessError(db, itemID)
 		}
 		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
 	}
 
-	return &storageDTO.ToDoItem{
-		Id:         item.ID,
-		Title:      &item.Title,
-		IsComplete: &item.IsComplete,
-		OwnerId:    item.OwnerID,
-	}, nil
+	storageItems := []storageDTO.ToDoItem{toDoItemFromPG(item)}
+	err := fillChildCounts(db, storageItems)
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return &storageItems[0], nil
+}
+
+// likePattern escapes LIKE wildcards of substring
+func likePattern(substring string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(substring) + "%"
+}
+
+// itemSortKey describes keyset pagination over one sort column
+type itemSortKey struct {
+	column   string
+	desc     bool
+	nullable bool
+	// value returns cursor key of item. Nil for NULL
+	value func(item postgresStorageORM.ToDoItemPG) *string
+	// parse converts cursor key to query argument
+	parse func(key string) (interface{}, error)
+}
+
+func stringKey(value string) *string {
+	return &value
+}
+
+func parseStringKey(key string) (interface{}, error) {
+	return key, nil
+}
+
+func timeKey(value time.Time) *string {
+	return stringKey(value.UTC().Format(time.RFC3339Nano))
+}
+
+func parseTimeKey(key string) (interface{}, error) {
+	return time.Parse(time.RFC3339Nano, key)
+}
+
+func parseIntKey(key string) (interface{}, error) {
+	return strconv.Atoi(key)
+}
+
+func parseInt64Key(key string) (interface{}, error) {
+	return strconv.ParseInt(key, 10, 64)
+}
+
+// sortKey returns sort column of list order. Nil means sort by id only
+func sortKey(order storageDTO.ToDoItemSortOrder) *itemSortKey {
+	switch order {
+	case storageDTO.ToDoItemSortByTitleAsc, storageDTO.ToDoItemSortByTitleDesc:
+		return &itemSortKey{
+			column: "title",
+			desc:   order == storageDTO.ToDoItemSortByTitleDesc,
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				return stringKey(item.Title)
+			},
+			parse: parseStringKey,
+		}
+	case storageDTO.ToDoItemSortByDueAtAsc, storageDTO.ToDoItemSortByDueAtDesc:
+		return &itemSortKey{
+			column:   "due_at",
+			desc:     order == storageDTO.ToDoItemSortByDueAtDesc,
+			nullable: true,
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				if item.DueAt == nil {
+					return nil
+				}
+				return timeKey(*item.DueAt)
+			},
+			parse: parseTimeKey,
+		}
+	case storageDTO.ToDoItemSortByPriorityAsc, storageDTO.ToDoItemSortByPriorityDesc:
+		return &itemSortKey{
+			column: "priority",
+			desc:   order == storageDTO.ToDoItemSortByPriorityDesc,
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				return stringKey(strconv.Itoa(int(item.Priority)))
+			},
+			parse: parseIntKey,
+		}
+	case storageDTO.ToDoItemSortByPositionAsc:
+		return &itemSortKey{
+			column: "position",
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				return stringKey(strconv.FormatInt(item.Position, 10))
+			},
+			parse: parseInt64Key,
+		}
+	case storageDTO.ToDoItemSortByCreatedAtAsc, storageDTO.ToDoItemSortByCreatedAtDesc:
+		return &itemSortKey{
+			column: "created_at",
+			desc:   order == storageDTO.ToDoItemSortByCreatedAtDesc,
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				return timeKey(item.CreatedAt)
+			},
+			parse: parseTimeKey,
+		}
+	case storageDTO.ToDoItemSortByUpdatedAtAsc, storageDTO.ToDoItemSortByUpdatedAtDesc:
+		return &itemSortKey{
+			column: "updated_at",
+			desc:   order == storageDTO.ToDoItemSortByUpdatedAtDesc,
+			value: func(item postgresStorageORM.ToDoItemPG) *string {
+				return timeKey(item.UpdatedAt)
+			},
+			parse: parseTimeKey,
+		}
+	default:
+		return nil
+	}
+}
+
+// applyListOrder adds keyset condition and ordering of sort order to query.
+// NULL keys of nullable columns are placed last in both directions
+func applyListOrder(tx *gorm.DB, order storageDTO.ToDoItemSortOrder, after *storageDTO.ToDoItemCursor) (*gorm.DB, error) {
+	key := sortKey(order)
+
+	desc := order == storageDTO.ToDoItemSortByIDDesc
+	if key != nil {
+		desc = key.desc
+	}
+
+	compare, direction := ">", "ASC"
+	if desc {
+		compare, direction = "<", "DESC"
+	}
+
+	if after != nil {
+		switch {
+		case key == nil:
+			tx = tx.Where("id "+compare+" ?", after.ID)
+		case after.Key == nil:
+			if !key.nullable {
+				return nil, storage.ErrInvalidCursor
+			}
+			tx = tx.Where(key.column+" IS NULL AND id "+compare+" ?", after.ID)
+		default:
+			value, err := key.parse(*after.Key)
+			if err != nil {
+				return nil, errors.Join(storage.ErrInvalidCursor, err)
+			}
+
+			if key.nullable {
+				tx = tx.Where(
+					"("+key.column+" "+compare+" ? OR "+key.column+" IS NULL OR ("+key.column+" = ? AND id "+compare+" ?))",
+					value, value, after.ID,
+				)
+			} else {
+				tx = tx.Where("("+key.column+", id) "+compare+" (?, ?)", value, after.ID)
+			}
+		}
+	}
+
+	if key != nil {
+		if key.nullable {
+			tx = tx.Order(key.column + " " + direction + " NULLS LAST")
+		} else {
+			tx = tx.Order(key.column + " " + direction)
+		}
+	}
+
+	return tx.Order("id " + direction), nil
 }
 
 // StorageToDoItem_GetList implements todoService.IToDoItemGetter.
-func (d *PostgresDataProvider) StorageToDoItemGetList(ctx context.Context, ownerID uint64) ([]storageDTO.ToDoItem, error) {
+// Returns cursor of the last item if there are more items after it
+func (d *PostgresDataProvider) StorageToDoItemGetList(
+	ctx context.Context,
+	ownerID uint64,
+	query storageDTO.ToDoItemListQuery,
+) ([]storageDTO.ToDoItem, *storageDTO.ToDoItemCursor, error) {
 	var items []postgresStorageORM.ToDoItemPG
 	var resultList []storageDTO.ToDoItem
 
-	result := d.db.WithContext(ctx).Find(&items, "owner_id = ?", ownerID)
-	if result.Error != nil {
-		return resultList, result.Error
+	db := d.db.WithContext(ctx)
+	var tx *gorm.DB
+	if query.IncludeShared {
+		tx = db.Where(
+			"(owner_id = ? OR id IN (?) OR project_id IN (?))",
+			ownerID,
+			sharedWith(db, storageDTO.ShareResourceTask, ownerID),
+			sharedWith(db, storageDTO.ShareResourceProject, ownerID),
+		)
+	} else {
+		tx = db.Where("owner_id = ?", ownerID)
 	}
 
-	for _, item := range items {
-		resultList = append(resultList, storageDTO.ToDoItem{
-			Id:         item.ID,
-			Title:      &item.Title,
-			IsComplete: &item.IsComplete,
-			OwnerId:    item.OwnerID,
-		})
+	if query.ParentID != nil {
+		tx = tx.Where("parent_id = ?", *query.ParentID)
+	} else {
+		tx = tx.Where("parent_id IS NULL")
 	}
 
-	return resultList, nil
-}
+	if query.ProjectID != nil {
+		if *query.ProjectID == 0 {
+			tx = tx.Where("project_id IS NULL")
+		} else {
+			tx = tx.Where("project_id = ?", *query.ProjectID)
+		}
+	}
+
+	if query.IsComplete != nil {
+		tx = tx.Where("is_complete = ?", *query.IsComplete)
+	}
+
+	if query.TitleContains != nil && *query.TitleContains != "" {
+		tx = tx.Where(`title ILIKE ? ESCAPE '\'`, likePattern(*query.TitleContains))
+	}
+
+	if len(query.Priorities) > 0 {
+		priorities := make([]int8, 0, len(query.Priorities))
+		for _, priority := range query.Priorities {
+			priorities = append(priorities, int8(priority))
+		}
+		tx = tx.Where("priority IN ?", priorities)
+	}
+
+	if query.DueAfter != nil {
+		tx = tx.Where("due_at >= ?", *query.DueAfter)
+	}
+
+	if query.DueBefore != nil {
+		tx = tx.Where("due_at < ?", *query.DueBefore)
+	}
 
-// StorageToDoItem_DeleteById implements todoService.IToDoItemDeleter.
-func (d *PostgresDataProvider) StorageToDoItemDeleteByID(ctx context.Context, itemID uint64, ownerID uint64) error {
-	result := d.db.WithContext(ctx).Delete(&postgresStorageORM.ToDoItemPG{}, itemID)
+	tx, err := applyListOrder(tx, query.SortOrder, query.After)
+	if err != nil {
+		return resultList, nil, err
+	}
+
+	if query.Limit > 0 {
+		tx = tx.Limit(query.Limit + 1)
+	}
 
+	result := tx.Find(&items)
 	if result.Error != nil {
-		return errors.Join(storage.ErrDatabaseError, result.Error)
+		return resultList, nil, errors.Join(storage.ErrDatabaseError, result.Error)
 	}
 
-	if result.RowsAffected == 0 {
-		return storage.ErrNotFound
+	var next *storageDTO.ToDoItemCursor
+	if query.Limit > 0 && len(items) > query.Limit {
+		items = items[:query.Limit]
+		last := items[len(items)-1]
+		next = &storageDTO.ToDoItemCursor{ID: last.ID}
+		if key := sortKey(query.SortOrder); key != nil {
+			next.Key = key.value(last)
+		}
 	}
 
-	return nil
-}
+	for _, item := range items {
+		resultList = append(resultList, toDoItemFromPG(item))
+	}
 
-// GetCredential implements credentialService.ICredentialStorageProvider.
-func (d *PostgresDataProvider) GetCredential(username string) (*storageDTO.Credential, error) {
-	log := d.log.With(slog.String("method", "GetCredential"))
-	log.Warn("get credential not implement")
-	return &storageDTO.Credential{
-		Username:  "user",
-		TokenHash: nil,
-	}, nil
+	err = fillChildCounts(db, resultList)
+	if err != nil {
+		return nil, nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return resultList, next, nil
 }
 
 // var _ authService.IAccountCreator = (*PostgresDataProvider)(nil)
@@ -235,13 +735,87 @@ func (d *PostgresDataProvider) CreateAccount(ctx context.Context, username strin
 		PasswordHash: passwordHash,
 	}
 
-	result := d.db.WithContext(ctx).Create(&newUser)
+	result := d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "username"}}, DoNothing: true}).
+		Create(&newUser)
 	if result.Error != nil {
 		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
 	}
 
+	if result.RowsAffected == 0 {
+		return nil, storage.ErrAlreadyExists
+	}
+
 	return &serviceDTO.User{
 		UserID:   &newUser.ID,
 		Username: &newUser.Username,
 	}, nil
 }
+
+// UpdatePasswordHash implements accountService.IAccountUpdater.
+func (d *PostgresDataProvider) UpdatePasswordHash(ctx context.Context, userID uint64, passwordHash []byte) error {
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.UserPG{}).
+		Where("id = ?", userID).
+		UpdateColumn("password_hash", passwordHash)
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateNewJWTID implements secretsJwt.IJWTIndexer.
+func (d *PostgresDataProvider) CreateNewJWTID(ctx context.Context) (uint64, error) {
+	var id uint64
+	result := d.db.WithContext(ctx).Raw("SELECT nextval('" + jwtTokenIDSequence + "')").Scan(&id)
+
+	if result.Error != nil {
+		return 0, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	return id, nil
+}
+
+// IsJWTRevoked implements secretsJwt.IJWTRevoker.
+func (d *PostgresDataProvider) IsJWTRevoked(ctx context.Context, id uint64) (bool, error) {
+	var count int64
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.RevokedTokenPG{}).
+		Where("token_id = ?", id).
+		Count(&count)
+
+	if result.Error != nil {
+		return false, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	return count > 0, nil
+}
+
+// RevokeJWT implements secretsJwt.IJWTRevoker.
+// Revocations of already expired tokens are purged in the same transaction
+func (d *PostgresDataProvider) RevokeJWT(ctx context.Context, id uint64, expiresAt time.Time) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", time.Now()).Delete(&postgresStorageORM.RevokedTokenPG{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&postgresStorageORM.RevokedTokenPG{
+			TokenID:   id,
+			ExpiresAt: expiresAt,
+		})
+
+		return result.Error
+	})
+
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return nil
+}
@@ -2,14 +2,17 @@ package postgresdb
 
 import (
 	"context"
+	"database/sql/driver"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 )
 
 func createStorage(t *testing.T) (*PostgresDataProvider, sqlmock.Sqlmock) {
@@ -172,6 +175,26 @@ func TestPostgresDataProvider_CreateAccount_Success(t *testing.T) {
 	require.Equal(t, "user1", *usr.Username)
 }
 
+func TestPostgresDataProvider_CreateAccount_Error_AlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	username := "user1"
+	passwordHash := []byte("pass")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO "users" (.+) ON CONFLICT \("username"\) DO NOTHING RETURNING "id"$`).
+		WithArgs(username, passwordHash).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	usr, err := storageService.CreateAccount(ctx, username, passwordHash)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrAlreadyExists)
+	require.Nil(t, usr)
+}
+
 func TestPostgresDataProvider_CreateAccount_Error_DBInternal(t *testing.T) {
 	ctx := context.Background()
 	storageService, mock := createStorage(t)
@@ -195,3 +218,538 @@ func TestPostgresDataProvider_CreateAccount_Error_DBInternal(t *testing.T) {
 	require.ErrorIs(t, err, gorm.ErrInvalidDB)
 	require.Nil(t, usr)
 }
+
+func TestPostgresDataProvider_CreateNewJWTID_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`SELECT nextval\('jwt_token_id_seq'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(42))
+
+	id, err := storageService.CreateNewJWTID(ctx)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), id)
+}
+
+func TestPostgresDataProvider_CreateNewJWTID_Error_DBInternal(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`SELECT nextval`).WillReturnError(gorm.ErrInvalidDB)
+
+	_, err := storageService.CreateNewJWTID(ctx)
+
+	require.ErrorIs(t, err, storage.ErrDatabaseError)
+}
+
+func TestPostgresDataProvider_IsJWTRevoked(t *testing.T) {
+	testCases := []struct {
+		name     string
+		count    int
+		expected bool
+	}{
+		{
+			name:     "revoked",
+			count:    1,
+			expected: true,
+		},
+		{
+			name:     "not revoked",
+			count:    0,
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			storageService, mock := createStorage(t)
+
+			tokenID := uint64(7)
+
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "revokedTokens"`).
+				WithArgs(tokenID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(testCase.count))
+
+			revoked, err := storageService.IsJWTRevoked(ctx, tokenID)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, revoked)
+		})
+	}
+}
+
+func TestPostgresDataProvider_IsJWTRevoked_Error_DBInternal(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`SELECT count`).WillReturnError(gorm.ErrInvalidDB)
+
+	_, err := storageService.IsJWTRevoked(ctx, 7)
+
+	require.ErrorIs(t, err, storage.ErrDatabaseError)
+}
+
+func TestPostgresDataProvider_RevokeJWT_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	tokenID := uint64(7)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM "revokedTokens" WHERE expires_at < (.+)$`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^INSERT INTO "revokedTokens" (.+) ON CONFLICT DO NOTHING$`).
+		WithArgs(tokenID, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.RevokeJWT(ctx, tokenID, expiresAt)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_RevokeJWT_Error_DBInternal(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^DELETE FROM "revokedTokens"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^INSERT INTO "revokedTokens"`).
+		WillReturnError(gorm.ErrInvalidDB)
+	mock.ExpectRollback()
+
+	err := storageService.RevokeJWT(ctx, 7, time.Now())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrDatabaseError)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetByID_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	rows := sqlmock.NewRows(
+		[]string{
+			"id",
+			"owner_id",
+			"title",
+			"is_complete",
+		}).AddRow(
+		itemID,
+		ownerID,
+		"task",
+		false,
+	)
+
+	mock.ExpectQuery(`SELECT \* FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\) AND "todoItems"."deleted_at" IS NULL`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(rows)
+	mock.ExpectQuery(
+		`^SELECT parent_id, COUNT\(\*\) AS total_count, COUNT\(\*\) FILTER \(WHERE is_complete\) AS done_count ` +
+			`FROM "todoItems" WHERE parent_id IN \(\$1\) AND "todoItems"."deleted_at" IS NULL GROUP BY "parent_id"$`).
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "total_count", "done_count"}).AddRow(itemID, 3, 2))
+
+	item, err := storageService.StorageToDoItemGetByID(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, itemID, item.Id)
+	require.Equal(t, ownerID, item.OwnerId)
+	require.Equal(t, "task", *item.Title)
+	require.Equal(t, int64(2), item.DoneCount)
+	require.Equal(t, int64(3), item.TotalCount)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetByID_Error_Scoped(t *testing.T) {
+	testCases := []struct {
+		name          string
+		existingCount int
+		expectedError error
+	}{
+		{
+			name:          "item of another owner",
+			existingCount: 1,
+			expectedError: storage.ErrAccessDenied,
+		},
+		{
+			name:          "missing item",
+			existingCount: 0,
+			expectedError: storage.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			storageService, mock := createStorage(t)
+
+			itemID := uint64(10)
+			ownerID := uint64(2)
+
+			mock.ExpectQuery(`SELECT \* FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\) AND "todoItems"."deleted_at" IS NULL`).
+				WithArgs(itemID, ownerID, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE id = \$1`).
+				WithArgs(itemID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(testCase.existingCount))
+
+			item, err := storageService.StorageToDoItemGetByID(ctx, itemID, ownerID)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.ErrorIs(t, err, testCase.expectedError)
+			require.Nil(t, item)
+		})
+	}
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+	title := "new title"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "todoItems" SET "title"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE \(id = \$3 AND owner_id = \$4\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(title, sqlmock.AnyArg(), itemID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: itemID, Title: &title}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_Error_AnotherOwner(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(2)
+	isComplete := true
+
+	mock.ExpectBegin()
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "completed_at"=COALESCE\(completed_at, \$1\),"is_complete"=\$2,"version"=version \+ 1,"updated_at"=\$3 `+
+			`WHERE \(id = \$4 AND owner_id = \$5\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), isComplete, sqlmock.AnyArg(), itemID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE id = \$1`).
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: itemID, IsComplete: &isComplete}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrAccessDenied)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_Error_DBInternal(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	title := "new title"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "todoItems"`).WillReturnError(gorm.ErrInvalidDB)
+	mock.ExpectRollback()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: 10, Title: &title}, 1)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrDatabaseError)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_ClearsDueAt(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "todoItems" SET "due_at"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE \(id = \$3 AND owner_id = \$4\) AND version = \$5 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(nil, sqlmock.AnyArg(), itemID, ownerID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: itemID, DueAt: &time.Time{}, Version: 3}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_SetsRecurrence(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "recurrence_rule"=\$1,"recurrence_time_zone"=\$2,"version"=version \+ 1,"updated_at"=\$3 `+
+			`WHERE \(id = \$4 AND owner_id = \$5\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs("FREQ=WEEKLY;BYDAY=MO", "Europe/Berlin", sqlmock.AnyArg(), itemID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{
+		Id:         itemID,
+		Recurrence: &storageDTO.ToDoItemRecurrence{Rule: "FREQ=WEEKLY;BYDAY=MO", TimeZone: "Europe/Berlin"},
+	}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_Error_VersionConflict(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+	title := "new title"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "todoItems" SET "title"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE \(id = \$3 AND owner_id = \$4\) AND version = \$5 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(title, sqlmock.AnyArg(), itemID, ownerID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\)`).
+		WithArgs(itemID, ownerID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: itemID, Title: &title, Version: 3}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrVersionConflict)
+}
+
+func TestPostgresDataProvider_StorageToDoItemDeleteByID_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(
+		`^SELECT "id","parent_id","project_id","depth" FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\) AND "todoItems"."deleted_at" IS NULL LIMIT \$3 FOR UPDATE$`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(itemID, nil, nil, 0))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "depth"=depth - 1 WHERE id IN \(WITH RECURSIVE subtree AS \(.+\)$`).
+		WithArgs(itemID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "parent_id"=\$1,"project_id"=\$2,"version"=version \+ 1,"updated_at"=\$3 WHERE parent_id = \$4$`).
+		WithArgs(nil, nil, sqlmock.AnyArg(), itemID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "deleted_at"=\$1 WHERE id = \$2 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), itemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemDeleteByID(ctx, itemID, ownerID, storageDTO.SubtaskDeleteReparent)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemDeleteByID_Error_Scoped(t *testing.T) {
+	testCases := []struct {
+		name          string
+		existingCount int
+		expectedError error
+	}{
+		{
+			name:          "item of another owner",
+			existingCount: 1,
+			expectedError: storage.ErrAccessDenied,
+		},
+		{
+			name:          "missing item",
+			existingCount: 0,
+			expectedError: storage.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			storageService, mock := createStorage(t)
+
+			itemID := uint64(10)
+			ownerID := uint64(2)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT "id","parent_id","project_id","depth" FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\) AND "todoItems"."deleted_at" IS NULL`).
+				WithArgs(itemID, ownerID, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(`SELECT count\(\*\) FROM "todoItems" WHERE id = \$1`).
+				WithArgs(itemID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(testCase.existingCount))
+			mock.ExpectRollback()
+
+			err := storageService.StorageToDoItemDeleteByID(ctx, itemID, ownerID, storageDTO.SubtaskDeleteCascade)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_FirstPage(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	ownerID := uint64(1)
+	isComplete := false
+	titleContains := "50%_off"
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "title", "is_complete"}).
+		AddRow(1, ownerID, "a", false).
+		AddRow(2, ownerID, "b", false).
+		AddRow(3, ownerID, "c", false)
+
+	mock.ExpectQuery(
+		`^SELECT \* FROM "todoItems" WHERE owner_id = \$1 AND parent_id IS NULL AND is_complete = \$2 `+
+			`AND title ILIKE \$3 ESCAPE '\\' AND "todoItems"."deleted_at" IS NULL ORDER BY title ASC,id ASC LIMIT \$4$`).
+		WithArgs(ownerID, isComplete, `%50\%\_off%`, 3).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`^SELECT parent_id, COUNT\(\*\) AS total_count, .+ WHERE parent_id IN \(\$1,\$2\)`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "total_count", "done_count"}))
+
+	items, next, err := storageService.StorageToDoItemGetList(ctx, ownerID, storageDTO.ToDoItemListQuery{
+		IsComplete:    &isComplete,
+		TitleContains: &titleContains,
+		SortOrder:     storageDTO.ToDoItemSortByTitleAsc,
+		Limit:         2,
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	lastKey := "b"
+	require.Equal(t, &storageDTO.ToDoItemCursor{ID: 2, Key: &lastKey}, next)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_LastPage(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	ownerID := uint64(1)
+
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "title", "is_complete"}).
+		AddRow(4, ownerID, "d", true)
+
+	mock.ExpectQuery(
+		`^SELECT \* FROM "todoItems" WHERE owner_id = \$1 AND parent_id IS NULL AND id < \$2 AND "todoItems"."deleted_at" IS NULL ORDER BY id DESC LIMIT \$3$`).
+		WithArgs(ownerID, 5, 3).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`^SELECT parent_id, COUNT\(\*\) AS total_count, .+ WHERE parent_id IN \(\$1\)`).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "total_count", "done_count"}))
+
+	items, next, err := storageService.StorageToDoItemGetList(ctx, ownerID, storageDTO.ToDoItemListQuery{
+		SortOrder: storageDTO.ToDoItemSortByIDDesc,
+		Limit:     2,
+		After:     &storageDTO.ToDoItemCursor{ID: 5},
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Nil(t, next)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_Error_DBInternal(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`SELECT`).WillReturnError(gorm.ErrInvalidDB)
+
+	_, _, err := storageService.StorageToDoItemGetList(ctx, 1, storageDTO.ToDoItemListQuery{})
+
+	require.ErrorIs(t, err, storage.ErrDatabaseError)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_DueAtCursor(t *testing.T) {
+	dueAt := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	dueAtKey := dueAt.Format(time.RFC3339Nano)
+
+	testCases := []struct {
+		name         string
+		after        *storageDTO.ToDoItemCursor
+		expectedSQL  string
+		expectedArgs []driver.Value
+	}{
+		{
+			name:  "after item with due date",
+			after: &storageDTO.ToDoItemCursor{ID: 3, Key: &dueAtKey},
+			expectedSQL: `^SELECT \* FROM "todoItems" WHERE owner_id = \$1 AND parent_id IS NULL AND priority IN \(\$2,\$3\) ` +
+				`AND \(\(due_at > \$4 OR due_at IS NULL OR \(due_at = \$5 AND id > \$6\)\)\) AND "todoItems"."deleted_at" IS NULL ` +
+				`ORDER BY due_at ASC NULLS LAST,id ASC LIMIT \$7$`,
+			expectedArgs: []driver.Value{1, 3, 4, dueAt, dueAt, 3, 11},
+		},
+		{
+			name:  "after item without due date",
+			after: &storageDTO.ToDoItemCursor{ID: 3},
+			expectedSQL: `^SELECT \* FROM "todoItems" WHERE owner_id = \$1 AND parent_id IS NULL AND priority IN \(\$2,\$3\) ` +
+				`AND \(due_at IS NULL AND id > \$4\) AND "todoItems"."deleted_at" IS NULL ` +
+				`ORDER BY due_at ASC NULLS LAST,id ASC LIMIT \$5$`,
+			expectedArgs: []driver.Value{1, 3, 4, 3, 11},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			storageService, mock := createStorage(t)
+
+			mock.ExpectQuery(testCase.expectedSQL).
+				WithArgs(testCase.expectedArgs...).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			_, next, err := storageService.StorageToDoItemGetList(ctx, 1, storageDTO.ToDoItemListQuery{
+				Priorities: []storageDTO.ToDoItemPriority{storageDTO.ToDoItemPriorityHigh, storageDTO.ToDoItemPriorityUrgent},
+				SortOrder:  storageDTO.ToDoItemSortByDueAtAsc,
+				Limit:      10,
+				After:      testCase.after,
+			})
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.NoError(t, err)
+			require.Nil(t, next)
+		})
+	}
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_Error_InvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	storageService, _ := createStorage(t)
+
+	key := "not a time"
+
+	_, _, err := storageService.StorageToDoItemGetList(ctx, 1, storageDTO.ToDoItemListQuery{
+		SortOrder: storageDTO.ToDoItemSortByCreatedAtDesc,
+		Limit:     10,
+		After:     &storageDTO.ToDoItemCursor{ID: 3, Key: &key},
+	})
+
+	require.ErrorIs(t, err, storage.ErrInvalidCursor)
+}
@@ -0,0 +1,15 @@
+package postgresstorageorm
+
+import "time"
+
+// LoginAttemptPG failed logins of username or source address
+type LoginAttemptPG struct {
+	Key           string    `gorm:"primaryKey;size:320"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   time.Time `gorm:"not null;index:idx_login_attempt_locked"`
+}
+
+func (LoginAttemptPG) TableName() string {
+	return "loginAttempts"
+}
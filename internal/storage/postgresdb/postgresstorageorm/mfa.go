@@ -0,0 +1,46 @@
+package postgresstorageorm
+
+import "time"
+
+// TOTPSecretPG TOTP enrollment of user. Secret is encrypted by service.
+// Codes of steps up to LastUsedStep are rejected to prevent replay
+type TOTPSecretPG struct {
+	UserID       uint64 `gorm:"primaryKey;autoincrement:false"`
+	User         UserPG `gorm:"constraint:OnDelete:CASCADE"`
+	Secret       []byte `gorm:"not null"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64     `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (TOTPSecretPG) TableName() string {
+	return "mfaTotp"
+}
+
+// RecoveryCodePG single-use MFA recovery code. Only hash of the code is stored
+type RecoveryCodePG struct {
+	ID        uint64 `gorm:"primaryKey;autoincrement"`
+	UserID    uint64 `gorm:"not null;uniqueIndex:idx_recovery_code_user_hash"`
+	User      UserPG `gorm:"constraint:OnDelete:CASCADE"`
+	CodeHash  []byte `gorm:"not null;uniqueIndex:idx_recovery_code_user_hash"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (RecoveryCodePG) TableName() string {
+	return "mfaRecoveryCodes"
+}
+
+// MfaChallengePG pending login waiting for MFA code. Only hash of the challenge token is stored
+type MfaChallengePG struct {
+	ID        uint64    `gorm:"primaryKey;autoincrement"`
+	UserID    uint64    `gorm:"not null;index:idx_mfa_challenge_user"`
+	User      UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	TokenHash []byte    `gorm:"not null;uniqueIndex:idx_mfa_challenge_hash"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_mfa_challenge_expires"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (MfaChallengePG) TableName() string {
+	return "mfaChallenges"
+}
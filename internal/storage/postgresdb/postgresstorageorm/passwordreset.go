@@ -0,0 +1,18 @@
+package postgresstorageorm
+
+import "time"
+
+// PasswordResetTokenPG single-use password reset token. Only hash of the token is stored
+type PasswordResetTokenPG struct {
+	ID        uint64    `gorm:"primaryKey;autoincrement"`
+	UserID    uint64    `gorm:"not null;index:idx_password_reset_user"`
+	User      UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	TokenHash []byte    `gorm:"not null;uniqueIndex:idx_password_reset_hash"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_password_reset_expires"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (PasswordResetTokenPG) TableName() string {
+	return "passwordResetTokens"
+}
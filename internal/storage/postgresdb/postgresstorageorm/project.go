@@ -0,0 +1,17 @@
+package postgresstorageorm
+
+import "time"
+
+type ProjectPG struct {
+	ID         uint64    `gorm:"primaryKey;autoincrement;index:idx_project"`
+	OwnerID    uint64    `gorm:"index:idx_project_owner"`
+	Owner      UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	Name       string    `gorm:"size:255;not null"`
+	IsArchived bool      `gorm:"not null;default:false"`
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ProjectPG) TableName() string {
+	return "projects"
+}
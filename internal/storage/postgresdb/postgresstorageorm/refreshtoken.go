@@ -0,0 +1,23 @@
+package postgresstorageorm
+
+import "time"
+
+// RefreshTokenPG single-use refresh token. Only hash of the token is stored.
+// Tokens issued by rotation share family of the token issued on login
+type RefreshTokenPG struct {
+	ID              uint64    `gorm:"primaryKey;autoincrement"`
+	FamilyID        uint64    `gorm:"not null;index:idx_refresh_token_family"`
+	UserID          uint64    `gorm:"not null;index:idx_refresh_token_user"`
+	User            UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	TokenHash       []byte    `gorm:"not null;uniqueIndex:idx_refresh_token_hash"`
+	AccessTokenID   uint64    `gorm:"not null;index:idx_refresh_token_access"`
+	AccessExpiresAt time.Time `gorm:"not null"`
+	ExpiresAt       time.Time `gorm:"not null;index:idx_refresh_token_expires"`
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (RefreshTokenPG) TableName() string {
+	return "refreshTokens"
+}
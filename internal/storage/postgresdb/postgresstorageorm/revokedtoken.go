@@ -0,0 +1,12 @@
+package postgresstorageorm
+
+import "time"
+
+type RevokedTokenPG struct {
+	TokenID   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_revoked_token_expires"`
+}
+
+func (RevokedTokenPG) TableName() string {
+	return "revokedTokens"
+}
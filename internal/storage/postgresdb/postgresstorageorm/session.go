@@ -0,0 +1,20 @@
+package postgresstorageorm
+
+import "time"
+
+// SessionPG login of user. Refresh tokens of session share its id as family
+type SessionPG struct {
+	ID         uint64    `gorm:"primaryKey;autoincrement"`
+	UserID     uint64    `gorm:"not null;index:idx_session_user"`
+	User       UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	UserAgent  string    `gorm:"size:255;not null;default:''"`
+	IP         string    `gorm:"size:45;not null;default:''"`
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	LastSeenAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	ExpiresAt  time.Time `gorm:"not null;index:idx_session_expires"`
+	RevokedAt  *time.Time
+}
+
+func (SessionPG) TableName() string {
+	return "sessions"
+}
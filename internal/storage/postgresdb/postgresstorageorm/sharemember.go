@@ -0,0 +1,17 @@
+package postgresstorageorm
+
+import "time"
+
+// ShareMemberPG grants user a role on shared task or project
+type ShareMemberPG struct {
+	ResourceType int8      `gorm:"primaryKey;autoIncrement:false"`
+	ResourceID   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	UserID       uint64    `gorm:"primaryKey;autoIncrement:false;index:idx_share_member_user"`
+	User         UserPG    `gorm:"constraint:OnDelete:CASCADE"`
+	Role         int8      `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ShareMemberPG) TableName() string {
+	return "shareMembers"
+}
@@ -1,12 +1,37 @@
 // Package postgresstorageorm contains Postgres ORM models
 package postgresstorageorm
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type ToDoItemPG struct {
-	ID         uint64 `gorm:"primaryKey;autoincrement;index:idx_todo_item"`
-	OwnerID    uint64 `gorm:"index:idx_owner"`
-	Owner      UserPG `gorm:"constraint:OnDelete:CASCADE"`
-	Title      string `gorm:"size:255;not null"`
-	IsComplete bool   `gorm:"default:false"`
+	ID           uint64      `gorm:"primaryKey;autoincrement;index:idx_todo_item"`
+	OwnerID      uint64      `gorm:"index:idx_owner"`
+	Owner        UserPG      `gorm:"constraint:OnDelete:CASCADE"`
+	ProjectID    *uint64     `gorm:"index:idx_todo_item_project"`
+	Project      *ProjectPG  `gorm:"constraint:OnDelete:SET NULL"`
+	ParentID     *uint64     `gorm:"index:idx_todo_item_parent"`
+	Parent       *ToDoItemPG `gorm:"constraint:OnDelete:CASCADE"`
+	Depth        int16       `gorm:"not null;default:0"`
+	Position     int64       `gorm:"not null;default:0"`
+	AutoComplete bool        `gorm:"not null;default:false"`
+	Title        string      `gorm:"size:255;not null"`
+	IsComplete   bool        `gorm:"default:false"`
+	Notes        string      `gorm:"type:text;not null;default:''"`
+	Priority     int8        `gorm:"not null;default:2"`
+	DueAt        *time.Time  `gorm:"index:idx_todo_item_due"`
+	CreatedAt    time.Time   `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt    time.Time   `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	CompletedAt  *time.Time
+	Version      int64 `gorm:"not null;default:1"`
+	// RecurrenceRule RRULE value, empty for items without recurrence
+	RecurrenceRule     string `gorm:"size:255;not null;default:''"`
+	RecurrenceTimeZone string `gorm:"size:64;not null;default:''"`
+	// DeletedAt moves item to trash, items in trash are excluded from queries unless unscoped
+	DeletedAt gorm.DeletedAt `gorm:"index:idx_todo_item_deleted"`
 }
 
 func (ToDoItemPG) TableName() string {
@@ -0,0 +1,236 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// projectFromPG converts ORM model to storage DTO
+func projectFromPG(project postgresStorageORM.ProjectPG) storageDTO.Project {
+	return storageDTO.Project{
+		Id:         project.ID,
+		OwnerId:    project.OwnerID,
+		Name:       &project.Name,
+		IsArchived: &project.IsArchived,
+		CreatedAt:  project.CreatedAt,
+		UpdatedAt:  project.UpdatedAt,
+	}
+}
+
+// lockProject locks owner project until the end of transaction.
+// Returns gorm.ErrRecordNotFound if owner has no such project
+func lockProject(tx *gorm.DB, projectID uint64, ownerID uint64, strength string) error {
+	var project postgresStorageORM.ProjectPG
+	return tx.Clauses(clause.Locking{Strength: strength}).
+		Select("id").
+		Take(&project, "id = ? AND owner_id = ?", projectID, ownerID).
+		Error
+}
+
+// referenceProject keeps referenced project from being deleted until the end of transaction.
+// Returns storage.ErrReferenceNotFound if owner has no such project
+func referenceProject(tx *gorm.DB, projectID uint64, ownerID uint64) error {
+	err := lockProject(tx, projectID, ownerID, clause.LockingStrengthShare)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrReferenceNotFound
+	}
+	return err
+}
+
+// projectAccessError returns storage.ErrAccessDenied if project exists but belongs to another owner
+// and storage.ErrNotFound if project does not exist
+func (d *PostgresDataProvider) projectAccessError(tx *gorm.DB, projectID uint64) error {
+	var count int64
+	result := tx.Model(&postgresStorageORM.ProjectPG{}).Where("id = ?", projectID).Count(&count)
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if count > 0 {
+		return storage.ErrAccessDenied
+	}
+
+	return storage.ErrNotFound
+}
+
+// StorageProjectCreate implements projectService.IProjectCreator.
+func (d *PostgresDataProvider) StorageProjectCreate(ctx context.Context, project storageDTO.Project, ownerID uint64) (uint64, error) {
+	newProject := postgresStorageORM.ProjectPG{
+		OwnerID: ownerID,
+	}
+
+	if project.Name != nil {
+		newProject.Name = *project.Name
+	}
+
+	result := d.db.WithContext(ctx).Create(&newProject)
+	if result.Error != nil {
+		return 0, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	return newProject.ID, nil
+}
+
+// StorageProjectGetByID implements projectService.IProjectGetter.
+func (d *PostgresDataProvider) StorageProjectGetByID(ctx context.Context, projectID uint64, ownerID uint64) (*storageDTO.Project, error) {
+	var project postgresStorageORM.ProjectPG
+	db := d.db.WithContext(ctx)
+	result := db.First(&project, "id = ? AND owner_id = ?", projectID, ownerID)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, d.projectAccessError(db, projectID)
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	storageProject := projectFromPG(project)
+	return &storageProject, nil
+}
+
+// StorageProjectGetList implements projectService.IProjectGetter.
+// includeShared adds projects shared with owner
+func (d *PostgresDataProvider) StorageProjectGetList(
+	ctx context.Context,
+	ownerID uint64,
+	includeArchived bool,
+	includeShared bool,
+) ([]storageDTO.Project, error) {
+	var projects []postgresStorageORM.ProjectPG
+
+	db := d.db.WithContext(ctx)
+	var tx *gorm.DB
+	if includeShared {
+		tx = db.Where("(owner_id = ? OR id IN (?))", ownerID, sharedWith(db, storageDTO.ShareResourceProject, ownerID))
+	} else {
+		tx = db.Where("owner_id = ?", ownerID)
+	}
+	if !includeArchived {
+		tx = tx.Where("is_archived = ?", false)
+	}
+
+	result := tx.Order("id ASC").Find(&projects)
+	if result.Error != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	resultList := make([]storageDTO.Project, 0, len(projects))
+	for _, project := range projects {
+		resultList = append(resultList, projectFromPG(project))
+	}
+
+	return resultList, nil
+}
+
+// StorageProjectUpdate implements projectService.IProjectUpdater.
+func (d *PostgresDataProvider) StorageProjectUpdate(ctx context.Context, project storageDTO.Project, ownerID uint64) error {
+	updatedFields := make(map[string]interface{}, 2)
+
+	if project.Name != nil {
+		updatedFields["name"] = *project.Name
+	}
+
+	if project.IsArchived != nil {
+		updatedFields["is_archived"] = *project.IsArchived
+	}
+
+	db := d.db.WithContext(ctx)
+	scope := db.Model(&postgresStorageORM.ProjectPG{}).Where("id = ? AND owner_id = ?", project.Id, ownerID)
+
+	var result *gorm.DB
+	var affected int64
+	if len(updatedFields) == 0 {
+		result = scope.Count(&affected)
+	} else {
+		result = scope.Updates(updatedFields)
+		affected = result.RowsAffected
+	}
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if affected == 0 {
+		return d.projectAccessError(db, project.Id)
+	}
+
+	return nil
+}
+
+// StorageProjectDeleteByID implements projectService.IProjectDeleter.
+// Project items are unassigned, moved to reassignTo project or moved to trash with their subtasks
+// depending on mode in the same transaction. Share members of the project are deleted too
+func (d *PostgresDataProvider) StorageProjectDeleteByID(
+	ctx context.Context,
+	projectID uint64,
+	ownerID uint64,
+	mode storageDTO.ProjectDeleteMode,
+	reassignTo uint64,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockProject(tx, projectID, ownerID, clause.LockingStrengthUpdate)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return d.projectAccessError(tx, projectID)
+			}
+			return err
+		}
+
+		items := tx.Model(&postgresStorageORM.ToDoItemPG{}).Where("project_id = ?", projectID)
+
+		switch mode {
+		case storageDTO.ProjectDeleteCascade:
+			projectItems := tx.Model(&postgresStorageORM.ToDoItemPG{}).Select("id").Where("project_id = ?", projectID)
+			err = tx.Model(&postgresStorageORM.ToDoItemPG{}).
+				Where("project_id = ? OR id IN (?)", projectID, descendantsOf(tx, projectItems)).
+				UpdateColumn("deleted_at", time.Now()).
+				Error
+		case storageDTO.ProjectDeleteReassign:
+			if reassignTo == projectID {
+				return storage.ErrReferenceNotFound
+			}
+			err = lockProject(tx, reassignTo, ownerID, clause.LockingStrengthShare)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrReferenceNotFound
+			}
+			if err == nil {
+				err = items.Updates(map[string]interface{}{
+					"project_id": reassignTo,
+					"version":    gorm.Expr("version + 1"),
+				}).Error
+			}
+		default:
+			err = items.Updates(map[string]interface{}{
+				"project_id": nil,
+				"version":    gorm.Expr("version + 1"),
+			}).Error
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("resource_type = ? AND resource_id = ?", int8(storageDTO.ShareResourceProject), projectID).
+			Delete(&postgresStorageORM.ShareMemberPG{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&postgresStorageORM.ProjectPG{}, projectID).Error
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_StorageProjectDeleteByID_Cascade(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	projectID := uint64(5)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id" FROM "projects" WHERE id = \$1 AND owner_id = \$2 LIMIT \$3 FOR UPDATE$`).
+		WithArgs(projectID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(projectID))
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "deleted_at"=\$1 WHERE \(project_id = \$2 OR id IN \(WITH RECURSIVE subtree AS `+
+			`\(SELECT id FROM "todoItems" WHERE parent_id IN \(SELECT "id" FROM "todoItems" WHERE project_id = \$3 AND "todoItems"."deleted_at" IS NULL\) `+
+			`UNION ALL .+\) SELECT id FROM subtree\)\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), projectID, projectID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^DELETE FROM "shareMembers" WHERE resource_type = \$1 AND resource_id = \$2$`).
+		WithArgs(int8(storageDTO.ShareResourceProject), projectID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^DELETE FROM "projects" WHERE "projects"."id" = \$1$`).
+		WithArgs(projectID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageProjectDeleteByID(ctx, projectID, ownerID, storageDTO.ProjectDeleteCascade, 0)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageProjectDeleteByID_Reassign(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	projectID := uint64(5)
+	targetID := uint64(6)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id" FROM "projects" WHERE id = \$1 AND owner_id = \$2 LIMIT \$3 FOR UPDATE$`).
+		WithArgs(projectID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(projectID))
+	mock.ExpectQuery(`^SELECT "id" FROM "projects" WHERE id = \$1 AND owner_id = \$2 LIMIT \$3 FOR SHARE$`).
+		WithArgs(targetID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(targetID))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "project_id"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE project_id = \$3 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(targetID, sqlmock.AnyArg(), projectID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^DELETE FROM "shareMembers" WHERE resource_type = \$1 AND resource_id = \$2$`).
+		WithArgs(int8(storageDTO.ShareResourceProject), projectID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^DELETE FROM "projects" WHERE "projects"."id" = \$1$`).
+		WithArgs(projectID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageProjectDeleteByID(ctx, projectID, ownerID, storageDTO.ProjectDeleteReassign, targetID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageProjectDeleteByID_Error_ForeignTarget(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(`FOR SHARE$`).
+		WithArgs(uint64(6), uint64(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := storageService.StorageProjectDeleteByID(ctx, 5, 1, storageDTO.ProjectDeleteReassign, 6)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrReferenceNotFound)
+}
+
+func TestPostgresDataProvider_StorageProjectDeleteByID_Error_AnotherOwner(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	projectID := uint64(5)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`^SELECT count\(\*\) FROM "projects" WHERE id = \$1$`).
+		WithArgs(projectID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := storageService.StorageProjectDeleteByID(ctx, projectID, 2, storageDTO.ProjectDeleteUnassign, 0)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrAccessDenied)
+}
+
+func TestPostgresDataProvider_StorageToDoItemCreate_Error_ForeignProject(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	projectID := uint64(5)
+	ownerID := uint64(2)
+	title := "task"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id" FROM "projects" WHERE id = \$1 AND owner_id = \$2 LIMIT \$3 FOR SHARE$`).
+		WithArgs(projectID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err := storageService.StorageToDoItemCreate(ctx, storageDTO.ToDoItem{Title: &title, ProjectID: &projectID}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrReferenceNotFound)
+}
@@ -0,0 +1,138 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateRefreshToken implements secretsJwt.IRefreshTokenStorage.
+// Session of token family is extended up to token expiration.
+// Returns storage.ErrNotFound if session is revoked or expired.
+// Expired refresh tokens and sessions are purged in the same transaction
+func (d *PostgresDataProvider) CreateRefreshToken(
+	ctx context.Context,
+	token storageDTO.RefreshToken,
+	tokenHash []byte,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		result := tx.Model(&postgresStorageORM.SessionPG{}).
+			Where("id = ? AND revoked_at IS NULL AND expires_at > ?", token.FamilyID, now).
+			UpdateColumns(map[string]interface{}{
+				"last_seen_at": now,
+				"expires_at":   token.ExpiresAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return storage.ErrNotFound
+		}
+
+		err := tx.Where("expires_at < ?", now).Delete(&postgresStorageORM.RefreshTokenPG{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("expires_at < ?", now).Delete(&postgresStorageORM.SessionPG{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&postgresStorageORM.RefreshTokenPG{
+			FamilyID:        token.FamilyID,
+			UserID:          token.UserID,
+			TokenHash:       tokenHash,
+			AccessTokenID:   token.AccessTokenID,
+			AccessExpiresAt: token.AccessExpiresAt,
+			ExpiresAt:       token.ExpiresAt,
+		}).Error
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// UseRefreshToken implements secretsJwt.IRefreshTokenStorage.
+// Marks token as used and returns it. Returns storage.ErrNotFound for unknown, revoked and expired tokens.
+// Returns storage.ErrTokenReused and revokes session of the token when token was already used
+func (d *PostgresDataProvider) UseRefreshToken(ctx context.Context, tokenHash []byte) (*storageDTO.RefreshToken, error) {
+	var result *storageDTO.RefreshToken
+	reused := false
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var token postgresStorageORM.RefreshTokenPG
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Take(&token, "token_hash = ?", tokenHash).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrNotFound
+			}
+			return err
+		}
+
+		if token.RevokedAt != nil || token.ExpiresAt.Before(now) {
+			return storage.ErrNotFound
+		}
+
+		if token.UsedAt != nil {
+			reused = true
+			return revokeSessions(tx, []uint64{token.FamilyID}, now)
+		}
+
+		err = tx.Model(&postgresStorageORM.RefreshTokenPG{}).
+			Where("id = ?", token.ID).
+			UpdateColumn("used_at", now).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var username string
+		err = tx.Model(&postgresStorageORM.UserPG{}).
+			Select("username").
+			Where("id = ?", token.UserID).
+			Take(&username).
+			Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrNotFound
+			}
+			return err
+		}
+
+		result = &storageDTO.RefreshToken{
+			FamilyID:        token.FamilyID,
+			UserID:          token.UserID,
+			Username:        username,
+			AccessTokenID:   token.AccessTokenID,
+			AccessExpiresAt: token.AccessExpiresAt,
+			ExpiresAt:       token.ExpiresAt,
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	if reused {
+		return nil, storage.ErrTokenReused
+	}
+
+	return result, nil
+}
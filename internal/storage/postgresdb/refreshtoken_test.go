@@ -0,0 +1,88 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+var refreshTokenColumns = []string{
+	"id", "family_id", "user_id", "token_hash", "access_token_id", "access_expires_at", "expires_at", "used_at", "revoked_at",
+}
+
+func TestPostgresDataProvider_UseRefreshToken_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	tokenHash := []byte("hash")
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT \* FROM "refreshTokens" WHERE token_hash = \$1 LIMIT \$2 FOR UPDATE$`).
+		WithArgs(tokenHash, 1).
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(3, 2, 1, tokenHash, 5, expiresAt, expiresAt, nil, nil))
+	mock.ExpectExec(`^UPDATE "refreshTokens" SET "used_at"=\$1 WHERE id = \$2$`).
+		WithArgs(sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT "username" FROM "users" WHERE id = \$1 LIMIT \$2$`).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("test_user"))
+	mock.ExpectCommit()
+
+	token, err := storageService.UseRefreshToken(ctx, tokenHash)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), token.FamilyID)
+	require.Equal(t, uint64(1), token.UserID)
+	require.Equal(t, "test_user", token.Username)
+}
+
+func TestPostgresDataProvider_UseRefreshToken_Error_Reused(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	tokenHash := []byte("hash")
+	expiresAt := time.Now().Add(time.Hour)
+	usedAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT \* FROM "refreshTokens" WHERE token_hash = \$1 LIMIT \$2 FOR UPDATE$`).
+		WithArgs(tokenHash, 1).
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(3, 2, 1, tokenHash, 5, expiresAt, expiresAt, usedAt, nil))
+	expectRevokeSessions(mock, 2)
+	mock.ExpectCommit()
+
+	token, err := storageService.UseRefreshToken(ctx, tokenHash)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrTokenReused)
+	require.Nil(t, token)
+}
+
+func TestPostgresDataProvider_UseRefreshToken_Error_Revoked(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	tokenHash := []byte("hash")
+	expiresAt := time.Now().Add(time.Hour)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WithArgs(tokenHash, 1).
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(3, 2, 1, tokenHash, 5, expiresAt, expiresAt, revokedAt, revokedAt))
+	mock.ExpectRollback()
+
+	_, err := storageService.UseRefreshToken(ctx, tokenHash)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
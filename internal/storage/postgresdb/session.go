@@ -0,0 +1,177 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pendingSessionMaxAge lifetime of session until its first refresh token is created
+const pendingSessionMaxAge = time.Minute
+
+// sessionFromPG converts ORM model to storage DTO
+func sessionFromPG(session postgresStorageORM.SessionPG) storageDTO.Session {
+	return storageDTO.Session{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+// CreateSession implements authService.ISessionCreator.
+// Session expires shortly unless refresh token is issued for it
+func (d *PostgresDataProvider) CreateSession(ctx context.Context, session storageDTO.Session) (uint64, error) {
+	now := time.Now()
+	newSession := postgresStorageORM.SessionPG{
+		UserID:     session.UserID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(pendingSessionMaxAge),
+	}
+
+	err := d.db.WithContext(ctx).Create(&newSession).Error
+	if err != nil {
+		return 0, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return newSession.ID, nil
+}
+
+// GetSessions implements authService.ISessionGetter.
+// Returns active sessions of user, recently seen first
+func (d *PostgresDataProvider) GetSessions(ctx context.Context, userID uint64) ([]storageDTO.Session, error) {
+	var sessions []postgresStorageORM.SessionPG
+	err := d.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC, id DESC").
+		Find(&sessions).
+		Error
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	result := make([]storageDTO.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, sessionFromPG(session))
+	}
+
+	return result, nil
+}
+
+// TouchSession implements authService.ISessionUpdater.
+// Sets last seen time of active session unless it was seen within interval before seenAt
+func (d *PostgresDataProvider) TouchSession(
+	ctx context.Context,
+	sessionID uint64,
+	seenAt time.Time,
+	interval time.Duration,
+) error {
+	err := d.db.WithContext(ctx).Model(&postgresStorageORM.SessionPG{}).
+		Where("id = ? AND revoked_at IS NULL AND last_seen_at < ?", sessionID, seenAt.Add(-interval)).
+		UpdateColumn("last_seen_at", seenAt).
+		Error
+	if err != nil {
+		return errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	return nil
+}
+
+// RevokeSession implements secretsJwt.IJWTRevoker.
+// Returns storage.ErrNotFound if user has no such active session
+func (d *PostgresDataProvider) RevokeSession(ctx context.Context, userID uint64, sessionID uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var sessionIDs []uint64
+		err := tx.Model(&postgresStorageORM.SessionPG{}).
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, now).
+			Pluck("id", &sessionIDs).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if len(sessionIDs) == 0 {
+			return storage.ErrNotFound
+		}
+
+		return revokeSessions(tx, sessionIDs, now)
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions implements secretsJwt.IJWTRevoker.
+// Revokes every active session of user except sessionID and returns count of revoked sessions
+func (d *PostgresDataProvider) RevokeOtherSessions(ctx context.Context, userID uint64, sessionID uint64) (int64, error) {
+	var sessionIDs []uint64
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Model(&postgresStorageORM.SessionPG{}).
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL AND expires_at > ?", userID, sessionID, now).
+			Pluck("id", &sessionIDs).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if len(sessionIDs) == 0 {
+			return nil
+		}
+
+		return revokeSessions(tx, sessionIDs, now)
+	})
+
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	return int64(len(sessionIDs)), nil
+}
+
+// revokeSessions revokes sessions with their refresh tokens and access tokens issued for them
+func revokeSessions(tx *gorm.DB, sessionIDs []uint64, now time.Time) error {
+	err := tx.Model(&postgresStorageORM.SessionPG{}).
+		Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+		UpdateColumn("revoked_at", now).
+		Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&postgresStorageORM.RefreshTokenPG{}).
+		Where("family_id IN ? AND revoked_at IS NULL", sessionIDs).
+		UpdateColumn("revoked_at", now).
+		Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(
+		`INSERT INTO "revokedTokens" (token_id, expires_at) `+
+			`SELECT access_token_id, access_expires_at FROM "refreshTokens" `+
+			`WHERE family_id IN ? AND access_expires_at > ? ON CONFLICT DO NOTHING`,
+		sessionIDs, now,
+	).Error
+}
@@ -0,0 +1,125 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+// expectRevokeSessions expects revocation of single session with its tokens
+func expectRevokeSessions(mock sqlmock.Sqlmock, sessionID uint64) {
+	mock.ExpectExec(`^UPDATE "sessions" SET "revoked_at"=\$1 WHERE id IN \(\$2\) AND revoked_at IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE "refreshTokens" SET "revoked_at"=\$1 WHERE family_id IN \(\$2\) AND revoked_at IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^INSERT INTO "revokedTokens" \(token_id, expires_at\) SELECT access_token_id, access_expires_at `+
+		`FROM "refreshTokens" WHERE family_id IN \(\$1\) AND access_expires_at > \$2 ON CONFLICT DO NOTHING$`).
+		WithArgs(sessionID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestPostgresDataProvider_CreateRefreshToken_Error_SessionRevoked(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	sessionID := uint64(2)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "sessions" SET "expires_at"=\$1,"last_seen_at"=\$2 `+
+		`WHERE id = \$3 AND revoked_at IS NULL AND expires_at > \$4$`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sessionID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := storageService.CreateRefreshToken(
+		ctx,
+		storageDTO.RefreshToken{FamilyID: sessionID, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)},
+		[]byte("hash"),
+	)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresDataProvider_TouchSession_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	sessionID := uint64(2)
+	seenAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "sessions" SET "last_seen_at"=\$1 WHERE id = \$2 AND revoked_at IS NULL AND last_seen_at < \$3$`).
+		WithArgs(seenAt, sessionID, seenAt.Add(-time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.TouchSession(ctx, sessionID, seenAt, time.Minute)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_RevokeSession_Error_NotFound(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id" FROM "sessions" WHERE id = \$1 AND user_id = \$2 `+
+		`AND revoked_at IS NULL AND expires_at > \$3 FOR UPDATE$`).
+		WithArgs(5, 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err := storageService.RevokeSession(ctx, 1, 5)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresDataProvider_RevokeOtherSessions_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id" FROM "sessions" WHERE user_id = \$1 AND id <> \$2 `+
+		`AND revoked_at IS NULL AND expires_at > \$3 FOR UPDATE$`).
+		WithArgs(1, 5, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	expectRevokeSessions(mock, 6)
+	mock.ExpectCommit()
+
+	count, err := storageService.RevokeOtherSessions(ctx, 1, 5)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestPostgresDataProvider_GetSessions_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	now := time.Now()
+
+	mock.ExpectQuery(`^SELECT \* FROM "sessions" WHERE user_id = \$1 AND revoked_at IS NULL AND expires_at > \$2 `+
+		`ORDER BY last_seen_at DESC, id DESC$`).
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip", "created_at", "last_seen_at", "expires_at"}).
+			AddRow(5, 1, "grpc-go/1.0", "10.0.0.1", now, now, now.Add(time.Hour)))
+
+	sessions, err := storageService.GetSessions(ctx, 1)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "grpc-go/1.0", sessions[0].UserAgent)
+	require.Equal(t, "10.0.0.1", sessions[0].IP)
+}
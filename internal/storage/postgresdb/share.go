@@ -0,0 +1,266 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sharedWith selects ids of resources of resourceType shared with user
+func sharedWith(tx *gorm.DB, resourceType storageDTO.ShareResourceType, userID uint64) *gorm.DB {
+	return tx.Model(&postgresStorageORM.ShareMemberPG{}).
+		Select("resource_id").
+		Where("resource_type = ? AND user_id = ?", int8(resourceType), userID)
+}
+
+// resourceConditions matches share members of any of resources
+func resourceConditions(tx *gorm.DB, resources []storageDTO.ShareResource) *gorm.DB {
+	conditions := tx.Session(&gorm.Session{NewDB: true})
+	for i, resource := range resources {
+		condition := "resource_type = ? AND resource_id = ?"
+		if i == 0 {
+			conditions = conditions.Where(condition, int8(resource.Type), resource.ID)
+		} else {
+			conditions = conditions.Or(condition, int8(resource.Type), resource.ID)
+		}
+	}
+	return conditions
+}
+
+// memberRole returns the highest role of user on any of resources
+func memberRole(tx *gorm.DB, userID uint64, resources ...storageDTO.ShareResource) (storageDTO.ShareRole, error) {
+	var role int8
+	result := tx.Model(&postgresStorageORM.ShareMemberPG{}).
+		Select("COALESCE(MAX(role), 0)").
+		Where("user_id = ?", userID).
+		Where(resourceConditions(tx, resources)).
+		Scan(&role)
+
+	if result.Error != nil {
+		return storageDTO.ShareRoleNone, result.Error
+	}
+
+	return storageDTO.ShareRole(role), nil
+}
+
+// itemResources returns resources sharing of which grants access to item:
+// the item, its ancestors and project of the top level ancestor
+func itemResources(tx *gorm.DB, item postgresStorageORM.ToDoItemPG) ([]storageDTO.ShareResource, error) {
+	resources := []storageDTO.ShareResource{{Type: storageDTO.ShareResourceTask, ID: item.ID}}
+
+	for item.ParentID != nil {
+		parentID := *item.ParentID
+		item = postgresStorageORM.ToDoItemPG{}
+		err := tx.Select("id", "parent_id", "project_id").Take(&item, "id = ?", parentID).Error
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, storageDTO.ShareResource{Type: storageDTO.ShareResourceTask, ID: item.ID})
+	}
+
+	if item.ProjectID != nil {
+		resources = append(resources, storageDTO.ShareResource{Type: storageDTO.ShareResourceProject, ID: *item.ProjectID})
+	}
+
+	return resources, nil
+}
+
+// StorageToDoItemAccess implements todoService.IToDoItemAccessResolver.
+// Roles on ancestors and project of item apply to the item too. Returns storage.ErrNotFound if item does not exist
+func (d *PostgresDataProvider) StorageToDoItemAccess(ctx context.Context, itemID uint64, userID uint64) (*storageDTO.Access, error) {
+	var item postgresStorageORM.ToDoItemPG
+	db := d.db.WithContext(ctx)
+	result := db.Select("id", "owner_id", "project_id", "parent_id").Take(&item, "id = ?", itemID)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	access := storageDTO.Access{OwnerID: item.OwnerID, Role: storageDTO.ShareRoleOwner}
+	if item.OwnerID == userID {
+		return &access, nil
+	}
+
+	resources, err := itemResources(db, item)
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	role, err := memberRole(db, userID, resources...)
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	access.Role = role
+	return &access, nil
+}
+
+// StorageProjectAccess implements todoService.IToDoItemAccessResolver.
+// Returns storage.ErrNotFound if project does not exist
+func (d *PostgresDataProvider) StorageProjectAccess(ctx context.Context, projectID uint64, userID uint64) (*storageDTO.Access, error) {
+	var project postgresStorageORM.ProjectPG
+	db := d.db.WithContext(ctx)
+	result := db.Select("id", "owner_id").Take(&project, "id = ?", projectID)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	access := storageDTO.Access{OwnerID: project.OwnerID, Role: storageDTO.ShareRoleOwner}
+	if project.OwnerID == userID {
+		return &access, nil
+	}
+
+	role, err := memberRole(db, userID, storageDTO.ShareResource{Type: storageDTO.ShareResourceProject, ID: projectID})
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	access.Role = role
+	return &access, nil
+}
+
+// StorageToDoItemMemberIDs implements todoService.IToDoItemAccessResolver.
+// Returns users the item is shared with directly, by ancestor or by project
+func (d *PostgresDataProvider) StorageToDoItemMemberIDs(ctx context.Context, itemID uint64) ([]uint64, error) {
+	var item postgresStorageORM.ToDoItemPG
+	db := d.db.WithContext(ctx)
+	result := db.Select("id", "parent_id", "project_id").Take(&item, "id = ?", itemID)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	resources, err := itemResources(db, item)
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	var userIDs []uint64
+	result = db.Model(&postgresStorageORM.ShareMemberPG{}).
+		Distinct("user_id").
+		Where(resourceConditions(db, resources)).
+		Pluck("user_id", &userIDs)
+
+	if result.Error != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	return userIDs, nil
+}
+
+// StorageShareMemberCreate implements shareService.IShareMemberCreator.
+// Returns storage.ErrAlreadyExists if user is already a member
+func (d *PostgresDataProvider) StorageShareMemberCreate(ctx context.Context, member storageDTO.ShareMember) error {
+	result := d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&postgresStorageORM.ShareMemberPG{
+			ResourceType: int8(member.Resource.Type),
+			ResourceID:   member.Resource.ID,
+			UserID:       member.UserID,
+			Role:         int8(member.Role),
+		})
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return storage.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// StorageShareMemberGetList implements shareService.IShareMemberGetter.
+func (d *PostgresDataProvider) StorageShareMemberGetList(
+	ctx context.Context,
+	resource storageDTO.ShareResource,
+) ([]storageDTO.ShareMember, error) {
+	var rows []struct {
+		postgresStorageORM.ShareMemberPG
+		Username string
+	}
+
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.ShareMemberPG{}).
+		Select(`"shareMembers".*, "users".username`).
+		Joins(`JOIN "users" ON "users".id = "shareMembers".user_id`).
+		Where(`"shareMembers".resource_type = ? AND "shareMembers".resource_id = ?`, int8(resource.Type), resource.ID).
+		Order(`"shareMembers".user_id ASC`).
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	members := make([]storageDTO.ShareMember, 0, len(rows))
+	for _, row := range rows {
+		username := row.Username
+		members = append(members, storageDTO.ShareMember{
+			Resource:  resource,
+			UserID:    row.UserID,
+			Username:  &username,
+			Role:      storageDTO.ShareRole(row.Role),
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	return members, nil
+}
+
+// StorageShareMemberUpdate implements shareService.IShareMemberUpdater.
+func (d *PostgresDataProvider) StorageShareMemberUpdate(ctx context.Context, member storageDTO.ShareMember) error {
+	result := d.db.WithContext(ctx).
+		Model(&postgresStorageORM.ShareMemberPG{}).
+		Where(
+			"resource_type = ? AND resource_id = ? AND user_id = ?",
+			int8(member.Resource.Type), member.Resource.ID, member.UserID,
+		).
+		Update("role", int8(member.Role))
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
+
+// StorageShareMemberDelete implements shareService.IShareMemberDeleter.
+func (d *PostgresDataProvider) StorageShareMemberDelete(
+	ctx context.Context,
+	resource storageDTO.ShareResource,
+	userID uint64,
+) error {
+	result := d.db.WithContext(ctx).
+		Where("resource_type = ? AND resource_id = ? AND user_id = ?", int8(resource.Type), resource.ID, userID).
+		Delete(&postgresStorageORM.ShareMemberPG{})
+
+	if result.Error != nil {
+		return errors.Join(storage.ErrDatabaseError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_StorageToDoItemAccess_Owner(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	mock.ExpectQuery(`^SELECT "id","owner_id","project_id","parent_id" FROM "todoItems" WHERE id = \$1 AND "todoItems"."deleted_at" IS NULL LIMIT \$2$`).
+		WithArgs(itemID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "parent_id"}).AddRow(itemID, ownerID, nil, nil))
+
+	access, err := storageService.StorageToDoItemAccess(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, &storageDTO.Access{OwnerID: ownerID, Role: storageDTO.ShareRoleOwner}, access)
+}
+
+func TestPostgresDataProvider_StorageToDoItemAccess_ProjectMember(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	projectID := uint64(5)
+	ownerID := uint64(1)
+	memberID := uint64(2)
+
+	mock.ExpectQuery(`^SELECT "id","owner_id","project_id","parent_id" FROM "todoItems" WHERE id = \$1 AND "todoItems"."deleted_at" IS NULL LIMIT \$2$`).
+		WithArgs(itemID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "parent_id"}).AddRow(itemID, ownerID, projectID, nil))
+	mock.ExpectQuery(
+		`^SELECT COALESCE\(MAX\(role\), 0\) FROM "shareMembers" WHERE user_id = \$1 AND `+
+			`\(\(resource_type = \$2 AND resource_id = \$3\) OR \(resource_type = \$4 AND resource_id = \$5\)\)$`).
+		WithArgs(
+			memberID,
+			int8(storageDTO.ShareResourceTask), itemID,
+			int8(storageDTO.ShareResourceProject), projectID,
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int8(storageDTO.ShareRoleEditor)))
+
+	access, err := storageService.StorageToDoItemAccess(ctx, itemID, memberID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, &storageDTO.Access{OwnerID: ownerID, Role: storageDTO.ShareRoleEditor}, access)
+}
+
+func TestPostgresDataProvider_StorageToDoItemAccess_Error_NotFound(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	mock.ExpectQuery(`^SELECT "id","owner_id","project_id","parent_id" FROM "todoItems"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id"}))
+
+	_, err := storageService.StorageToDoItemAccess(ctx, 10, 2)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresDataProvider_StorageShareMemberCreate_Error_AlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	member := storageDTO.ShareMember{
+		Resource: storageDTO.ShareResource{Type: storageDTO.ShareResourceProject, ID: 5},
+		UserID:   2,
+		Role:     storageDTO.ShareRoleViewer,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO "shareMembers" (.+) ON CONFLICT DO NOTHING RETURNING "created_at"$`).
+		WithArgs(int8(member.Resource.Type), member.Resource.ID, member.UserID, int8(member.Role)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}))
+	mock.ExpectCommit()
+
+	err := storageService.StorageShareMemberCreate(ctx, member)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrAlreadyExists)
+}
+
+func TestPostgresDataProvider_StorageToDoItemGetList_IncludeShared(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	userID := uint64(2)
+
+	mock.ExpectQuery(
+		`^SELECT \* FROM "todoItems" WHERE \(\(owner_id = \$1 OR id IN `+
+			`\(SELECT "resource_id" FROM "shareMembers" WHERE resource_type = \$2 AND user_id = \$3\) OR project_id IN `+
+			`\(SELECT "resource_id" FROM "shareMembers" WHERE resource_type = \$4 AND user_id = \$5\)\)\) `+
+			`AND parent_id IS NULL AND "todoItems"."deleted_at" IS NULL ORDER BY id ASC LIMIT \$6$`).
+		WithArgs(
+			userID,
+			int8(storageDTO.ShareResourceTask), userID,
+			int8(storageDTO.ShareResourceProject), userID,
+			11,
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "title"}).AddRow(10, 1, "shared"))
+	mock.ExpectQuery(`^SELECT parent_id, COUNT\(\*\) AS total_count`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "total_count", "done_count"}))
+
+	items, next, err := storageService.StorageToDoItemGetList(ctx, userID, storageDTO.ToDoItemListQuery{
+		IncludeShared: true,
+		Limit:         10,
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Nil(t, next)
+	require.Len(t, items, 1)
+	require.Equal(t, uint64(1), items[0].OwnerId)
+}
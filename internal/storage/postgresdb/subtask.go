@@ -0,0 +1,312 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// descendantsOf selects ids of all descendants of items, including items in trash.
+// parents is item id, slice of ids or subquery selecting ids
+func descendantsOf(tx *gorm.DB, parents interface{}) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).Raw(
+		`WITH RECURSIVE subtree AS (`+
+			`SELECT id FROM "todoItems" WHERE parent_id IN (?) `+
+			`UNION ALL SELECT child.id FROM "todoItems" child JOIN subtree ON child.parent_id = subtree.id`+
+			`) SELECT id FROM subtree`,
+		parents,
+	)
+}
+
+// lockItem locks owner item until the end of transaction.
+// Returns gorm.ErrRecordNotFound if owner has no such item
+func lockItem(tx *gorm.DB, itemID uint64, ownerID uint64, strength string) (*postgresStorageORM.ToDoItemPG, error) {
+	var item postgresStorageORM.ToDoItemPG
+	err := tx.Clauses(clause.Locking{Strength: strength}).
+		Select("id", "parent_id", "project_id", "depth").
+		Take(&item, "id = ? AND owner_id = ?", itemID, ownerID).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// lockParent keeps parent item from being deleted until the end of transaction.
+// Returns storage.ErrReferenceNotFound if owner has no such item
+func lockParent(tx *gorm.DB, parentID uint64, ownerID uint64) (*postgresStorageORM.ToDoItemPG, error) {
+	parent, err := lockItem(tx, parentID, ownerID, clause.LockingStrengthShare)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrReferenceNotFound
+	}
+	return parent, err
+}
+
+// childCounts counts children of item
+type childCounts struct {
+	ParentID   uint64
+	TotalCount int64
+	DoneCount  int64
+}
+
+// countChildren returns child counts of items having children
+func countChildren(tx *gorm.DB, itemIDs ...uint64) ([]childCounts, error) {
+	var counts []childCounts
+	err := tx.Model(&postgresStorageORM.ToDoItemPG{}).
+		Select("parent_id, COUNT(*) AS total_count, COUNT(*) FILTER (WHERE is_complete) AS done_count").
+		Where("parent_id IN ?", itemIDs).
+		Group("parent_id").
+		Scan(&counts).
+		Error
+	return counts, err
+}
+
+// fillChildCounts sets done and total child counts of items
+func fillChildCounts(tx *gorm.DB, items []storageDTO.ToDoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemIDs := make([]uint64, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.Id)
+	}
+
+	counts, err := countChildren(tx, itemIDs...)
+	if err != nil {
+		return err
+	}
+
+	byParent := make(map[uint64]childCounts, len(counts))
+	for _, count := range counts {
+		byParent[count.ParentID] = count
+	}
+
+	for i := range items {
+		count := byParent[items[i].Id]
+		items[i].DoneCount = count.DoneCount
+		items[i].TotalCount = count.TotalCount
+	}
+
+	return nil
+}
+
+// refreshAncestors completes auto complete items starting from parentID when all their children are done
+// and reopens them otherwise. Stops at the first ancestor whose state is unchanged
+func refreshAncestors(tx *gorm.DB, parentID *uint64) error {
+	for parentID != nil {
+		var parent postgresStorageORM.ToDoItemPG
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Select("id", "parent_id", "is_complete", "auto_complete").
+			Take(&parent, "id = ?", *parentID).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if !parent.AutoComplete {
+			return nil
+		}
+
+		counts, err := countChildren(tx, parent.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(counts) == 0 {
+			return nil
+		}
+
+		isComplete := counts[0].DoneCount == counts[0].TotalCount
+		if isComplete == parent.IsComplete {
+			return nil
+		}
+
+		updatedFields := map[string]interface{}{
+			"is_complete":  isComplete,
+			"completed_at": nil,
+			"version":      gorm.Expr("version + 1"),
+		}
+		if isComplete {
+			updatedFields["completed_at"] = time.Now()
+		}
+
+		err = tx.Model(&postgresStorageORM.ToDoItemPG{}).Where("id = ?", parent.ID).Updates(updatedFields).Error
+		if err != nil {
+			return err
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// StorageToDoItemReorder implements todoService.IToDoItemUpdater.
+// childIDs must list every child of parent exactly once.
+// Returns storage.ErrInvalidOrder otherwise
+func (d *PostgresDataProvider) StorageToDoItemReorder(
+	ctx context.Context,
+	parentID uint64,
+	ownerID uint64,
+	childIDs []uint64,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := lockItem(tx, parentID, ownerID, clause.LockingStrengthUpdate)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return d.itemAccessError(tx, parentID)
+			}
+			return err
+		}
+
+		var currentIDs []uint64
+		err = tx.Model(&postgresStorageORM.ToDoItemPG{}).
+			Where("parent_id = ?", parentID).
+			Pluck("id", &currentIDs).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if len(currentIDs) != len(childIDs) {
+			return storage.ErrInvalidOrder
+		}
+
+		if len(childIDs) == 0 {
+			return nil
+		}
+
+		current := make(map[uint64]bool, len(currentIDs))
+		for _, id := range currentIDs {
+			current[id] = true
+		}
+
+		var positions strings.Builder
+		args := make([]interface{}, 0, len(childIDs)*2)
+		positions.WriteString("CASE id")
+		for i, id := range childIDs {
+			if !current[id] {
+				return storage.ErrInvalidOrder
+			}
+			delete(current, id)
+
+			positions.WriteString(" WHEN ? THEN ?")
+			args = append(args, id, i+1)
+		}
+		positions.WriteString(" END")
+
+		return tx.Model(&postgresStorageORM.ToDoItemPG{}).
+			Where("parent_id = ?", parentID).
+			UpdateColumns(map[string]interface{}{
+				"position": gorm.Expr(positions.String(), args...),
+				"version":  gorm.Expr("version + 1"),
+			}).
+			Error
+	})
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// StorageToDoItem_DeleteById implements todoService.IToDoItemDeleter.
+// Item is moved to trash, children are moved to trash with item or moved to its parent depending on mode.
+// Share members are kept until items are purged
+func (d *PostgresDataProvider) StorageToDoItemDeleteByID(
+	ctx context.Context,
+	itemID uint64,
+	ownerID uint64,
+	mode storageDTO.SubtaskDeleteMode,
+) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return d.deleteItem(tx, itemID, ownerID, mode)
+	})
+
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// deleteItem moves item of owner to trash within transaction tx.
+// Children moved to trash with item share its deleted_at to be restored together
+func (d *PostgresDataProvider) deleteItem(
+	tx *gorm.DB,
+	itemID uint64,
+	ownerID uint64,
+	mode storageDTO.SubtaskDeleteMode,
+) error {
+	item, err := lockItem(tx, itemID, ownerID, clause.LockingStrengthUpdate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return d.itemAccessError(tx, itemID)
+		}
+		return err
+	}
+
+	deleted := tx.Model(&postgresStorageORM.ToDoItemPG{})
+	switch mode {
+	case storageDTO.SubtaskDeleteCascade:
+		deleted = deleted.Where("id = ? OR id IN (?)", itemID, descendantsOf(tx, itemID))
+	default:
+		err = d.reparentChildren(tx, item)
+		if err != nil {
+			return err
+		}
+		deleted = deleted.Where("id = ?", itemID)
+	}
+
+	err = deleted.UpdateColumn("deleted_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	return refreshAncestors(tx, item.ParentID)
+}
+
+// reparentChildren moves children of item to its parent after the parent's own children.
+// Children moved to the top level take the project of item. Children in trash are moved too
+func (d *PostgresDataProvider) reparentChildren(tx *gorm.DB, item *postgresStorageORM.ToDoItemPG) error {
+	err := tx.Unscoped().Model(&postgresStorageORM.ToDoItemPG{}).
+		Where("id IN (?)", descendantsOf(tx, item.ID)).
+		UpdateColumn("depth", gorm.Expr("depth - 1")).
+		Error
+	if err != nil {
+		return err
+	}
+
+	updatedFields := map[string]interface{}{
+		"parent_id": item.ParentID,
+		"version":   gorm.Expr("version + 1"),
+	}
+
+	if item.ParentID == nil {
+		updatedFields["project_id"] = item.ProjectID
+	} else {
+		var lastPosition int64
+		err = tx.Model(&postgresStorageORM.ToDoItemPG{}).
+			Select("COALESCE(MAX(position), 0)").
+			Where("parent_id = ?", *item.ParentID).
+			Scan(&lastPosition).
+			Error
+		if err != nil {
+			return err
+		}
+		updatedFields["position"] = gorm.Expr("position + ?", lastPosition)
+	}
+
+	return tx.Unscoped().Model(&postgresStorageORM.ToDoItemPG{}).
+		Where("parent_id = ?", item.ID).
+		Updates(updatedFields).
+		Error
+}
@@ -0,0 +1,155 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_StorageToDoItemCreate_Subtask(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	parentID := uint64(7)
+	ownerID := uint64(1)
+	title := "subtask"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(
+		`^SELECT "id","parent_id","project_id","depth" FROM "todoItems" WHERE \(id = \$1 AND owner_id = \$2\) AND "todoItems"."deleted_at" IS NULL LIMIT \$3 FOR SHARE$`).
+		WithArgs(parentID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(parentID, nil, nil, 0))
+	mock.ExpectQuery(`^SELECT COALESCE\(MAX\(position\), 0\) \+ 1 FROM "todoItems" WHERE parent_id = \$1 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+	mock.ExpectQuery(`^INSERT INTO "todoItems" (.+) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(
+		`^SELECT "id","parent_id","is_complete","auto_complete" FROM "todoItems" WHERE id = \$1 AND "todoItems"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE$`).
+		WithArgs(parentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "is_complete", "auto_complete"}).
+			AddRow(parentID, nil, false, false))
+	mock.ExpectCommit()
+
+	id, err := storageService.StorageToDoItemCreate(ctx, storageDTO.ToDoItem{Title: &title, ParentID: &parentID}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), id)
+}
+
+func TestPostgresDataProvider_StorageToDoItemUpdate_AutoCompletesParent(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(11)
+	parentID := uint64(7)
+	ownerID := uint64(1)
+	isComplete := true
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE "todoItems" SET "completed_at"=COALESCE\(completed_at, \$1\),"is_complete"=\$2`).
+		WithArgs(sqlmock.AnyArg(), isComplete, sqlmock.AnyArg(), itemID, ownerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT "parent_id" FROM "todoItems" WHERE id = \$1 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(parentID))
+	mock.ExpectQuery(`^SELECT "id","parent_id","is_complete","auto_complete" FROM "todoItems"`).
+		WithArgs(parentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "is_complete", "auto_complete"}).
+			AddRow(parentID, nil, false, true))
+	mock.ExpectQuery(`^SELECT parent_id, COUNT\(\*\) AS total_count`).
+		WithArgs(parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"parent_id", "total_count", "done_count"}).AddRow(parentID, 2, 2))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "completed_at"=\$1,"is_complete"=\$2,"version"=version \+ 1,"updated_at"=\$3 WHERE id = \$4 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), true, sqlmock.AnyArg(), parentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemUpdate(ctx, storageDTO.ToDoItem{Id: itemID, IsComplete: &isComplete}, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemReorder_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	parentID := uint64(7)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WithArgs(parentID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(parentID, nil, nil, 0))
+	mock.ExpectQuery(`^SELECT "id" FROM "todoItems" WHERE parent_id = \$1 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "position"=CASE id WHEN \$1 THEN \$2 WHEN \$3 THEN \$4 END,"version"=version \+ 1 `+
+			`WHERE parent_id = \$5 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(12, 1, 11, 2, parentID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemReorder(ctx, parentID, ownerID, []uint64{12, 11})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemReorder_Error_InvalidOrder(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	parentID := uint64(7)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WithArgs(parentID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(parentID, nil, nil, 0))
+	mock.ExpectQuery(`^SELECT "id" FROM "todoItems" WHERE parent_id = \$1 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+	mock.ExpectRollback()
+
+	err := storageService.StorageToDoItemReorder(ctx, parentID, ownerID, []uint64{11, 11})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrInvalidOrder)
+}
+
+func TestPostgresDataProvider_StorageToDoItemDeleteByID_Cascade(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(11)
+	parentID := uint64(7)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE$`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(itemID, parentID, nil, 1))
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "deleted_at"=\$1 WHERE \(id = \$2 OR id IN \(WITH RECURSIVE subtree AS `+
+			`\(SELECT id FROM "todoItems" WHERE parent_id IN \(\$3\) UNION ALL .+\) SELECT id FROM subtree\)\) AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), itemID, itemID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`^SELECT "id","parent_id","is_complete","auto_complete" FROM "todoItems"`).
+		WithArgs(parentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "is_complete", "auto_complete"}).
+			AddRow(parentID, nil, false, false))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemDeleteByID(ctx, itemID, ownerID, storageDTO.SubtaskDeleteCascade)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
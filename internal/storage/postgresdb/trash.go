@@ -0,0 +1,180 @@
+package postgresdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	postgresStorageORM "github.com/IldarGaleev/todo-backend-service/internal/storage/postgresdb/postgresstorageorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockDeletedItem locks owner item in trash until the end of transaction.
+// Returns storage.ErrAccessDenied if item in trash belongs to another owner and storage.ErrNotFound if it is not in trash
+func (d *PostgresDataProvider) lockDeletedItem(tx *gorm.DB, itemID uint64, ownerID uint64) (*postgresStorageORM.ToDoItemPG, error) {
+	var item postgresStorageORM.ToDoItemPG
+	err := tx.Unscoped().
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Select("id", "parent_id", "project_id", "deleted_at").
+		Take(&item, "id = ? AND owner_id = ? AND deleted_at IS NOT NULL", itemID, ownerID).
+		Error
+	if err == nil {
+		return &item, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var count int64
+	err = tx.Unscoped().Model(&postgresStorageORM.ToDoItemPG{}).
+		Where("id = ? AND deleted_at IS NOT NULL", itemID).
+		Count(&count).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	if count > 0 {
+		return nil, storage.ErrAccessDenied
+	}
+
+	return nil, storage.ErrNotFound
+}
+
+// StorageToDoItemGetDeleted implements todoService.IToDoItemGetter.
+// Returns items of owner in trash except children moved to trash with their parent, recently deleted first
+func (d *PostgresDataProvider) StorageToDoItemGetDeleted(ctx context.Context, ownerID uint64) ([]storageDTO.ToDoItem, error) {
+	db := d.db.WithContext(ctx)
+
+	var items []postgresStorageORM.ToDoItemPG
+	err := db.Unscoped().
+		Where("owner_id = ? AND deleted_at IS NOT NULL", ownerID).
+		Where(
+			"parent_id IS NULL OR parent_id IN (?)",
+			db.Model(&postgresStorageORM.ToDoItemPG{}).Select("id"),
+		).
+		Order("deleted_at DESC, id DESC").
+		Find(&items).
+		Error
+	if err != nil {
+		return nil, errors.Join(storage.ErrDatabaseError, err)
+	}
+
+	result := make([]storageDTO.ToDoItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, toDoItemFromPG(item))
+	}
+
+	return result, nil
+}
+
+// StorageToDoItemRestore implements todoService.IToDoItemUpdater.
+// Children moved to trash with item are restored too. Item can not be restored while its parent is in trash,
+// storage.ErrReferenceNotFound is returned then. Item of project deleted meanwhile is restored without project
+func (d *PostgresDataProvider) StorageToDoItemRestore(ctx context.Context, itemID uint64, ownerID uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := d.lockDeletedItem(tx, itemID, ownerID)
+		if err != nil {
+			return err
+		}
+
+		if item.ParentID != nil {
+			_, err = lockParent(tx, *item.ParentID, ownerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Unscoped().Model(&postgresStorageORM.ToDoItemPG{}).
+			Where("id = ? OR id IN (?)", itemID, descendantsOf(tx, itemID)).
+			Where("deleted_at = ?", item.DeletedAt).
+			UpdateColumns(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if item.ProjectID != nil {
+			err = lockProject(tx, *item.ProjectID, ownerID, clause.LockingStrengthShare)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = tx.Model(&postgresStorageORM.ToDoItemPG{}).
+					Where("id = ?", itemID).
+					UpdateColumn("project_id", nil).
+					Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return refreshAncestors(tx, item.ParentID)
+	})
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// StorageToDoItemPurge implements todoService.IToDoItemDeleter.
+// Permanently deletes item in trash with its children and share members
+func (d *PostgresDataProvider) StorageToDoItemPurge(ctx context.Context, itemID uint64, ownerID uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := d.lockDeletedItem(tx, itemID, ownerID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where(
+			"resource_type = ? AND (resource_id = ? OR resource_id IN (?))",
+			int8(storageDTO.ShareResourceTask), itemID, descendantsOf(tx, itemID),
+		).Delete(&postgresStorageORM.ShareMemberPG{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().
+			Where("id = ? OR id IN (?)", itemID, descendantsOf(tx, itemID)).
+			Delete(&postgresStorageORM.ToDoItemPG{}).
+			Error
+	})
+	if err != nil {
+		return storageError(err)
+	}
+
+	return nil
+}
+
+// StorageToDoItemPurgeDeleted permanently deletes items moved to trash before deletedBefore
+// with their share members. Returns number of deleted items
+func (d *PostgresDataProvider) StorageToDoItemPurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&postgresStorageORM.ToDoItemPG{}).
+			Select("id").
+			Where("deleted_at < ?", deletedBefore)
+
+		err := tx.Where("resource_type = ? AND resource_id IN (?)", int8(storageDTO.ShareResourceTask), expired).
+			Delete(&postgresStorageORM.ShareMemberPG{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&postgresStorageORM.ToDoItemPG{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, storageError(err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,218 @@
+package postgresdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IldarGaleev/todo-backend-service/internal/storage"
+	storageDTO "github.com/IldarGaleev/todo-backend-service/internal/storage/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDataProvider_StorageToDoItemGetDeleted_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	ownerID := uint64(1)
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery(
+		`^SELECT \* FROM "todoItems" WHERE \(owner_id = \$1 AND deleted_at IS NOT NULL\) ` +
+			`AND \(parent_id IS NULL OR parent_id IN \(SELECT "id" FROM "todoItems" WHERE "todoItems"."deleted_at" IS NULL\)\) ` +
+			`ORDER BY deleted_at DESC, id DESC$`).
+		WithArgs(ownerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "title", "deleted_at"}).AddRow(10, ownerID, "task", deletedAt))
+
+	items, err := storageService.StorageToDoItemGetDeleted(ctx, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, uint64(10), items[0].Id)
+	require.Equal(t, deletedAt, *items[0].DeletedAt)
+}
+
+func TestPostgresDataProvider_StorageToDoItemRestore_Subtask(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(11)
+	parentID := uint64(7)
+	ownerID := uint64(1)
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(
+		`^SELECT "id","parent_id","project_id","deleted_at" FROM "todoItems" `+
+			`WHERE id = \$1 AND owner_id = \$2 AND deleted_at IS NOT NULL LIMIT \$3 FOR UPDATE$`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "deleted_at"}).AddRow(itemID, parentID, nil, deletedAt))
+	mock.ExpectQuery(`^SELECT "id","parent_id","project_id","depth" FROM "todoItems" .+ FOR SHARE$`).
+		WithArgs(parentID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}).AddRow(parentID, nil, nil, 0))
+	mock.ExpectExec(
+		`^UPDATE "todoItems" SET "deleted_at"=\$1,"version"=version \+ 1 WHERE \(id = \$2 OR id IN \(WITH RECURSIVE subtree AS .+\)\) `+
+			`AND deleted_at = \$4$`).
+		WithArgs(nil, itemID, itemID, deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`^SELECT "id","parent_id","is_complete","auto_complete" FROM "todoItems"`).
+		WithArgs(parentID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "is_complete", "auto_complete"}).
+			AddRow(parentID, nil, false, false))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemRestore(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemRestore_Error_ParentDeleted(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(11)
+	parentID := uint64(7)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id","parent_id","project_id","deleted_at" FROM "todoItems"`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "deleted_at"}).AddRow(itemID, parentID, nil, time.Now()))
+	mock.ExpectQuery(`^SELECT "id","parent_id","project_id","depth" FROM "todoItems" .+ FOR SHARE$`).
+		WithArgs(parentID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "depth"}))
+	mock.ExpectRollback()
+
+	err := storageService.StorageToDoItemRestore(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.ErrorIs(t, err, storage.ErrReferenceNotFound)
+}
+
+func TestPostgresDataProvider_StorageToDoItemRestore_ProjectDeleted(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(11)
+	projectID := uint64(3)
+	ownerID := uint64(1)
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id","parent_id","project_id","deleted_at" FROM "todoItems"`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "deleted_at"}).
+			AddRow(itemID, nil, projectID, deletedAt))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "deleted_at"=\$1,"version"=version \+ 1 WHERE .+ AND deleted_at = \$4$`).
+		WithArgs(nil, itemID, itemID, deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`^SELECT "id" FROM "projects" WHERE id = \$1 AND owner_id = \$2 LIMIT \$3 FOR SHARE$`).
+		WithArgs(projectID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`^UPDATE "todoItems" SET "project_id"=\$1 WHERE id = \$2 AND "todoItems"."deleted_at" IS NULL$`).
+		WithArgs(nil, itemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemRestore(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemPurge_Error_Scoped(t *testing.T) {
+	testCases := []struct {
+		name          string
+		deletedCount  int
+		expectedError error
+	}{
+		{
+			name:          "item of another owner",
+			deletedCount:  1,
+			expectedError: storage.ErrAccessDenied,
+		},
+		{
+			name:          "item not in trash",
+			deletedCount:  0,
+			expectedError: storage.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			storageService, mock := createStorage(t)
+
+			itemID := uint64(10)
+			ownerID := uint64(2)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`^SELECT "id","parent_id","project_id","deleted_at" FROM "todoItems"`).
+				WithArgs(itemID, ownerID, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "deleted_at"}))
+			mock.ExpectQuery(`^SELECT count\(\*\) FROM "todoItems" WHERE id = \$1 AND deleted_at IS NOT NULL$`).
+				WithArgs(itemID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(testCase.deletedCount))
+			mock.ExpectRollback()
+
+			err := storageService.StorageToDoItemPurge(ctx, itemID, ownerID)
+
+			require.NoError(t, mock.ExpectationsWereMet())
+			require.ErrorIs(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestPostgresDataProvider_StorageToDoItemPurge_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	itemID := uint64(10)
+	ownerID := uint64(1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^SELECT "id","parent_id","project_id","deleted_at" FROM "todoItems"`).
+		WithArgs(itemID, ownerID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "project_id", "deleted_at"}).AddRow(itemID, nil, nil, time.Now()))
+	mock.ExpectExec(
+		`^DELETE FROM "shareMembers" WHERE resource_type = \$1 AND \(resource_id = \$2 OR resource_id IN `+
+			`\(WITH RECURSIVE subtree AS .+\)\)$`).
+		WithArgs(int8(storageDTO.ShareResourceTask), itemID, itemID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM "todoItems" WHERE id = \$1 OR id IN \(WITH RECURSIVE subtree AS .+\)$`).
+		WithArgs(itemID, itemID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	err := storageService.StorageToDoItemPurge(ctx, itemID, ownerID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+}
+
+func TestPostgresDataProvider_StorageToDoItemPurgeDeleted_Success(t *testing.T) {
+	ctx := context.Background()
+	storageService, mock := createStorage(t)
+
+	deletedBefore := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(
+		`^DELETE FROM "shareMembers" WHERE resource_type = \$1 AND resource_id IN `+
+			`\(SELECT "id" FROM "todoItems" WHERE deleted_at < \$2\)$`).
+		WithArgs(int8(storageDTO.ShareResourceTask), deletedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^DELETE FROM "todoItems" WHERE deleted_at < \$1$`).
+		WithArgs(deletedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	purged, err := storageService.StorageToDoItemPurgeDeleted(ctx, deletedBefore)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), purged)
+}
@@ -8,5 +8,16 @@ import (
 var (
 	ErrNotFound      = errors.New("storage: not found")
 	ErrAccessDenied  = errors.New("storage: access denied")
+	ErrInvalidCursor = errors.New("storage: invalid cursor")
+	ErrAlreadyExists = errors.New("storage: already exists")
+	ErrInvalidOrder  = errors.New("storage: order does not match children")
+	// ErrReferenceNotFound referenced entity does not exist or belongs to another owner
+	ErrReferenceNotFound = errors.New("storage: referenced entity not found")
+	// ErrTokenReused refresh token was already used. Its family is revoked
+	ErrTokenReused = errors.New("storage: token reused")
+	// ErrVersionConflict item was changed since the version given by caller
+	ErrVersionConflict = errors.New("storage: version conflict")
+	// ErrBatchAborted mutation was not applied since another mutation of atomic batch failed
+	ErrBatchAborted  = errors.New("storage: batch aborted")
 	ErrDatabaseError = errors.New("storage: database error")
 )
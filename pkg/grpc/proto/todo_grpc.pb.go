@@ -19,28 +19,94 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ToDoService_Login_FullMethodName          = "/todo_service.ToDoService/Login"
-	ToDoService_Logout_FullMethodName         = "/todo_service.ToDoService/Logout"
-	ToDoService_CheckSecret_FullMethodName    = "/todo_service.ToDoService/CheckSecret"
-	ToDoService_CreateTask_FullMethodName     = "/todo_service.ToDoService/CreateTask"
-	ToDoService_ListTasks_FullMethodName      = "/todo_service.ToDoService/ListTasks"
-	ToDoService_GetTaskByID_FullMethodName    = "/todo_service.ToDoService/GetTaskByID"
-	ToDoService_UpdateTaskByID_FullMethodName = "/todo_service.ToDoService/UpdateTaskByID"
-	ToDoService_DeleteTaskByID_FullMethodName = "/todo_service.ToDoService/DeleteTaskByID"
+	ToDoService_Login_FullMethodName                  = "/todo_service.ToDoService/Login"
+	ToDoService_Logout_FullMethodName                 = "/todo_service.ToDoService/Logout"
+	ToDoService_CheckSecret_FullMethodName            = "/todo_service.ToDoService/CheckSecret"
+	ToDoService_Register_FullMethodName               = "/todo_service.ToDoService/Register"
+	ToDoService_ChangePassword_FullMethodName         = "/todo_service.ToDoService/ChangePassword"
+	ToDoService_RequestPasswordReset_FullMethodName   = "/todo_service.ToDoService/RequestPasswordReset"
+	ToDoService_ConfirmPasswordReset_FullMethodName   = "/todo_service.ToDoService/ConfirmPasswordReset"
+	ToDoService_RefreshToken_FullMethodName           = "/todo_service.ToDoService/RefreshToken"
+	ToDoService_GetPublicKeys_FullMethodName          = "/todo_service.ToDoService/GetPublicKeys"
+	ToDoService_ListSessions_FullMethodName           = "/todo_service.ToDoService/ListSessions"
+	ToDoService_RevokeSession_FullMethodName          = "/todo_service.ToDoService/RevokeSession"
+	ToDoService_RevokeAllOtherSessions_FullMethodName = "/todo_service.ToDoService/RevokeAllOtherSessions"
+	ToDoService_VerifyMfa_FullMethodName              = "/todo_service.ToDoService/VerifyMfa"
+	ToDoService_EnrollTotp_FullMethodName             = "/todo_service.ToDoService/EnrollTotp"
+	ToDoService_ConfirmTotp_FullMethodName            = "/todo_service.ToDoService/ConfirmTotp"
+	ToDoService_DisableTotp_FullMethodName            = "/todo_service.ToDoService/DisableTotp"
+	ToDoService_GenerateRecoveryCodes_FullMethodName  = "/todo_service.ToDoService/GenerateRecoveryCodes"
+	ToDoService_CreateTask_FullMethodName             = "/todo_service.ToDoService/CreateTask"
+	ToDoService_ListTasks_FullMethodName              = "/todo_service.ToDoService/ListTasks"
+	ToDoService_GetTaskByID_FullMethodName            = "/todo_service.ToDoService/GetTaskByID"
+	ToDoService_UpdateTaskByID_FullMethodName         = "/todo_service.ToDoService/UpdateTaskByID"
+	ToDoService_DeleteTaskByID_FullMethodName         = "/todo_service.ToDoService/DeleteTaskByID"
+	ToDoService_WatchTasks_FullMethodName             = "/todo_service.ToDoService/WatchTasks"
+	ToDoService_AddSubtask_FullMethodName             = "/todo_service.ToDoService/AddSubtask"
+	ToDoService_ReorderSubtasks_FullMethodName        = "/todo_service.ToDoService/ReorderSubtasks"
+	ToDoService_ListSubtasks_FullMethodName           = "/todo_service.ToDoService/ListSubtasks"
+	ToDoService_CreateProject_FullMethodName          = "/todo_service.ToDoService/CreateProject"
+	ToDoService_ListProjects_FullMethodName           = "/todo_service.ToDoService/ListProjects"
+	ToDoService_RenameProject_FullMethodName          = "/todo_service.ToDoService/RenameProject"
+	ToDoService_ArchiveProject_FullMethodName         = "/todo_service.ToDoService/ArchiveProject"
+	ToDoService_DeleteProject_FullMethodName          = "/todo_service.ToDoService/DeleteProject"
+	ToDoService_InviteMember_FullMethodName           = "/todo_service.ToDoService/InviteMember"
+	ToDoService_ChangeMemberRole_FullMethodName       = "/todo_service.ToDoService/ChangeMemberRole"
+	ToDoService_RevokeMember_FullMethodName           = "/todo_service.ToDoService/RevokeMember"
+	ToDoService_ListMembers_FullMethodName            = "/todo_service.ToDoService/ListMembers"
 )
 
 // ToDoServiceClient is the client API for ToDoService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Kept for existing clients, new clients use todo.v2 AuthService and TaskService
 type ToDoServiceClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponce, error)
 	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponce, error)
 	CheckSecret(ctx context.Context, in *CheckSecretRequest, opts ...grpc.CallOption) (*CheckSecretResponce, error)
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponce, error)
+	// Changes password of the caller and revokes all of its sessions
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponce, error)
+	// Sends single-use reset token to the account owner. Succeeds for unknown accounts too
+	RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*RequestPasswordResetResponce, error)
+	// Sets new password with reset token and revokes all sessions of the account
+	ConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest, opts ...grpc.CallOption) (*ConfirmPasswordResetResponce, error)
+	// Exchanges single-use refresh token for a new token pair
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*LoginResponce, error)
+	// Returns public keys verifying issued tokens
+	GetPublicKeys(ctx context.Context, in *GetPublicKeysRequest, opts ...grpc.CallOption) (*GetPublicKeysResponce, error)
+	// Lists active sessions of the caller
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponce, error)
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponce, error)
+	// Revokes every session of the caller except the current one
+	RevokeAllOtherSessions(ctx context.Context, in *RevokeAllOtherSessionsRequest, opts ...grpc.CallOption) (*RevokeAllOtherSessionsResponce, error)
+	// Second login step of accounts with MFA. Exchanges mfa_challenge from Login and code for tokens
+	VerifyMfa(ctx context.Context, in *VerifyMfaRequest, opts ...grpc.CallOption) (*LoginResponce, error)
+	// Starts TOTP enrollment of the caller, MFA is enabled after ConfirmTotp
+	EnrollTotp(ctx context.Context, in *EnrollTotpRequest, opts ...grpc.CallOption) (*EnrollTotpResponce, error)
+	ConfirmTotp(ctx context.Context, in *ConfirmTotpRequest, opts ...grpc.CallOption) (*ConfirmTotpResponce, error)
+	DisableTotp(ctx context.Context, in *DisableTotpRequest, opts ...grpc.CallOption) (*DisableTotpResponce, error)
+	// Replaces recovery codes of the caller. Each code completes VerifyMfa once
+	GenerateRecoveryCodes(ctx context.Context, in *GenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*GenerateRecoveryCodesResponce, error)
 	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateTaskResponce, error)
 	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponce, error)
 	GetTaskByID(ctx context.Context, in *TaskByIdRequest, opts ...grpc.CallOption) (*GetTaskByIdResponce, error)
 	UpdateTaskByID(ctx context.Context, in *UpdateTaskByIdRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error)
-	DeleteTaskByID(ctx context.Context, in *TaskByIdRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error)
+	DeleteTaskByID(ctx context.Context, in *DeleteTaskByIdRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error)
+	WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error)
+	AddSubtask(ctx context.Context, in *AddSubtaskRequest, opts ...grpc.CallOption) (*CreateTaskResponce, error)
+	ReorderSubtasks(ctx context.Context, in *ReorderSubtasksRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error)
+	ListSubtasks(ctx context.Context, in *ListSubtasksRequest, opts ...grpc.CallOption) (*ListTasksResponce, error)
+	CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*CreateProjectResponce, error)
+	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponce, error)
+	RenameProject(ctx context.Context, in *RenameProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error)
+	ArchiveProject(ctx context.Context, in *ArchiveProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error)
+	DeleteProject(ctx context.Context, in *DeleteProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error)
+	InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*MemberResponce, error)
+	ChangeMemberRole(ctx context.Context, in *ChangeMemberRoleRequest, opts ...grpc.CallOption) (*ChangedMemberResponce, error)
+	RevokeMember(ctx context.Context, in *RevokeMemberRequest, opts ...grpc.CallOption) (*ChangedMemberResponce, error)
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponce, error)
 }
 
 type toDoServiceClient struct {
@@ -81,6 +147,146 @@ func (c *toDoServiceClient) CheckSecret(ctx context.Context, in *CheckSecretRequ
 	return out, nil
 }
 
+func (c *toDoServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponce)
+	err := c.cc.Invoke(ctx, ToDoService_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangePasswordResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ChangePassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*RequestPasswordResetResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestPasswordResetResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RequestPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ConfirmPasswordReset(ctx context.Context, in *ConfirmPasswordResetRequest, opts ...grpc.CallOption) (*ConfirmPasswordResetResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmPasswordResetResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ConfirmPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*LoginResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) GetPublicKeys(ctx context.Context, in *GetPublicKeysRequest, opts ...grpc.CallOption) (*GetPublicKeysResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPublicKeysResponce)
+	err := c.cc.Invoke(ctx, ToDoService_GetPublicKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeSessionResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RevokeAllOtherSessions(ctx context.Context, in *RevokeAllOtherSessionsRequest, opts ...grpc.CallOption) (*RevokeAllOtherSessionsResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeAllOtherSessionsResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RevokeAllOtherSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) VerifyMfa(ctx context.Context, in *VerifyMfaRequest, opts ...grpc.CallOption) (*LoginResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponce)
+	err := c.cc.Invoke(ctx, ToDoService_VerifyMfa_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) EnrollTotp(ctx context.Context, in *EnrollTotpRequest, opts ...grpc.CallOption) (*EnrollTotpResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollTotpResponce)
+	err := c.cc.Invoke(ctx, ToDoService_EnrollTotp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ConfirmTotp(ctx context.Context, in *ConfirmTotpRequest, opts ...grpc.CallOption) (*ConfirmTotpResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmTotpResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ConfirmTotp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) DisableTotp(ctx context.Context, in *DisableTotpRequest, opts ...grpc.CallOption) (*DisableTotpResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisableTotpResponce)
+	err := c.cc.Invoke(ctx, ToDoService_DisableTotp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) GenerateRecoveryCodes(ctx context.Context, in *GenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*GenerateRecoveryCodesResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateRecoveryCodesResponce)
+	err := c.cc.Invoke(ctx, ToDoService_GenerateRecoveryCodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *toDoServiceClient) CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateTaskResponce, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateTaskResponce)
@@ -121,7 +327,7 @@ func (c *toDoServiceClient) UpdateTaskByID(ctx context.Context, in *UpdateTaskBy
 	return out, nil
 }
 
-func (c *toDoServiceClient) DeleteTaskByID(ctx context.Context, in *TaskByIdRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error) {
+func (c *toDoServiceClient) DeleteTaskByID(ctx context.Context, in *DeleteTaskByIdRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ChangedTaskByIdResponce)
 	err := c.cc.Invoke(ctx, ToDoService_DeleteTaskByID_FullMethodName, in, out, cOpts...)
@@ -131,18 +337,196 @@ func (c *toDoServiceClient) DeleteTaskByID(ctx context.Context, in *TaskByIdRequ
 	return out, nil
 }
 
+func (c *toDoServiceClient) WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ToDoService_ServiceDesc.Streams[0], ToDoService_WatchTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTasksRequest, TaskEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ToDoService_WatchTasksClient = grpc.ServerStreamingClient[TaskEvent]
+
+func (c *toDoServiceClient) AddSubtask(ctx context.Context, in *AddSubtaskRequest, opts ...grpc.CallOption) (*CreateTaskResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTaskResponce)
+	err := c.cc.Invoke(ctx, ToDoService_AddSubtask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ReorderSubtasks(ctx context.Context, in *ReorderSubtasksRequest, opts ...grpc.CallOption) (*ChangedTaskByIdResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedTaskByIdResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ReorderSubtasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListSubtasks(ctx context.Context, in *ListSubtasksRequest, opts ...grpc.CallOption) (*ListTasksResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ListSubtasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*CreateProjectResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateProjectResponce)
+	err := c.cc.Invoke(ctx, ToDoService_CreateProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProjectsResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ListProjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RenameProject(ctx context.Context, in *RenameProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedProjectResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RenameProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ArchiveProject(ctx context.Context, in *ArchiveProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedProjectResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ArchiveProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) DeleteProject(ctx context.Context, in *DeleteProjectRequest, opts ...grpc.CallOption) (*ChangedProjectResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedProjectResponce)
+	err := c.cc.Invoke(ctx, ToDoService_DeleteProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) InviteMember(ctx context.Context, in *InviteMemberRequest, opts ...grpc.CallOption) (*MemberResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemberResponce)
+	err := c.cc.Invoke(ctx, ToDoService_InviteMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ChangeMemberRole(ctx context.Context, in *ChangeMemberRoleRequest, opts ...grpc.CallOption) (*ChangedMemberResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedMemberResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ChangeMemberRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) RevokeMember(ctx context.Context, in *RevokeMemberRequest, opts ...grpc.CallOption) (*ChangedMemberResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangedMemberResponce)
+	err := c.cc.Invoke(ctx, ToDoService_RevokeMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponce, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMembersResponce)
+	err := c.cc.Invoke(ctx, ToDoService_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ToDoServiceServer is the server API for ToDoService service.
 // All implementations must embed UnimplementedToDoServiceServer
 // for forward compatibility.
+//
+// Kept for existing clients, new clients use todo.v2 AuthService and TaskService
 type ToDoServiceServer interface {
 	Login(context.Context, *LoginRequest) (*LoginResponce, error)
 	Logout(context.Context, *LogoutRequest) (*LogoutResponce, error)
 	CheckSecret(context.Context, *CheckSecretRequest) (*CheckSecretResponce, error)
+	Register(context.Context, *RegisterRequest) (*RegisterResponce, error)
+	// Changes password of the caller and revokes all of its sessions
+	ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponce, error)
+	// Sends single-use reset token to the account owner. Succeeds for unknown accounts too
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*RequestPasswordResetResponce, error)
+	// Sets new password with reset token and revokes all sessions of the account
+	ConfirmPasswordReset(context.Context, *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponce, error)
+	// Exchanges single-use refresh token for a new token pair
+	RefreshToken(context.Context, *RefreshTokenRequest) (*LoginResponce, error)
+	// Returns public keys verifying issued tokens
+	GetPublicKeys(context.Context, *GetPublicKeysRequest) (*GetPublicKeysResponce, error)
+	// Lists active sessions of the caller
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponce, error)
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponce, error)
+	// Revokes every session of the caller except the current one
+	RevokeAllOtherSessions(context.Context, *RevokeAllOtherSessionsRequest) (*RevokeAllOtherSessionsResponce, error)
+	// Second login step of accounts with MFA. Exchanges mfa_challenge from Login and code for tokens
+	VerifyMfa(context.Context, *VerifyMfaRequest) (*LoginResponce, error)
+	// Starts TOTP enrollment of the caller, MFA is enabled after ConfirmTotp
+	EnrollTotp(context.Context, *EnrollTotpRequest) (*EnrollTotpResponce, error)
+	ConfirmTotp(context.Context, *ConfirmTotpRequest) (*ConfirmTotpResponce, error)
+	DisableTotp(context.Context, *DisableTotpRequest) (*DisableTotpResponce, error)
+	// Replaces recovery codes of the caller. Each code completes VerifyMfa once
+	GenerateRecoveryCodes(context.Context, *GenerateRecoveryCodesRequest) (*GenerateRecoveryCodesResponce, error)
 	CreateTask(context.Context, *CreateTaskRequest) (*CreateTaskResponce, error)
 	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponce, error)
 	GetTaskByID(context.Context, *TaskByIdRequest) (*GetTaskByIdResponce, error)
 	UpdateTaskByID(context.Context, *UpdateTaskByIdRequest) (*ChangedTaskByIdResponce, error)
-	DeleteTaskByID(context.Context, *TaskByIdRequest) (*ChangedTaskByIdResponce, error)
+	DeleteTaskByID(context.Context, *DeleteTaskByIdRequest) (*ChangedTaskByIdResponce, error)
+	WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskEvent]) error
+	AddSubtask(context.Context, *AddSubtaskRequest) (*CreateTaskResponce, error)
+	ReorderSubtasks(context.Context, *ReorderSubtasksRequest) (*ChangedTaskByIdResponce, error)
+	ListSubtasks(context.Context, *ListSubtasksRequest) (*ListTasksResponce, error)
+	CreateProject(context.Context, *CreateProjectRequest) (*CreateProjectResponce, error)
+	ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponce, error)
+	RenameProject(context.Context, *RenameProjectRequest) (*ChangedProjectResponce, error)
+	ArchiveProject(context.Context, *ArchiveProjectRequest) (*ChangedProjectResponce, error)
+	DeleteProject(context.Context, *DeleteProjectRequest) (*ChangedProjectResponce, error)
+	InviteMember(context.Context, *InviteMemberRequest) (*MemberResponce, error)
+	ChangeMemberRole(context.Context, *ChangeMemberRoleRequest) (*ChangedMemberResponce, error)
+	RevokeMember(context.Context, *RevokeMemberRequest) (*ChangedMemberResponce, error)
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponce, error)
 	mustEmbedUnimplementedToDoServiceServer()
 }
 
@@ -162,6 +546,48 @@ func (UnimplementedToDoServiceServer) Logout(context.Context, *LogoutRequest) (*
 func (UnimplementedToDoServiceServer) CheckSecret(context.Context, *CheckSecretRequest) (*CheckSecretResponce, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckSecret not implemented")
 }
+func (UnimplementedToDoServiceServer) Register(context.Context, *RegisterRequest) (*RegisterResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedToDoServiceServer) ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedToDoServiceServer) RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*RequestPasswordResetResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestPasswordReset not implemented")
+}
+func (UnimplementedToDoServiceServer) ConfirmPasswordReset(context.Context, *ConfirmPasswordResetRequest) (*ConfirmPasswordResetResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmPasswordReset not implemented")
+}
+func (UnimplementedToDoServiceServer) RefreshToken(context.Context, *RefreshTokenRequest) (*LoginResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedToDoServiceServer) GetPublicKeys(context.Context, *GetPublicKeysRequest) (*GetPublicKeysResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicKeys not implemented")
+}
+func (UnimplementedToDoServiceServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedToDoServiceServer) RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedToDoServiceServer) RevokeAllOtherSessions(context.Context, *RevokeAllOtherSessionsRequest) (*RevokeAllOtherSessionsResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllOtherSessions not implemented")
+}
+func (UnimplementedToDoServiceServer) VerifyMfa(context.Context, *VerifyMfaRequest) (*LoginResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyMfa not implemented")
+}
+func (UnimplementedToDoServiceServer) EnrollTotp(context.Context, *EnrollTotpRequest) (*EnrollTotpResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTotp not implemented")
+}
+func (UnimplementedToDoServiceServer) ConfirmTotp(context.Context, *ConfirmTotpRequest) (*ConfirmTotpResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTotp not implemented")
+}
+func (UnimplementedToDoServiceServer) DisableTotp(context.Context, *DisableTotpRequest) (*DisableTotpResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTotp not implemented")
+}
+func (UnimplementedToDoServiceServer) GenerateRecoveryCodes(context.Context, *GenerateRecoveryCodesRequest) (*GenerateRecoveryCodesResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateRecoveryCodes not implemented")
+}
 func (UnimplementedToDoServiceServer) CreateTask(context.Context, *CreateTaskRequest) (*CreateTaskResponce, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTask not implemented")
 }
@@ -174,9 +600,48 @@ func (UnimplementedToDoServiceServer) GetTaskByID(context.Context, *TaskByIdRequ
 func (UnimplementedToDoServiceServer) UpdateTaskByID(context.Context, *UpdateTaskByIdRequest) (*ChangedTaskByIdResponce, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTaskByID not implemented")
 }
-func (UnimplementedToDoServiceServer) DeleteTaskByID(context.Context, *TaskByIdRequest) (*ChangedTaskByIdResponce, error) {
+func (UnimplementedToDoServiceServer) DeleteTaskByID(context.Context, *DeleteTaskByIdRequest) (*ChangedTaskByIdResponce, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTaskByID not implemented")
 }
+func (UnimplementedToDoServiceServer) WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTasks not implemented")
+}
+func (UnimplementedToDoServiceServer) AddSubtask(context.Context, *AddSubtaskRequest) (*CreateTaskResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSubtask not implemented")
+}
+func (UnimplementedToDoServiceServer) ReorderSubtasks(context.Context, *ReorderSubtasksRequest) (*ChangedTaskByIdResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderSubtasks not implemented")
+}
+func (UnimplementedToDoServiceServer) ListSubtasks(context.Context, *ListSubtasksRequest) (*ListTasksResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubtasks not implemented")
+}
+func (UnimplementedToDoServiceServer) CreateProject(context.Context, *CreateProjectRequest) (*CreateProjectResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProject not implemented")
+}
+func (UnimplementedToDoServiceServer) ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
+}
+func (UnimplementedToDoServiceServer) RenameProject(context.Context, *RenameProjectRequest) (*ChangedProjectResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameProject not implemented")
+}
+func (UnimplementedToDoServiceServer) ArchiveProject(context.Context, *ArchiveProjectRequest) (*ChangedProjectResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveProject not implemented")
+}
+func (UnimplementedToDoServiceServer) DeleteProject(context.Context, *DeleteProjectRequest) (*ChangedProjectResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProject not implemented")
+}
+func (UnimplementedToDoServiceServer) InviteMember(context.Context, *InviteMemberRequest) (*MemberResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InviteMember not implemented")
+}
+func (UnimplementedToDoServiceServer) ChangeMemberRole(context.Context, *ChangeMemberRoleRequest) (*ChangedMemberResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeMemberRole not implemented")
+}
+func (UnimplementedToDoServiceServer) RevokeMember(context.Context, *RevokeMemberRequest) (*ChangedMemberResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeMember not implemented")
+}
+func (UnimplementedToDoServiceServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
 func (UnimplementedToDoServiceServer) mustEmbedUnimplementedToDoServiceServer() {}
 func (UnimplementedToDoServiceServer) testEmbeddedByValue()                     {}
 
@@ -252,101 +717,580 @@ func _ToDoService_CheckSecret_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoService_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateTaskRequest)
+func _ToDoService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoServiceServer).CreateTask(ctx, in)
+		return srv.(ToDoServiceServer).Register(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoService_CreateTask_FullMethodName,
+		FullMethod: ToDoService_Register_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoServiceServer).CreateTask(ctx, req.(*CreateTaskRequest))
+		return srv.(ToDoServiceServer).Register(ctx, req.(*RegisterRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoService_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListTasksRequest)
+func _ToDoService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoServiceServer).ListTasks(ctx, in)
+		return srv.(ToDoServiceServer).ChangePassword(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoService_ListTasks_FullMethodName,
+		FullMethod: ToDoService_ChangePassword_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoServiceServer).ListTasks(ctx, req.(*ListTasksRequest))
+		return srv.(ToDoServiceServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoService_GetTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TaskByIdRequest)
+func _ToDoService_RequestPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPasswordResetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoServiceServer).GetTaskByID(ctx, in)
+		return srv.(ToDoServiceServer).RequestPasswordReset(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoService_GetTaskByID_FullMethodName,
+		FullMethod: ToDoService_RequestPasswordReset_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoServiceServer).GetTaskByID(ctx, req.(*TaskByIdRequest))
+		return srv.(ToDoServiceServer).RequestPasswordReset(ctx, req.(*RequestPasswordResetRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoService_UpdateTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateTaskByIdRequest)
+func _ToDoService_ConfirmPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmPasswordResetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoServiceServer).UpdateTaskByID(ctx, in)
+		return srv.(ToDoServiceServer).ConfirmPasswordReset(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoService_UpdateTaskByID_FullMethodName,
+		FullMethod: ToDoService_ConfirmPasswordReset_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoServiceServer).UpdateTaskByID(ctx, req.(*UpdateTaskByIdRequest))
+		return srv.(ToDoServiceServer).ConfirmPasswordReset(ctx, req.(*ConfirmPasswordResetRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoService_DeleteTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TaskByIdRequest)
+func _ToDoService_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoServiceServer).DeleteTaskByID(ctx, in)
+		return srv.(ToDoServiceServer).RefreshToken(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoService_DeleteTaskByID_FullMethodName,
+		FullMethod: ToDoService_RefreshToken_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoServiceServer).DeleteTaskByID(ctx, req.(*TaskByIdRequest))
+		return srv.(ToDoServiceServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// ToDoService_ServiceDesc is the grpc.ServiceDesc for ToDoService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var ToDoService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "todo_service.ToDoService",
+func _ToDoService_GetPublicKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).GetPublicKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_GetPublicKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).GetPublicKeys(ctx, req.(*GetPublicKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_RevokeAllOtherSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAllOtherSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).RevokeAllOtherSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_RevokeAllOtherSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).RevokeAllOtherSessions(ctx, req.(*RevokeAllOtherSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_VerifyMfa_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMfaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).VerifyMfa(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_VerifyMfa_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).VerifyMfa(ctx, req.(*VerifyMfaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_EnrollTotp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollTotpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).EnrollTotp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_EnrollTotp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).EnrollTotp(ctx, req.(*EnrollTotpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ConfirmTotp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTotpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ConfirmTotp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ConfirmTotp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ConfirmTotp(ctx, req.(*ConfirmTotpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_DisableTotp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTotpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).DisableTotp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_DisableTotp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).DisableTotp(ctx, req.(*DisableTotpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_GenerateRecoveryCodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRecoveryCodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).GenerateRecoveryCodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_GenerateRecoveryCodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).GenerateRecoveryCodes(ctx, req.(*GenerateRecoveryCodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).CreateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_CreateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).CreateTask(ctx, req.(*CreateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_GetTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).GetTaskByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_GetTaskByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).GetTaskByID(ctx, req.(*TaskByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_UpdateTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTaskByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).UpdateTaskByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_UpdateTaskByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).UpdateTaskByID(ctx, req.(*UpdateTaskByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_DeleteTaskByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTaskByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).DeleteTaskByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_DeleteTaskByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).DeleteTaskByID(ctx, req.(*DeleteTaskByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_WatchTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTasksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ToDoServiceServer).WatchTasks(m, &grpc.GenericServerStream[WatchTasksRequest, TaskEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ToDoService_WatchTasksServer = grpc.ServerStreamingServer[TaskEvent]
+
+func _ToDoService_AddSubtask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSubtaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).AddSubtask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_AddSubtask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).AddSubtask(ctx, req.(*AddSubtaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ReorderSubtasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderSubtasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ReorderSubtasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ReorderSubtasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ReorderSubtasks(ctx, req.(*ReorderSubtasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListSubtasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubtasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListSubtasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ListSubtasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListSubtasks(ctx, req.(*ListSubtasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_CreateProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).CreateProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_CreateProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).CreateProject(ctx, req.(*CreateProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ListProjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListProjects(ctx, req.(*ListProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_RenameProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).RenameProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_RenameProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).RenameProject(ctx, req.(*RenameProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ArchiveProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ArchiveProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ArchiveProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ArchiveProject(ctx, req.(*ArchiveProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_DeleteProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).DeleteProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_DeleteProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).DeleteProject(ctx, req.(*DeleteProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_InviteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).InviteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_InviteMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).InviteMember(ctx, req.(*InviteMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ChangeMemberRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeMemberRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ChangeMemberRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ChangeMemberRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ChangeMemberRole(ctx, req.(*ChangeMemberRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_RevokeMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).RevokeMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_RevokeMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).RevokeMember(ctx, req.(*RevokeMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoService_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ToDoService_ServiceDesc is the grpc.ServiceDesc for ToDoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ToDoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "todo_service.ToDoService",
 	HandlerType: (*ToDoServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
@@ -361,6 +1305,62 @@ var ToDoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckSecret",
 			Handler:    _ToDoService_CheckSecret_Handler,
 		},
+		{
+			MethodName: "Register",
+			Handler:    _ToDoService_Register_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _ToDoService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "RequestPasswordReset",
+			Handler:    _ToDoService_RequestPasswordReset_Handler,
+		},
+		{
+			MethodName: "ConfirmPasswordReset",
+			Handler:    _ToDoService_ConfirmPasswordReset_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _ToDoService_RefreshToken_Handler,
+		},
+		{
+			MethodName: "GetPublicKeys",
+			Handler:    _ToDoService_GetPublicKeys_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _ToDoService_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _ToDoService_RevokeSession_Handler,
+		},
+		{
+			MethodName: "RevokeAllOtherSessions",
+			Handler:    _ToDoService_RevokeAllOtherSessions_Handler,
+		},
+		{
+			MethodName: "VerifyMfa",
+			Handler:    _ToDoService_VerifyMfa_Handler,
+		},
+		{
+			MethodName: "EnrollTotp",
+			Handler:    _ToDoService_EnrollTotp_Handler,
+		},
+		{
+			MethodName: "ConfirmTotp",
+			Handler:    _ToDoService_ConfirmTotp_Handler,
+		},
+		{
+			MethodName: "DisableTotp",
+			Handler:    _ToDoService_DisableTotp_Handler,
+		},
+		{
+			MethodName: "GenerateRecoveryCodes",
+			Handler:    _ToDoService_GenerateRecoveryCodes_Handler,
+		},
 		{
 			MethodName: "CreateTask",
 			Handler:    _ToDoService_CreateTask_Handler,
@@ -381,7 +1381,61 @@ var ToDoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTaskByID",
 			Handler:    _ToDoService_DeleteTaskByID_Handler,
 		},
+		{
+			MethodName: "AddSubtask",
+			Handler:    _ToDoService_AddSubtask_Handler,
+		},
+		{
+			MethodName: "ReorderSubtasks",
+			Handler:    _ToDoService_ReorderSubtasks_Handler,
+		},
+		{
+			MethodName: "ListSubtasks",
+			Handler:    _ToDoService_ListSubtasks_Handler,
+		},
+		{
+			MethodName: "CreateProject",
+			Handler:    _ToDoService_CreateProject_Handler,
+		},
+		{
+			MethodName: "ListProjects",
+			Handler:    _ToDoService_ListProjects_Handler,
+		},
+		{
+			MethodName: "RenameProject",
+			Handler:    _ToDoService_RenameProject_Handler,
+		},
+		{
+			MethodName: "ArchiveProject",
+			Handler:    _ToDoService_ArchiveProject_Handler,
+		},
+		{
+			MethodName: "DeleteProject",
+			Handler:    _ToDoService_DeleteProject_Handler,
+		},
+		{
+			MethodName: "InviteMember",
+			Handler:    _ToDoService_InviteMember_Handler,
+		},
+		{
+			MethodName: "ChangeMemberRole",
+			Handler:    _ToDoService_ChangeMemberRole_Handler,
+		},
+		{
+			MethodName: "RevokeMember",
+			Handler:    _ToDoService_RevokeMember_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _ToDoService_ListMembers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTasks",
+			Handler:       _ToDoService_WatchTasks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "todo.proto",
 }